@@ -0,0 +1,186 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EarlyCloseSpec is a date on which every run period stops early, for example Christmas Eve
+type EarlyCloseSpec struct {
+	// Date of the early close, interpreted in the ControlledJob's Timezone
+	// Format: yyyy-mm-dd
+	// +kubebuilder:validation:Pattern:=`^\d{4}-\d{2}-\d{2}$`
+	Date string `json:"date"`
+
+	// TimeOfDay at which to stop on that date, interpreted in the ControlledJob's Timezone
+	// Format: hh:mm
+	// +kubebuilder:validation:Pattern:=`^(\d{2}):(\d{2})$`
+	TimeOfDay string `json:"timeOfDay"`
+
+	// Description of the early close. Not used by the controller
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// AsTime returns the date and time of the early close, as a wall clock time in the given location
+func (e *EarlyCloseSpec) AsTime(location *time.Location) (time.Time, error) {
+	date, err := time.Parse(ExclusionDateFormat, e.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date must be in the format yyyy-mm-dd: %w", err)
+	}
+	timeOfDay, err := time.Parse("15:04", e.TimeOfDay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timeOfDay must be in the format hh:mm: %w", err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), timeOfDay.Hour(), timeOfDay.Minute(), 0, 0, location), nil
+}
+
+// ExtraWorkingDaySpec is a date on which events happen even though they normally wouldn't, for example a
+// Saturday which is treated as a normal working day
+type ExtraWorkingDaySpec struct {
+	// Date of the extra working day, interpreted in the ControlledJob's Timezone
+	// Format: yyyy-mm-dd
+	// +kubebuilder:validation:Pattern:=`^\d{4}-\d{2}-\d{2}$`
+	Date string `json:"date"`
+
+	// ScheduleAs is the day of the week whose events happen on this date. Defaults to MON
+	// +kubebuilder:validation:Enum=MON;TUE;WED;THU;FRI;SAT;SUN
+	// +optional
+	ScheduleAs string `json:"scheduleAs,omitempty"`
+
+	// Description of the extra working day. Not used by the controller
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+var weekdaysByName = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// AsDate returns the extra working day at midnight UTC, along with the day of the week it should be scheduled as
+func (e *ExtraWorkingDaySpec) AsDate() (date time.Time, scheduleAs time.Weekday, err error) {
+	date, err = time.Parse(ExclusionDateFormat, e.Date)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("date must be in the format yyyy-mm-dd: %w", err)
+	}
+	if e.ScheduleAs == "" {
+		return date, time.Monday, nil
+	}
+	scheduleAs, ok := weekdaysByName[strings.ToUpper(e.ScheduleAs)]
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("scheduleAs must be one of MON, TUE, WED, THU, FRI, SAT or SUN")
+	}
+	return date, scheduleAs, nil
+}
+
+// CalendarSpec defines the holidays, early closes and extra working days shared by the ControlledJobs which
+// reference it. All dates and times are interpreted in the Timezone of the ControlledJob using the calendar
+type CalendarSpec struct {
+	// Holidays are dates on which start events are skipped. They behave exactly like a ControlledJob's own
+	// exclusions: a Job which was started before a holiday keeps running until the next stop event
+	// +optional
+	Holidays []ExclusionSpec `json:"holidays,omitempty"`
+
+	// EarlyCloses add an extra stop event at the given time on the given date. They only affect ControlledJobs
+	// which have stop events of their own
+	// +optional
+	EarlyCloses []EarlyCloseSpec `json:"earlyCloses,omitempty"`
+
+	// ExtraWorkingDays are dates on which events happen as if it was a different day of the week. For example, a
+	// Saturday scheduled as a Monday gets all the events a Monday would. A holiday on the same date still
+	// skips the start events
+	// +optional
+	ExtraWorkingDays []ExtraWorkingDaySpec `json:"extraWorkingDays,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName="cal"
+
+// Calendar is the Schema for the calendars API. ControlledJobs in the same namespace reference it by name
+// from spec.calendarRef
+type Calendar struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CalendarSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CalendarList contains a list of Calendar
+type CalendarList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Calendar `json:"items"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName="ccal"
+
+// ClusterCalendar is the Schema for the clustercalendars API. It is the same as a Calendar, but can be referenced
+// by ControlledJobs in any namespace
+type ClusterCalendar struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CalendarSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterCalendarList contains a list of ClusterCalendar
+type ClusterCalendarList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterCalendar `json:"items"`
+}
+
+const (
+	CalendarKind        = "Calendar"
+	ClusterCalendarKind = "ClusterCalendar"
+)
+
+// CalendarReference identifies the Calendar or ClusterCalendar used by a ControlledJob
+type CalendarReference struct {
+	// Kind of the calendar. Defaults to Calendar, which must be in the same namespace as the ControlledJob
+	// +kubebuilder:validation:Enum=Calendar;ClusterCalendar
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the calendar
+	Name string `json:"name"`
+}
+
+// IsClusterCalendar returns true if the reference is to a ClusterCalendar rather than a Calendar
+func (r *CalendarReference) IsClusterCalendar() bool {
+	return r.Kind == ClusterCalendarKind
+}
+
+func init() {
+	SchemeBuilder.Register(&Calendar{}, &CalendarList{}, &ClusterCalendar{}, &ClusterCalendarList{})
+}
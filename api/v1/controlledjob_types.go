@@ -20,6 +20,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -44,6 +47,52 @@ type TimezoneSpec struct {
 	//
 	// +optional
 	OffsetSeconds int32 `json:"offset"`
+
+	// DSTPolicy says what happens to scheduled times which are skipped or repeated when the clocks change
+	// +optional
+	DSTPolicy *DSTPolicySpec `json:"dstPolicy,omitempty"`
+}
+
+// DSTSkippedTimePolicy says what happens to a scheduled time which doesn't exist, because the clocks go forward
+// over it
+type DSTSkippedTimePolicy string
+
+const (
+	// DSTSkip means the event doesn't happen that day
+	DSTSkip DSTSkippedTimePolicy = "Skip"
+	// DSTShiftForward means the event happens later by however far the clocks went forward, e.g. 01:30 in
+	// Europe/London becomes 02:30
+	DSTShiftForward DSTSkippedTimePolicy = "ShiftForward"
+)
+
+// DSTRepeatedTimePolicy says what happens to a scheduled time which happens twice, because the clocks go back
+// over it
+type DSTRepeatedTimePolicy string
+
+const (
+	// DSTBothOccurrences means the event happens twice
+	DSTBothOccurrences DSTRepeatedTimePolicy = "BothOccurrences"
+	// DSTFirstOccurrence means the event only happens the first time, before the clocks go back
+	DSTFirstOccurrence DSTRepeatedTimePolicy = "FirstOccurrence"
+	// DSTLastOccurrence means the event only happens the second time, after the clocks go back
+	DSTLastOccurrence DSTRepeatedTimePolicy = "LastOccurrence"
+)
+
+// DSTPolicySpec says what happens to scheduled times which are affected by daylight saving time changes. For
+// example, 01:30 doesn't exist in Europe/London on the last Sunday in March, and happens twice on the last Sunday
+// in October
+type DSTPolicySpec struct {
+	// SkippedTimes says what happens to scheduled times which don't exist because the clocks go forward over them.
+	// Defaults to Skip
+	// +kubebuilder:validation:Enum=Skip;ShiftForward
+	// +optional
+	SkippedTimes DSTSkippedTimePolicy `json:"skippedTimes,omitempty"`
+
+	// RepeatedTimes says what happens to scheduled times which happen twice because the clocks go back over them.
+	// Defaults to BothOccurrences
+	// +kubebuilder:validation:Enum=BothOccurrences;FirstOccurrence;LastOccurrence
+	// +optional
+	RepeatedTimes DSTRepeatedTimePolicy `json:"repeatedTimes,omitempty"`
 }
 
 type EventType string
@@ -51,23 +100,152 @@ type EventType string
 const (
 	EventTypeStart EventType = "start"
 	EventTypeStop  EventType = "stop"
-	// Restart not yet supported
+	// EventTypeRestart stops the current Job and, once it has fully terminated, replaces it with a fresh Job
+	// in the same run period. Restart events outside of a run period have no effect
 	EventTypeRestart EventType = "restart"
 )
 
 // FriendlyScheduleSpec is a more user friendly way to specify an event schedule
 // It's more limited than the format supported by CronSchedule
+//
+// The event happens at each of the times of day on every day which matches any of DaysOfWeek, DaysOfMonth,
+// WeekdaysOfMonth or LastBusinessDayOfMonth. At least one time of day, and at least one way of choosing days, must
+// be given
 type FriendlyScheduleSpec struct {
 	// TimeOfDay this event happens on the specified days
 	// Format: hh:mm
 	// +kubebuilder:validation:Pattern:=`^(\d{2}):(\d{2})$`
-	TimeOfDay string `json:"timeOfDay"`
+	// +optional
+	TimeOfDay string `json:"timeOfDay,omitempty"`
+
+	// TimesOfDay lists further times of day this event happens on the specified days, in addition to TimeOfDay
+	// Format: hh:mm
+	// +optional
+	TimesOfDay []string `json:"timesOfDay,omitempty"`
 
 	// DaysOfWeek this event occurs on.
 	// Either a comma separated list (MON,TUE,THU)
 	// Or a range (MON-FRI)
 	// +kubebuilder:validation:Pattern:=`(?:^([a-zA-Z]{3})(?:,([a-zA-Z]{3}))?(?:,([a-zA-Z]{3}))?(?:,([a-zA-Z]{3}))?(?:,([a-zA-Z]{3}))?(?:,([a-zA-Z]{3}))?(?:,([a-zA-Z]{3}))?)$|^(?P<startRange>[a-zA-Z]{3})-(?P<endRange>[a-zA-Z]{3}$)`
-	DaysOfWeek string `json:"daysOfWeek"`
+	// +optional
+	DaysOfWeek string `json:"daysOfWeek,omitempty"`
+
+	// DaysOfMonth this event occurs on, from 1 to 31. The event doesn't happen in months which are too short for
+	// a given day
+	// +optional
+	DaysOfMonth []int32 `json:"daysOfMonth,omitempty"`
+
+	// WeekdaysOfMonth this event occurs on, e.g. the 3rd Friday or the last Monday of each month
+	// +optional
+	WeekdaysOfMonth []WeekdayOfMonthSpec `json:"weekdaysOfMonth,omitempty"`
+
+	// LastBusinessDayOfMonth makes this event occur on the last Monday to Friday of each month. Holidays aren't
+	// taken into account, so if that day is excluded then start events don't happen that month
+	// +optional
+	LastBusinessDayOfMonth bool `json:"lastBusinessDayOfMonth,omitempty"`
+}
+
+// WeekdayOfMonthSpec is a particular day of the week within each month, e.g. the 3rd Friday or the last Monday
+type WeekdayOfMonthSpec struct {
+	// Day of the week
+	// +kubebuilder:validation:Enum=MON;TUE;WED;THU;FRI;SAT;SUN
+	Day string `json:"day"`
+
+	// Week is 1 for the first such day in the month, up to 5 for the fifth (in which case the event doesn't happen
+	// in months without a fifth such day), or -1 for the last such day in the month
+	// +kubebuilder:validation:Minimum=-1
+	// +kubebuilder:validation:Maximum=5
+	Week int32 `json:"week"`
+}
+
+// AsWeekday returns the day of the week, and which one in the month (-1 for the last)
+func (w *WeekdayOfMonthSpec) AsWeekday() (time.Weekday, int, error) {
+	weekday, ok := weekdaysByName[strings.ToUpper(w.Day)]
+	if !ok {
+		return 0, 0, fmt.Errorf("day must be one of MON, TUE, WED, THU, FRI, SAT or SUN")
+	}
+	if w.Week == 0 || w.Week < -1 || w.Week > 5 {
+		return 0, 0, fmt.Errorf("week must be from 1 to 5, or -1 for the last week of the month, not %d", w.Week)
+	}
+	return weekday, int(w.Week), nil
+}
+
+// HasMonthlyRules returns true if days are chosen in ways which cron can't express
+func (f *FriendlyScheduleSpec) HasMonthlyRules() bool {
+	return len(f.WeekdaysOfMonth) > 0 || f.LastBusinessDayOfMonth
+}
+
+// AllTimesOfDay returns TimeOfDay and TimesOfDay together, as offsets from midnight
+func (f *FriendlyScheduleSpec) AllTimesOfDay() ([]time.Duration, error) {
+	timesOfDay := f.TimesOfDay
+	if f.TimeOfDay != "" {
+		timesOfDay = append([]string{f.TimeOfDay}, timesOfDay...)
+	}
+	result := make([]time.Duration, 0, len(timesOfDay))
+	for _, timeOfDay := range timesOfDay {
+		timeOfDayMatches := timeOfDayRegex.FindStringSubmatch(timeOfDay)
+		// timeOfDayMatches should contain the entire string, the hours part, and the minutes part (the two subexpressions), so should
+		// have a length of 3
+		if len(timeOfDayMatches) != 3 {
+			return nil, errors.New("timeOfDay must be in the format hh:mm")
+		}
+		hours, _ := strconv.Atoi(timeOfDayMatches[1])
+		minutes, _ := strconv.Atoi(timeOfDayMatches[2])
+		if hours > 23 || minutes > 59 {
+			return nil, fmt.Errorf("timeOfDay %s is not a valid time", timeOfDay)
+		}
+		result = append(result, time.Duration(hours)*time.Hour+time.Duration(minutes)*time.Minute)
+	}
+	return result, nil
+}
+
+// AsCronSpecs presents the days of the week and days of the month in CronTab format, with one spec for each time of
+// day. This doesn't include WeekdaysOfMonth or LastBusinessDayOfMonth, which cron can't express, so returns no specs
+// if days are only chosen in those ways
+func (f *FriendlyScheduleSpec) AsCronSpecs() ([]string, error) {
+	timesOfDay, err := f.AllTimesOfDay()
+	if err != nil {
+		return nil, err
+	}
+	// A schedule without any times of day, or without any way of choosing days, is incomplete
+	if len(timesOfDay) == 0 || (f.DaysOfWeek == "" && len(f.DaysOfMonth) == 0 && !f.HasMonthlyRules()) {
+		return nil, errors.New("must specify either cronSchedule or schedule")
+	}
+	for _, weekdayOfMonth := range f.WeekdaysOfMonth {
+		if _, _, err := weekdayOfMonth.AsWeekday(); err != nil {
+			return nil, err
+		}
+	}
+	if f.DaysOfWeek == "" && len(f.DaysOfMonth) == 0 {
+		return nil, nil
+	}
+
+	// If only one of the day of the month and day of the week fields is restricted, cron only checks that one. If
+	// both are restricted, cron checks if either matches, which is what we want
+	daysOfWeek := "*"
+	if f.DaysOfWeek != "" {
+		if !daysOfWeekRegex.Match([]byte(f.DaysOfWeek)) {
+			return nil, errors.New("daysOfWeek must be in the format MON-FRI or SAT,SUN,TUE,WED")
+		}
+		daysOfWeek = f.DaysOfWeek
+	}
+	daysOfMonth := "*"
+	if len(f.DaysOfMonth) > 0 {
+		days := make([]string, 0, len(f.DaysOfMonth))
+		for _, day := range f.DaysOfMonth {
+			if day < 1 || day > 31 {
+				return nil, fmt.Errorf("daysOfMonth must be from 1 to 31, not %d", day)
+			}
+			days = append(days, strconv.Itoa(int(day)))
+		}
+		daysOfMonth = strings.Join(days, ",")
+	}
+
+	result := make([]string, 0, len(timesOfDay))
+	for _, timeOfDay := range timesOfDay {
+		result = append(result, fmt.Sprintf("%d %d %s * %s", int(timeOfDay.Minutes())%60, int(timeOfDay.Hours()), daysOfMonth, daysOfWeek))
+	}
+	return result, nil
 }
 
 // A specific event in the schedule
@@ -77,13 +255,94 @@ type EventSpec struct {
 
 	// CronSchedule can contain an arbitrary Golang Cron Schedule
 	// (see https://pkg.go.dev/github.com/robfig/cron#hdr-CRON_Expression_Format)
-	// If set, takes precedence over Schedule
+	// If set, takes precedence over Schedule. An interval such as '@every 90m' makes the event happen at a constant
+	// interval, anchored to Epoch
 	// +optional
 	CronSchedule string `json:"cronSchedule,omitempty"`
 
+	// Epoch anchors an '@every' CronSchedule: the event happens at the epoch, and at every whole multiple of the
+	// interval before and after it. Either a time in the event's timezone in the format yyyy-mm-ddThh:mm, or an
+	// RFC 3339 timestamp with an explicit UTC offset. Defaults to 1970-01-01T00:00:00Z
+	// +optional
+	Epoch string `json:"epoch,omitempty"`
+
 	// Schedule is a more user friendly way to specify an event schedule
 	// It's more limited than the format supported by CronSchedule
 	Schedule *FriendlyScheduleSpec `json:"schedule,omitempty"`
+
+	// Session schedules the event relative to the open or close of an exchange's trading session, on that
+	// exchange's trading days. If set, CronSchedule and Schedule are ignored
+	// +optional
+	Session *SessionEventSpec `json:"session,omitempty"`
+
+	// At makes this a one-off event, which happens only once at the given time, rather than on a schedule. Either a
+	// time in the event's timezone in the format yyyy-mm-ddThh:mm (e.g. 2026-12-24T06:00), or an RFC 3339 timestamp
+	// with an explicit UTC offset (e.g. 2026-12-24T06:00:00Z). Exclusions and calendars don't apply to one-off
+	// events. If set, CronSchedule and Schedule are ignored, and Session must not be set
+	// +optional
+	At string `json:"at,omitempty"`
+
+	// Timezone overrides the ControlledJob's timezone for this event only. For example, a job could start at
+	// 08:00 in Europe/London and stop at 16:30 in America/New_York. Exclusions still apply to dates in the
+	// ControlledJob's timezone. Ignored for session events, which always use the exchange's timezone
+	// +optional
+	Timezone *TimezoneSpec `json:"timezone,omitempty"`
+
+	// RunFor can only be set on start events. It adds an implied stop event this long after each time the start
+	// event is scheduled, e.g. '7h30m'. This is elapsed time, so if the clocks change during a run then the
+	// implied stop happens an hour earlier or later on the clock than usual
+	// +optional
+	RunFor *metav1.Duration `json:"runFor,omitempty"`
+
+	// Name identifies this event, so that other events can be scheduled relative to it. Names must be unique
+	// within a ControlledJob
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// RelativeTo schedules this event a fixed time before or after each time another event is scheduled, instead
+	// of on a schedule of its own. For example 'stop 10 minutes before the close event'. If set, CronSchedule,
+	// Schedule, Session and At must not be
+	// +optional
+	RelativeTo *RelativeEventSpec `json:"relativeTo,omitempty"`
+}
+
+// RelativeEventSpec schedules an event relative to another event in the same ControlledJob
+type RelativeEventSpec struct {
+	// Event is the name of the event this one is scheduled relative to. It's scheduled relative to each time that
+	// event is scheduled, even on dates when an exclusion means that event doesn't happen. Events can be relative to
+	// events which are themselves relative to other events, as long as there is no cycle
+	Event string `json:"event"`
+
+	// Offset from the other event at which this one happens, e.g. '-10m' for 10 minutes before it or '1h' for an
+	// hour after it. The offset is on the clock in the event's timezone, so an event an hour after 01:30 happens at
+	// 02:30 even if the clocks change in between
+	Offset metav1.Duration `json:"offset"`
+}
+
+// SessionAnchor is the point in an exchange's trading session an event is scheduled relative to
+type SessionAnchor string
+
+const (
+	SessionAnchorOpen  SessionAnchor = "open"
+	SessionAnchorClose SessionAnchor = "close"
+)
+
+// SessionEventSpec schedules an event relative to the trading session of an exchange. For example '30 minutes
+// before the open' or '15 minutes after the close'. The event happens once on every trading day of the exchange,
+// and on half-days the close is the early close
+type SessionEventSpec struct {
+	// Exchange is the market identifier code (MIC) of the exchange, e.g. XLON, XNYS or XETR. Some exchanges can
+	// also be referred to by a common name, e.g. LSE or NYSE
+	Exchange string `json:"exchange"`
+
+	// Anchor is the point in the trading session the event is relative to
+	// +kubebuilder:validation:Enum=open;close
+	Anchor SessionAnchor `json:"anchor"`
+
+	// OffsetMinutes from the anchor at which the event happens. Negative values are before the anchor, positive
+	// values after it
+	// +optional
+	OffsetMinutes int32 `json:"offsetMinutes,omitempty"`
 }
 
 var (
@@ -92,36 +351,126 @@ var (
 )
 
 // AsCronSpec presents the given EventSpec in CronTab format (as that's how the scheduling code needs to process it in).
-// If a CronSchedule is provided, it is returned unaltered and un-validated. Otherwise we check against some regexes for validation
-// before converting to a cron format
+// If a CronSchedule is provided, it is returned unaltered and un-validated. Otherwise we check against some regexes for validation.
+// A FriendlyScheduleSpec with several times of day, or which chooses days in ways cron can't express, can't be
+// presented as a single CronTab schedule
 func (e *EventSpec) AsCronSpec() (string, error) {
 	if e.CronSchedule != "" {
 		return e.CronSchedule, nil
 	}
-	if e.Schedule.TimeOfDay == "" || e.Schedule.DaysOfWeek == "" {
+	if e.Schedule == nil {
 		return "", errors.New("must specify either cronSchedule or schedule")
 	}
-	timeOfDayMatches := timeOfDayRegex.FindStringSubmatch(e.Schedule.TimeOfDay)
-	// timeOfDayMatches should contain the entire string, the hours part, and the minutes part (the two subexpressions), so should
-	// have a length of 3
-	if len(timeOfDayMatches) != 3 {
-		return "", errors.New("timeOfDay must be in the format hh:mm")
+	cronSpecs, err := e.Schedule.AsCronSpecs()
+	if err != nil {
+		return "", err
+	}
+	if len(cronSpecs) != 1 || e.Schedule.HasMonthlyRules() {
+		return "", errors.New("schedule can't be expressed as a single cron schedule")
+	}
+	return cronSpecs[0], nil
+}
+
+// ExclusionDateFormat is the format of the dates in an ExclusionSpec
+const ExclusionDateFormat = "2006-01-02"
+
+// OneOffTimeFormat is the format of the time of a one-off event, when it's given in the event's timezone
+const OneOffTimeFormat = "2006-01-02T15:04"
+
+// AsOneOffTime parses the time of a one-off event. If At has an explicit UTC offset, then hasOffset is true and the
+// result is the exact instant At refers to. Otherwise the result is the wall clock time At refers to, in UTC, which
+// still needs interpreting in the event's timezone
+func (e *EventSpec) AsOneOffTime() (result time.Time, hasOffset bool, err error) {
+	result, hasOffset, ok := parseEventTime(e.At)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("at must be in the format yyyy-mm-ddThh:mm, or an RFC 3339 timestamp: %s", e.At)
+	}
+	return result, hasOffset, nil
+}
+
+// AsEpoch parses the epoch of an '@every' CronSchedule, in the same way as AsOneOffTime. If Epoch isn't set then
+// the result is the Unix epoch
+func (e *EventSpec) AsEpoch() (result time.Time, hasOffset bool, err error) {
+	if e.Epoch == "" {
+		return time.Unix(0, 0).UTC(), true, nil
 	}
+	result, hasOffset, ok := parseEventTime(e.Epoch)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("epoch must be in the format yyyy-mm-ddThh:mm, or an RFC 3339 timestamp: %s", e.Epoch)
+	}
+	return result, hasOffset, nil
+}
 
-	if !daysOfWeekRegex.Match([]byte(e.Schedule.DaysOfWeek)) {
-		return "", errors.New("daysOfWeek must be in the format MON-FRI or SAT,SUN,TUE,WED")
+// parseEventTime parses either an RFC 3339 timestamp, or a wall clock time in the format OneOffTimeFormat (with
+// optional seconds)
+func parseEventTime(value string) (result time.Time, hasOffset bool, ok bool) {
+	if result, err := time.Parse(time.RFC3339, value); err == nil {
+		return result, true, true
 	}
+	for _, format := range []string{OneOffTimeFormat, OneOffTimeFormat + ":05"} {
+		if result, err := time.Parse(format, value); err == nil {
+			return result, false, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// ExclusionSpec is a date, or a range of dates, on which start events are skipped. For example a bank holiday
+type ExclusionSpec struct {
+	// Date to exclude, or the first date of the range to exclude if EndDate is set. Interpreted in the
+	// ControlledJob's Timezone
+	// Format: yyyy-mm-dd
+	// +kubebuilder:validation:Pattern:=`^\d{4}-\d{2}-\d{2}$`
+	Date string `json:"date"`
+
+	// EndDate is the last date (inclusive) of the range to exclude. If not set, only Date is excluded
+	// Format: yyyy-mm-dd
+	// +kubebuilder:validation:Pattern:=`^\d{4}-\d{2}-\d{2}$`
+	// +optional
+	EndDate string `json:"endDate,omitempty"`
+
+	// Description of the exclusion, e.g. 'Christmas Day'. Not used by the controller
+	// +optional
+	Description string `json:"description,omitempty"`
+}
 
-	return fmt.Sprintf("%s %s * * %s", timeOfDayMatches[2], timeOfDayMatches[1], e.Schedule.DaysOfWeek), nil
+// AsDateRange returns the first and last excluded dates, at midnight UTC. If no EndDate is provided, both
+// are the same date
+func (e *ExclusionSpec) AsDateRange() (first time.Time, last time.Time, err error) {
+	first, err = time.Parse(ExclusionDateFormat, e.Date)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("date must be in the format yyyy-mm-dd: %w", err)
+	}
+	if e.EndDate == "" {
+		return first, first, nil
+	}
+	last, err = time.Parse(ExclusionDateFormat, e.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("endDate must be in the format yyyy-mm-dd: %w", err)
+	}
+	if last.Before(first) {
+		return time.Time{}, time.Time{}, fmt.Errorf("endDate %s is before date %s", e.EndDate, e.Date)
+	}
+	return first, last, nil
 }
 
 type FailurePolicy string
 
 const (
-	NeverRestartFailurePolicy  FailurePolicy = "NeverRestart"
+	// NeverRestartFailurePolicy leaves a failed Job in place until the end of the run period
+	NeverRestartFailurePolicy FailurePolicy = "NeverRestart"
+	// AlwaysRestartFailurePolicy replaces a failed Job with a new Job (after a backoff), as long as we're still inside
+	// the run period and the restart budget for the run period has not been exhausted
 	AlwaysRestartFailurePolicy FailurePolicy = "AlwaysRestart"
 )
 
+const (
+	// DefaultFailureBackoffSeconds is the delay before the first restart of a failed Job, if not otherwise specified
+	DefaultFailureBackoffSeconds int32 = 10
+	// DefaultMaxFailureBackoffSeconds caps the exponential backoff between restarts of failed Jobs, if not otherwise specified
+	DefaultMaxFailureBackoffSeconds int32 = 600
+)
+
 type SpecChangePolicy string
 
 type RestartStrategy struct {
@@ -138,6 +487,39 @@ type RestartStrategy struct {
 	// https://kubernetes.io/docs/concepts/workloads/controllers/deployment/#strategy
 	// +optional
 	SpecChangePolicy SpecChangePolicy `json:"specChangePolicy,omitempty"`
+
+	// FailurePolicy deals with what to do when a Job fails inside its scheduled run period
+	// Valid values are:
+	//
+	// - "NeverRestart": (default) Leave the failed Job in place. No new Job will be created until the next run period
+	//
+	// - "AlwaysRestart": Create a new Job in the same run period, after waiting for the backoff. The backoff doubles
+	//     for each restart in the run period, up to MaxBackoffSeconds. No more Jobs are created once
+	//     MaxRestartsPerRunPeriod has been reached
+	// +kubebuilder:validation:Enum=NeverRestart;AlwaysRestart
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	//+kubebuilder:validation:Minimum=0
+
+	// BackoffSeconds is how long to wait after a Job fails before creating a new one. Only used by the AlwaysRestart
+	// FailurePolicy. Defaults to 10s
+	// +optional
+	BackoffSeconds *int32 `json:"backoffSeconds,omitempty"`
+
+	//+kubebuilder:validation:Minimum=0
+
+	// MaxBackoffSeconds is the upper limit of the exponential backoff between restarts of failed Jobs. Only used by
+	// the AlwaysRestart FailurePolicy. Defaults to 600s
+	// +optional
+	MaxBackoffSeconds *int32 `json:"maxBackoffSeconds,omitempty"`
+
+	//+kubebuilder:validation:Minimum=0
+
+	// MaxRestartsPerRunPeriod is the maximum number of times a failed Job will be replaced in a single run period.
+	// Only used by the AlwaysRestart FailurePolicy. If not set, there is no limit
+	// +optional
+	MaxRestartsPerRunPeriod *int32 `json:"maxRestartsPerRunPeriod,omitempty"`
 }
 
 const (
@@ -149,15 +531,54 @@ const (
 	RecreateSpecChangePolicy SpecChangePolicy = "Recreate"
 )
 
+// ConcurrencyPolicy describes how a start-only schedule treats a Job from an earlier run period which is still running
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent lets the new Job run alongside any Jobs from earlier run periods which are still running
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips the new run period, without starting a Job, if any Jobs from earlier run periods are
+	// still running at its start
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent stops any Jobs from earlier run periods which are still running, and starts the new Job once
+	// they have fully terminated
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// EventPrecedence says which kind of event wins when a start event and a stop event happen at the same instant
+// +kubebuilder:validation:Enum=Start;Stop
+type EventPrecedence string
+
+const (
+	// StartEventPrecedence ends the current run period and starts a new one at that instant, so any running Job is
+	// replaced by a new Job. If the ControlledJob wasn't running, it starts as if there was no stop event
+	StartEventPrecedence EventPrecedence = "Start"
+	// StopEventPrecedence stops the ControlledJob, as if there was no start event
+	StopEventPrecedence EventPrecedence = "Stop"
+)
+
 // ControlledJobSpec defines the desired state of ControlledJob
 type ControlledJobSpec struct {
 
-	// Timezone which governs the timing of all Events
+	// Timezone which governs the timing of all Events, other than session events which happen in the timezone of
+	// their exchange and events which specify their own timezone
 	Timezone TimezoneSpec `json:"timezone"`
 
 	// Events are a list of timings and operations to perform at those times. For example, 'start at 09:00', 'stop every hour on the half hour'
 	Events []EventSpec `json:"events"`
 
+	// Exclusions are dates on which start events are skipped, for example bank holidays. Only start events are
+	// affected: a Job which was started before an excluded date keeps running until the next stop event as usual,
+	// even if that falls on the excluded date. Stop and restart events are never skipped
+	// +optional
+	Exclusions []ExclusionSpec `json:"exclusions,omitempty"`
+
+	// CalendarRef refers to a Calendar (in the same namespace) or ClusterCalendar whose holidays, early closes
+	// and extra working days apply to this ControlledJob's Events, in addition to its own Exclusions
+	// +optional
+	CalendarRef *CalendarReference `json:"calendarRef,omitempty"`
+
 	// Specifies the job that will be created when executing a CronJob. Uses the native Kubernetes JobTemplateSpec, and so supports all features
 	// Kubernetes Jobs natively support
 	JobTemplate batchv1beta1.JobTemplateSpec `json:"jobTemplate"`
@@ -179,11 +600,73 @@ type ControlledJobSpec struct {
 	// +optional
 	RestartStrategy RestartStrategy `json:"restartStrategy,omitempty"`
 
+	// ConcurrencyPolicy only applies to start-only schedules (that is, schedules with no stop events), and must be set
+	// to use one. In a start-only schedule each start event begins a new run period, and Jobs run to completion
+	// instead of being stopped. ConcurrencyPolicy says what to do with Jobs from an earlier run period which are still
+	// running when a new run period starts:
+	//
+	// - "Allow": start the new Job anyway, so that they run concurrently
+	//
+	// - "Forbid": skip the new run period, so that no Job is started until the next start event
+	//
+	// - "Replace": stop the earlier Jobs, and start the new Job once they have fully terminated
+	//
+	// Jobs from earlier run periods which have already completed or failed are deleted when a new run period starts
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// EventPrecedence says what happens when a start event and a stop event (including a stop implied by runFor,
+	// or an early close in the calendar) happen at the same instant:
+	//
+	// - "Start": the current run period ends and a new one starts straight away
+	//
+	// - "Stop": the ControlledJob stops, and the start event has no effect
+	//
+	// If not set, the stop event wins, as with "Stop", and the ScheduleWarnings condition warns about it
+	// +optional
+	EventPrecedence EventPrecedence `json:"eventPrecedence,omitempty"`
+
 	// This flag tells the controller to suspend subsequent executions, it does
 	// not apply to already started executions.  Defaults to false.
-	// Is also set by the controller when a job fails and should not be restarted.
+	// This is only ever set by the user. When the controller suspends a ControlledJob itself
+	// (see SuspendAfterFailedRunPeriods) it records that in status.autoSuspended instead
 	// +optional
 	Suspend *bool `json:"suspend,omitempty"`
+
+	//+kubebuilder:validation:Minimum=1
+
+	// SuspendAfterFailedRunPeriods tells the controller to suspend this ControlledJob once this many consecutive
+	// run periods have ended up with a failed Job. A run period counts as failed once its Job has failed and will
+	// not be replaced (see RestartStrategy.FailurePolicy).
+	// The controller records this in status.autoSuspended, which must be cleared explicitly before any more
+	// Jobs are started. If not set, the controller never suspends a ControlledJob itself
+	// +optional
+	SuspendAfterFailedRunPeriods *int32 `json:"suspendAfterFailedRunPeriods,omitempty"`
+}
+
+// AutoSuspendedStatus records why and when the controller suspended a ControlledJob
+type AutoSuspendedStatus struct {
+	// Reason is a CamelCase reason for the suspension
+	Reason string `json:"reason"`
+	// Message is a human-readable explanation of the suspension
+	// +optional
+	Message string `json:"message,omitempty"`
+	// SuspendedAt is the time the controller suspended the ControlledJob
+	SuspendedAt metav1.Time `json:"suspendedAt"`
+}
+
+// FailedRunPeriodsStatus keeps track of consecutive failed run periods, so the controller can
+// apply SuspendAfterFailedRunPeriods
+type FailedRunPeriodsStatus struct {
+	// RunPeriodStartTime identifies the most recent run period seen by the controller
+	RunPeriodStartTime metav1.Time `json:"runPeriodStartTime"`
+	// RunPeriodFailed is true if a Job failed in that run period and will not be replaced
+	// +optional
+	RunPeriodFailed bool `json:"runPeriodFailed,omitempty"`
+	// ConsecutiveFailures is the number of consecutive failed run periods, up to and including
+	// the most recent one if it has failed
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
 }
 
 // ControlledJobStatus defines the observed state of ControlledJob
@@ -205,12 +688,27 @@ type ControlledJobStatus struct {
 	// +optional
 	IsRunning *bool `json:"isRunning,omitempty"`
 
-	// Set by the controller when a job fails and we shouldn't
-	// restart it. Can be cleared by the user resetting the
-	// spec.suspend flag
+	// IsSuspended is true if the ControlledJob is suspended, either because the user set
+	// spec.suspend, or because the controller suspended it (see AutoSuspended)
 	// +optional
 	IsSuspended *bool `json:"isSuspended,omitempty"`
 
+	// AutoSuspended is set by the controller when it suspends the ControlledJob because of repeated
+	// failures (see spec.suspendAfterFailedRunPeriods). It is never cleared by the controller: the user must
+	// clear it explicitly to resume the ControlledJob
+	// +optional
+	AutoSuspended *AutoSuspendedStatus `json:"autoSuspended,omitempty"`
+
+	// FailedRunPeriods tracks failed run periods. Only maintained if spec.suspendAfterFailedRunPeriods is set
+	// +optional
+	FailedRunPeriods *FailedRunPeriodsStatus `json:"failedRunPeriods,omitempty"`
+
+	// SkippedRunPeriodStartTime is the start of the most recent run period of a start-only schedule which was skipped
+	// because a Job from an earlier run period was still running and the ConcurrencyPolicy is Forbid. No Job is
+	// created in that run period, even once the earlier Job finishes
+	// +optional
+	SkippedRunPeriodStartTime *metav1.Time `json:"skippedRunPeriodStartTime,omitempty"`
+
 	// MostRecentAction is the most recent action taken by this ControlledJob
 	// +optional
 	MostRecentAction *ControlledJobActionHistoryEntry `json:"mostRecentAction,omitempty"`
@@ -263,9 +761,14 @@ const (
 	// between a stop and start time. If there are no start times, it will be set to Unknown
 	ConditionTypeShouldBeRunning ControlledJobConditionType = "ShouldBeRunning"
 
-	// ConditionTypeSuspended is set to True if the user has marked this ControlledJob as suspended
+	// ConditionTypeSuspended is set to True if the user has marked this ControlledJob as suspended, or if the
+	// controller has suspended it because of repeated failures
 	ConditionTypeSuspended ControlledJobConditionType = "Suspended"
 
+	// ConditionTypeAutoSuspended is set to True if the controller has suspended this ControlledJob because Jobs have
+	// failed in too many consecutive run periods
+	ConditionTypeAutoSuspended ControlledJobConditionType = "AutoSuspended"
+
 	// ConditionTypeOutOfDate is True if the spec of the running job does not match the desired JobSpec, and we
 	// are not able to recreate the job with the new spec
 	ConditionTypeOutOfDate ControlledJobConditionType = "OutOfDate"
@@ -314,6 +817,14 @@ const (
 	// ConditionTypeFailedToDeleteJob occurs if we expect to be starting a job, but the configured StartingDeadline has been exceeded
 	ConditionTypeStartingDeadlineExceeded ControlledJobConditionType = "StartingDeadlineExceeded"
 
+	// ConditionTypeRestartBudgetExhausted is True if the current job failed, and we would have replaced it because of the
+	// AlwaysRestart FailurePolicy, but have already reached MaxRestartsPerRunPeriod
+	ConditionTypeRestartBudgetExhausted ControlledJobConditionType = "RestartBudgetExhausted"
+
+	// ConditionTypeRunPeriodSkipped is True if the current run period of a start-only schedule was skipped, without
+	// starting a Job, because a Job from an earlier run period was still running and the ConcurrencyPolicy is Forbid
+	ConditionTypeRunPeriodSkipped ControlledJobConditionType = "RunPeriodSkipped"
+
 	// ConditionTypeRunningExpectedly is true if JobPotentiallyRunning, and either ShouldBeRunning or JobManuallyScheduled
 	ConditionTypeRunningExpectedly ControlledJobConditionType = "RunningExpectedly"
 
@@ -326,6 +837,11 @@ const (
 	// ConditionTypeNotRunningUnexpectedly is true if NOT JobPotentiallyRunning, and either ShouldBeRunning or JobManuallyScheduled
 	ConditionTypeNotRunningUnexpectedly ControlledJobConditionType = "NotRunningUnexpectedly"
 
+	// ConditionTypeScheduleWarnings is True if the schedule has events which conflict with or duplicate each
+	// other, for example a start and a stop event at the same instant without an EventPrecedence. The message
+	// describes each problem
+	ConditionTypeScheduleWarnings ControlledJobConditionType = "ScheduleWarnings"
+
 	// ConditionTypeError records if the last attempt to reconcile generated an error, and if so what error
 	ConditionTypeError ControlledJobConditionType = "Error"
 )
@@ -0,0 +1,116 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControlledJobActionType is the operation a ControlledJobAction asks for
+// +kubebuilder:validation:Enum=start;stop;restart;skip-today
+type ControlledJobActionType string
+
+const (
+	// ActionTypeStart starts a Job now, even outside the scheduled run periods. It is stopped at the next stop event
+	ActionTypeStart ControlledJobActionType = "start"
+	// ActionTypeStop stops the running Job until the next run period
+	ActionTypeStop ControlledJobActionType = "stop"
+	// ActionTypeRestart replaces the running Job with a new one
+	ActionTypeRestart ControlledJobActionType = "restart"
+	// ActionTypeSkipToday skips the rest of today's start events, by adding today's date to the ControlledJob's
+	// exclusions
+	ActionTypeSkipToday ControlledJobActionType = "skip-today"
+)
+
+// ControlledJobActionPhase is how far the controller has got with a ControlledJobAction
+type ControlledJobActionPhase string
+
+const (
+	// ActionPhaseSucceeded means the action has been carried out
+	ActionPhaseSucceeded ControlledJobActionPhase = "Succeeded"
+	// ActionPhaseFailed means the action couldn't be carried out, and won't be tried again
+	ActionPhaseFailed ControlledJobActionPhase = "Failed"
+)
+
+// ControlledJobActionSpec defines the operation to carry out on a ControlledJob
+type ControlledJobActionSpec struct {
+	// ControlledJobName is the name of the ControlledJob, in the same namespace, to operate on
+	ControlledJobName string `json:"controlledJobName"`
+
+	// Type of the operation
+	Type ControlledJobActionType `json:"type"`
+
+	// Reason for the operation, which is recorded in the ControlledJob's action history
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// ControlledJobActionStatus records the outcome of a ControlledJobAction
+type ControlledJobActionStatus struct {
+	// Phase is empty until the action has been processed
+	// +optional
+	Phase ControlledJobActionPhase `json:"phase,omitempty"`
+
+	// Message describes what the controller did, or why it couldn't
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// JobName is the name of the Job which was started, stopped or restarted, if any
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// CompletionTime is when the action was processed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// IsProcessed returns true once the controller has finished with the action, whether or not it succeeded
+func (s *ControlledJobActionStatus) IsProcessed() bool {
+	return s.Phase == ActionPhaseSucceeded || s.Phase == ActionPhaseFailed
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ControlledJob",type=string,JSONPath=`.spec.controlledJobName`
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:shortName="ctja"
+
+// ControlledJobAction asks the controller to start, stop or restart a ControlledJob, or to skip the rest of today's
+// run periods. It lets someone operate a ControlledJob without being able to edit its spec. Each action is carried out
+// once, and its outcome is recorded in its status and in the ControlledJob's action history
+type ControlledJobAction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlledJobActionSpec   `json:"spec,omitempty"`
+	Status ControlledJobActionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ControlledJobActionList contains a list of ControlledJobAction
+type ControlledJobActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControlledJobAction `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControlledJobAction{}, &ControlledJobActionList{})
+}
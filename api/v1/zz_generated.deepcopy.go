@@ -26,6 +26,183 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoSuspendedStatus) DeepCopyInto(out *AutoSuspendedStatus) {
+	*out = *in
+	in.SuspendedAt.DeepCopyInto(&out.SuspendedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoSuspendedStatus.
+func (in *AutoSuspendedStatus) DeepCopy() *AutoSuspendedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoSuspendedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Calendar) DeepCopyInto(out *Calendar) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Calendar.
+func (in *Calendar) DeepCopy() *Calendar {
+	if in == nil {
+		return nil
+	}
+	out := new(Calendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Calendar) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalendarList) DeepCopyInto(out *CalendarList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Calendar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalendarList.
+func (in *CalendarList) DeepCopy() *CalendarList {
+	if in == nil {
+		return nil
+	}
+	out := new(CalendarList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CalendarList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalendarReference) DeepCopyInto(out *CalendarReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalendarReference.
+func (in *CalendarReference) DeepCopy() *CalendarReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CalendarReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalendarSpec) DeepCopyInto(out *CalendarSpec) {
+	*out = *in
+	if in.Holidays != nil {
+		in, out := &in.Holidays, &out.Holidays
+		*out = make([]ExclusionSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.EarlyCloses != nil {
+		in, out := &in.EarlyCloses, &out.EarlyCloses
+		*out = make([]EarlyCloseSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraWorkingDays != nil {
+		in, out := &in.ExtraWorkingDays, &out.ExtraWorkingDays
+		*out = make([]ExtraWorkingDaySpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalendarSpec.
+func (in *CalendarSpec) DeepCopy() *CalendarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CalendarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCalendar) DeepCopyInto(out *ClusterCalendar) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCalendar.
+func (in *ClusterCalendar) DeepCopy() *ClusterCalendar {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCalendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCalendar) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCalendarList) DeepCopyInto(out *ClusterCalendarList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterCalendar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCalendarList.
+func (in *ClusterCalendarList) DeepCopy() *ClusterCalendarList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCalendarList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCalendarList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlledJob) DeepCopyInto(out *ControlledJob) {
 	*out = *in
@@ -53,6 +230,33 @@ func (in *ControlledJob) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlledJobAction) DeepCopyInto(out *ControlledJobAction) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlledJobAction.
+func (in *ControlledJobAction) DeepCopy() *ControlledJobAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlledJobAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ControlledJobAction) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlledJobActionHistoryEntry) DeepCopyInto(out *ControlledJobActionHistoryEntry) {
 	*out = *in
@@ -81,6 +285,72 @@ func (in *ControlledJobActionHistoryEntry) DeepCopy() *ControlledJobActionHistor
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlledJobActionList) DeepCopyInto(out *ControlledJobActionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ControlledJobAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlledJobActionList.
+func (in *ControlledJobActionList) DeepCopy() *ControlledJobActionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlledJobActionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ControlledJobActionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlledJobActionSpec) DeepCopyInto(out *ControlledJobActionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlledJobActionSpec.
+func (in *ControlledJobActionSpec) DeepCopy() *ControlledJobActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlledJobActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlledJobActionStatus) DeepCopyInto(out *ControlledJobActionStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlledJobActionStatus.
+func (in *ControlledJobActionStatus) DeepCopy() *ControlledJobActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlledJobActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlledJobList) DeepCopyInto(out *ControlledJobList) {
 	*out = *in
@@ -116,7 +386,7 @@ func (in *ControlledJobList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlledJobSpec) DeepCopyInto(out *ControlledJobSpec) {
 	*out = *in
-	out.Timezone = in.Timezone
+	in.Timezone.DeepCopyInto(&out.Timezone)
 	if in.Events != nil {
 		in, out := &in.Events, &out.Events
 		*out = make([]EventSpec, len(*in))
@@ -124,18 +394,33 @@ func (in *ControlledJobSpec) DeepCopyInto(out *ControlledJobSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Exclusions != nil {
+		in, out := &in.Exclusions, &out.Exclusions
+		*out = make([]ExclusionSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.CalendarRef != nil {
+		in, out := &in.CalendarRef, &out.CalendarRef
+		*out = new(CalendarReference)
+		**out = **in
+	}
 	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
 	if in.StartingDeadlineSeconds != nil {
 		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
 		*out = new(int64)
 		**out = **in
 	}
-	out.RestartStrategy = in.RestartStrategy
+	in.RestartStrategy.DeepCopyInto(&out.RestartStrategy)
 	if in.Suspend != nil {
 		in, out := &in.Suspend, &out.Suspend
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SuspendAfterFailedRunPeriods != nil {
+		in, out := &in.SuspendAfterFailedRunPeriods, &out.SuspendAfterFailedRunPeriods
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlledJobSpec.
@@ -175,6 +460,20 @@ func (in *ControlledJobStatus) DeepCopyInto(out *ControlledJobStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AutoSuspended != nil {
+		in, out := &in.AutoSuspended, &out.AutoSuspended
+		*out = new(AutoSuspendedStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedRunPeriods != nil {
+		in, out := &in.FailedRunPeriods, &out.FailedRunPeriods
+		*out = new(FailedRunPeriodsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SkippedRunPeriodStartTime != nil {
+		in, out := &in.SkippedRunPeriodStartTime, &out.SkippedRunPeriodStartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.MostRecentAction != nil {
 		in, out := &in.MostRecentAction, &out.MostRecentAction
 		*out = new(ControlledJobActionHistoryEntry)
@@ -206,12 +505,62 @@ func (in *ControlledJobStatus) DeepCopy() *ControlledJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DSTPolicySpec) DeepCopyInto(out *DSTPolicySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DSTPolicySpec.
+func (in *DSTPolicySpec) DeepCopy() *DSTPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DSTPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EarlyCloseSpec) DeepCopyInto(out *EarlyCloseSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EarlyCloseSpec.
+func (in *EarlyCloseSpec) DeepCopy() *EarlyCloseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EarlyCloseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventSpec) DeepCopyInto(out *EventSpec) {
 	*out = *in
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = new(FriendlyScheduleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Session != nil {
+		in, out := &in.Session, &out.Session
+		*out = new(SessionEventSpec)
+		**out = **in
+	}
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(TimezoneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RunFor != nil {
+		in, out := &in.RunFor, &out.RunFor
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RelativeTo != nil {
+		in, out := &in.RelativeTo, &out.RelativeTo
+		*out = new(RelativeEventSpec)
 		**out = **in
 	}
 }
@@ -226,9 +575,70 @@ func (in *EventSpec) DeepCopy() *EventSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExclusionSpec) DeepCopyInto(out *ExclusionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExclusionSpec.
+func (in *ExclusionSpec) DeepCopy() *ExclusionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExclusionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraWorkingDaySpec) DeepCopyInto(out *ExtraWorkingDaySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraWorkingDaySpec.
+func (in *ExtraWorkingDaySpec) DeepCopy() *ExtraWorkingDaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraWorkingDaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedRunPeriodsStatus) DeepCopyInto(out *FailedRunPeriodsStatus) {
+	*out = *in
+	in.RunPeriodStartTime.DeepCopyInto(&out.RunPeriodStartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedRunPeriodsStatus.
+func (in *FailedRunPeriodsStatus) DeepCopy() *FailedRunPeriodsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedRunPeriodsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FriendlyScheduleSpec) DeepCopyInto(out *FriendlyScheduleSpec) {
 	*out = *in
+	if in.TimesOfDay != nil {
+		in, out := &in.TimesOfDay, &out.TimesOfDay
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DaysOfMonth != nil {
+		in, out := &in.DaysOfMonth, &out.DaysOfMonth
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.WeekdaysOfMonth != nil {
+		in, out := &in.WeekdaysOfMonth, &out.WeekdaysOfMonth
+		*out = make([]WeekdayOfMonthSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FriendlyScheduleSpec.
@@ -241,9 +651,40 @@ func (in *FriendlyScheduleSpec) DeepCopy() *FriendlyScheduleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelativeEventSpec) DeepCopyInto(out *RelativeEventSpec) {
+	*out = *in
+	out.Offset = in.Offset
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelativeEventSpec.
+func (in *RelativeEventSpec) DeepCopy() *RelativeEventSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RelativeEventSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestartStrategy) DeepCopyInto(out *RestartStrategy) {
 	*out = *in
+	if in.BackoffSeconds != nil {
+		in, out := &in.BackoffSeconds, &out.BackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackoffSeconds != nil {
+		in, out := &in.MaxBackoffSeconds, &out.MaxBackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRestartsPerRunPeriod != nil {
+		in, out := &in.MaxRestartsPerRunPeriod, &out.MaxRestartsPerRunPeriod
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartStrategy.
@@ -256,9 +697,29 @@ func (in *RestartStrategy) DeepCopy() *RestartStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionEventSpec) DeepCopyInto(out *SessionEventSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionEventSpec.
+func (in *SessionEventSpec) DeepCopy() *SessionEventSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionEventSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimezoneSpec) DeepCopyInto(out *TimezoneSpec) {
 	*out = *in
+	if in.DSTPolicy != nil {
+		in, out := &in.DSTPolicy, &out.DSTPolicy
+		*out = new(DSTPolicySpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimezoneSpec.
@@ -270,3 +731,18 @@ func (in *TimezoneSpec) DeepCopy() *TimezoneSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeekdayOfMonthSpec) DeepCopyInto(out *WeekdayOfMonthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeekdayOfMonthSpec.
+func (in *WeekdayOfMonthSpec) DeepCopy() *WeekdayOfMonthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WeekdayOfMonthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
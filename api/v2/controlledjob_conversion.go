@@ -0,0 +1,195 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/batch/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+)
+
+// ConvertTo converts this ControlledJob to the hub version (v1)
+func (src *ControlledJob) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.ControlledJob)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.Timezone = timezoneToV1(src.Spec.Timezone)
+	dst.Spec.Events = nil
+	for _, event := range src.Spec.Events {
+		dst.Spec.Events = append(dst.Spec.Events, eventToV1(event))
+	}
+	dst.Spec.Exclusions = src.Spec.Exclusions
+	dst.Spec.CalendarRef = src.Spec.CalendarRef
+	// batch/v1beta1 and batch/v1 JobTemplateSpecs share the same JobSpec, and serialize identically, so the
+	// template hash recorded on existing Jobs still matches after conversion
+	dst.Spec.JobTemplate = v1beta1.JobTemplateSpec{
+		ObjectMeta: src.Spec.JobTemplate.ObjectMeta,
+		Spec:       src.Spec.JobTemplate.Spec,
+	}
+	dst.Spec.StartingDeadlineSeconds = src.Spec.StartingDeadlineSeconds
+	dst.Spec.RestartStrategy = src.Spec.RestartStrategy
+	dst.Spec.ConcurrencyPolicy = src.Spec.ConcurrencyPolicy
+	dst.Spec.EventPrecedence = src.Spec.EventPrecedence
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.SuspendAfterFailedRunPeriods = src.Spec.SuspendAfterFailedRunPeriods
+
+	dst.Status.Active = src.Status.Active
+	dst.Status.LastScheduledStartTime = src.Status.LastScheduledStartTime
+	dst.Status.ShouldBeRunning = src.Status.ShouldBeRunning
+	dst.Status.IsRunning = src.Status.IsRunning
+	dst.Status.IsSuspended = src.Status.IsSuspended
+	dst.Status.AutoSuspended = src.Status.AutoSuspended
+	dst.Status.FailedRunPeriods = src.Status.FailedRunPeriods
+	dst.Status.SkippedRunPeriodStartTime = src.Status.SkippedRunPeriodStartTime
+	dst.Status.MostRecentAction = nil
+	if src.Status.MostRecentAction != nil {
+		mostRecentAction := historyEntryToV1(*src.Status.MostRecentAction)
+		dst.Status.MostRecentAction = &mostRecentAction
+	}
+	dst.Status.ActionHistory = nil
+	for _, entry := range src.Status.ActionHistory {
+		dst.Status.ActionHistory = append(dst.Status.ActionHistory, historyEntryToV1(entry))
+	}
+	dst.Status.Conditions = src.Status.Conditions
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *ControlledJob) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.ControlledJob)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.Timezone = timezoneFromV1(src.Spec.Timezone)
+	dst.Spec.Events = nil
+	for _, event := range src.Spec.Events {
+		dst.Spec.Events = append(dst.Spec.Events, eventFromV1(event))
+	}
+	dst.Spec.Exclusions = src.Spec.Exclusions
+	dst.Spec.CalendarRef = src.Spec.CalendarRef
+	dst.Spec.JobTemplate = batchv1.JobTemplateSpec{
+		ObjectMeta: src.Spec.JobTemplate.ObjectMeta,
+		Spec:       src.Spec.JobTemplate.Spec,
+	}
+	dst.Spec.StartingDeadlineSeconds = src.Spec.StartingDeadlineSeconds
+	dst.Spec.RestartStrategy = src.Spec.RestartStrategy
+	dst.Spec.ConcurrencyPolicy = src.Spec.ConcurrencyPolicy
+	dst.Spec.EventPrecedence = src.Spec.EventPrecedence
+	dst.Spec.Suspend = src.Spec.Suspend
+	dst.Spec.SuspendAfterFailedRunPeriods = src.Spec.SuspendAfterFailedRunPeriods
+
+	dst.Status.Active = src.Status.Active
+	dst.Status.LastScheduledStartTime = src.Status.LastScheduledStartTime
+	dst.Status.ShouldBeRunning = src.Status.ShouldBeRunning
+	dst.Status.IsRunning = src.Status.IsRunning
+	dst.Status.IsSuspended = src.Status.IsSuspended
+	dst.Status.AutoSuspended = src.Status.AutoSuspended
+	dst.Status.FailedRunPeriods = src.Status.FailedRunPeriods
+	dst.Status.SkippedRunPeriodStartTime = src.Status.SkippedRunPeriodStartTime
+	dst.Status.MostRecentAction = nil
+	if src.Status.MostRecentAction != nil {
+		mostRecentAction := historyEntryFromV1(*src.Status.MostRecentAction)
+		dst.Status.MostRecentAction = &mostRecentAction
+	}
+	dst.Status.ActionHistory = nil
+	for _, entry := range src.Status.ActionHistory {
+		dst.Status.ActionHistory = append(dst.Status.ActionHistory, historyEntryFromV1(entry))
+	}
+	dst.Status.Conditions = src.Status.Conditions
+
+	return nil
+}
+
+func timezoneToV1(timezone TimezoneSpec) v1.TimezoneSpec {
+	return v1.TimezoneSpec{
+		Name:          timezone.Name,
+		OffsetSeconds: timezone.OffsetSeconds,
+		DSTPolicy:     timezone.DSTPolicy,
+	}
+}
+
+func timezoneFromV1(timezone v1.TimezoneSpec) TimezoneSpec {
+	return TimezoneSpec{
+		Name:          timezone.Name,
+		OffsetSeconds: timezone.OffsetSeconds,
+		DSTPolicy:     timezone.DSTPolicy,
+	}
+}
+
+func eventToV1(event EventSpec) v1.EventSpec {
+	result := v1.EventSpec{
+		Action:       event.Action,
+		CronSchedule: event.CronSchedule,
+		Epoch:        event.Epoch,
+		Schedule:     event.Schedule,
+		Session:      event.Session,
+		At:           event.At,
+		RunFor:       event.RunFor,
+		Name:         event.Name,
+		RelativeTo:   event.RelativeTo,
+	}
+	if event.Timezone != nil {
+		timezone := timezoneToV1(*event.Timezone)
+		result.Timezone = &timezone
+	}
+	return result
+}
+
+func eventFromV1(event v1.EventSpec) EventSpec {
+	result := EventSpec{
+		Action:       event.Action,
+		CronSchedule: event.CronSchedule,
+		Epoch:        event.Epoch,
+		Schedule:     event.Schedule,
+		Session:      event.Session,
+		At:           event.At,
+		RunFor:       event.RunFor,
+		Name:         event.Name,
+		RelativeTo:   event.RelativeTo,
+	}
+	if event.Timezone != nil {
+		timezone := timezoneFromV1(*event.Timezone)
+		result.Timezone = &timezone
+	}
+	return result
+}
+
+func historyEntryToV1(entry ControlledJobActionHistoryEntry) v1.ControlledJobActionHistoryEntry {
+	return v1.ControlledJobActionHistoryEntry{
+		Type:               entry.Type,
+		Timestamp:          entry.Timestamp,
+		Message:            entry.Message,
+		ScheduledStartTime: entry.ScheduledStartTime,
+		JobIndex:           entry.JobIndex,
+		JobName:            entry.JobName,
+	}
+}
+
+func historyEntryFromV1(entry v1.ControlledJobActionHistoryEntry) ControlledJobActionHistoryEntry {
+	return ControlledJobActionHistoryEntry{
+		Type:               entry.Type,
+		Timestamp:          entry.Timestamp,
+		Message:            entry.Message,
+		ScheduledStartTime: entry.ScheduledStartTime,
+		JobIndex:           entry.JobIndex,
+		JobName:            entry.JobName,
+	}
+}
@@ -0,0 +1,111 @@
+package v2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+func v1ControlledJob() *v1.ControlledJob {
+	timestamp := metav1.NewTime(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	return &v1.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-job", Annotations: map[string]string{"foo": "bar"}},
+		Spec: v1.ControlledJobSpec{
+			Timezone: v1.TimezoneSpec{Name: "Europe/London", OffsetSeconds: 60},
+			Events: []v1.EventSpec{
+				{Action: v1.EventTypeStart, CronSchedule: "0 9 * * MON-FRI", RunFor: &metav1.Duration{Duration: 8 * time.Hour}, Name: "open"},
+				{Action: v1.EventTypeRestart, RelativeTo: &v1.RelativeEventSpec{Event: "open", Offset: metav1.Duration{Duration: time.Hour}}},
+				{Action: v1.EventTypeRestart, At: "2024-03-04T12:00", Timezone: &v1.TimezoneSpec{Name: "America/New_York", OffsetSeconds: -30}},
+			},
+			Exclusions: []v1.ExclusionSpec{{Date: "2024-12-25"}},
+			JobTemplate: v1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "my-job"}},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: pointer.Int32(2),
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers:    []corev1.Container{{Name: "main", Image: "busybox", Args: []string{"sleep", "3600"}}},
+							RestartPolicy: corev1.RestartPolicyNever,
+						},
+					},
+				},
+			},
+			StartingDeadlineSeconds: pointer.Int64(300),
+			EventPrecedence:         v1.StartEventPrecedence,
+			Suspend:                 pointer.Bool(false),
+		},
+		Status: v1.ControlledJobStatus{
+			IsRunning:                 pointer.Bool(true),
+			SkippedRunPeriodStartTime: &timestamp,
+			MostRecentAction:          &v1.ControlledJobActionHistoryEntry{Type: "JobStarted", Timestamp: &timestamp, JobName: "my-job-1234-0"},
+			ActionHistory: []v1.ControlledJobActionHistoryEntry{
+				{Type: "JobStarted", Timestamp: &timestamp, JobName: "my-job-1234-0", JobIndex: pointer.Int(0)},
+			},
+		},
+	}
+}
+
+func Test_ConvertFrom_ConvertTo_RoundTrip(t *testing.T) {
+	original := v1ControlledJob()
+
+	var converted ControlledJob
+	assert.Nil(t, converted.ConvertFrom(original.DeepCopy()))
+	var roundTripped v1.ControlledJob
+	assert.Nil(t, converted.ConvertTo(&roundTripped))
+
+	assert.Equal(t, original, &roundTripped, "converting to v2 and back again should not change anything")
+	assert.Equal(t, int32(-30), converted.Spec.Events[2].Timezone.OffsetSeconds)
+}
+
+func Test_ConvertFrom_ConvertTo_KeepsScheduledStartTime(t *testing.T) {
+	// History entries recorded by older versions of the operator have the deprecated ScheduledStartTime, and
+	// migrating them to the v2 storage version must not lose it
+	original := v1ControlledJob()
+	scheduledStartTime := metav1.NewTime(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	original.Status.MostRecentAction.ScheduledStartTime = &scheduledStartTime
+	original.Status.ActionHistory[0].ScheduledStartTime = &scheduledStartTime
+
+	var converted ControlledJob
+	assert.Nil(t, converted.ConvertFrom(original.DeepCopy()))
+	var roundTripped v1.ControlledJob
+	assert.Nil(t, converted.ConvertTo(&roundTripped))
+
+	assert.Equal(t, original, &roundTripped, "converting to v2 and back again should not change anything")
+}
+
+func Test_TimezoneSpec_OffsetSecondsSerialization(t *testing.T) {
+	asJSON, err := json.Marshal(TimezoneSpec{Name: "UTC", OffsetSeconds: 60})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name": "UTC", "offsetSeconds": 60}`, string(asJSON))
+}
+
+func Test_ConvertTo_PreservesTemplateHash(t *testing.T) {
+	// Existing Jobs are labelled with the hash of the v1 template they were created from. If converting from v2
+	// changed the hash, every Job would be seen as out of date and recreated
+	original := v1ControlledJob()
+	expectedHash := metadata.CalculateHashFor(original.Spec.JobTemplate)
+
+	var stored ControlledJob
+	assert.Nil(t, stored.ConvertFrom(original.DeepCopy()))
+	var hub v1.ControlledJob
+	assert.Nil(t, stored.ConvertTo(&hub))
+
+	assert.Equal(t, expectedHash, metadata.CalculateHashFor(hub.Spec.JobTemplate))
+
+	v1beta1JSON, err := json.Marshal(original.Spec.JobTemplate)
+	assert.Nil(t, err)
+	v1JSON, err := json.Marshal(stored.Spec.JobTemplate)
+	assert.Nil(t, err)
+	assert.Equal(t, string(v1beta1JSON), string(v1JSON), "batch/v1 and batch/v1beta1 templates should serialize identically")
+}
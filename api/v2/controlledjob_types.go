@@ -0,0 +1,316 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+)
+
+// The v2 API differs from v1 in that:
+//
+// - the job template is a batch/v1 JobTemplateSpec, rather than the deprecated batch/v1beta1 one
+//
+// - TimezoneSpec.OffsetSeconds is serialized as offsetSeconds, rather than offset
+//
+// Everything else is the same, and reuses the v1 types. v1 is the hub that v2 is converted to and from, and the
+// version the controller works with
+
+// TimezoneSpec defines the timezone which governs scheduled times
+type TimezoneSpec struct {
+	// Name of the timezone in the tzData. See https://golang.org/pkg/time/#LoadLocation for possible values
+	Name string `json:"name"`
+
+	// Additional offset from UTC on top of the specified timezone. If the timezone is normally UTC-2, and
+	// OffsetSeconds is +3600 (1h in seconds), then the overall effect will be UTC-1. If the timezone is
+	// normally UTC+2 and OffsetSeconds is +3600, then the overall effect will be UTC+3.
+	//
+	// In practice - if you set this field to 60s on top of a normally UTC-1 timezone, then you end up with
+	// a 'UTC-59m' timezone. In that timezone 10:00 UTC == 09:01 UTC-59m. So if you have a scheduled start time
+	// of 09:00 in that UTC-59m timezone, your job will be started at 09:59 UTC
+	//
+	// +optional
+	OffsetSeconds int32 `json:"offsetSeconds,omitempty"`
+
+	// DSTPolicy says what happens to scheduled times which are skipped or repeated when the clocks change
+	// +optional
+	DSTPolicy *v1.DSTPolicySpec `json:"dstPolicy,omitempty"`
+}
+
+// A specific event in the schedule
+type EventSpec struct {
+	// Action to take at the specified time(s)
+	Action v1.EventType `json:"action"`
+
+	// CronSchedule can contain an arbitrary Golang Cron Schedule
+	// (see https://pkg.go.dev/github.com/robfig/cron#hdr-CRON_Expression_Format)
+	// If set, takes precedence over Schedule. An interval such as '@every 90m' makes the event happen at a constant
+	// interval, anchored to Epoch
+	// +optional
+	CronSchedule string `json:"cronSchedule,omitempty"`
+
+	// Epoch anchors an '@every' CronSchedule: the event happens at the epoch, and at every whole multiple of the
+	// interval before and after it. Either a time in the event's timezone in the format yyyy-mm-ddThh:mm, or an
+	// RFC 3339 timestamp with an explicit UTC offset. Defaults to 1970-01-01T00:00:00Z
+	// +optional
+	Epoch string `json:"epoch,omitempty"`
+
+	// Schedule is a more user friendly way to specify an event schedule
+	// It's more limited than the format supported by CronSchedule
+	// +optional
+	Schedule *v1.FriendlyScheduleSpec `json:"schedule,omitempty"`
+
+	// Session schedules the event relative to the open or close of an exchange's trading session, on that
+	// exchange's trading days. If set, CronSchedule and Schedule are ignored
+	// +optional
+	Session *v1.SessionEventSpec `json:"session,omitempty"`
+
+	// At makes this a one-off event, which happens only once at the given time, rather than on a schedule. Either a
+	// time in the event's timezone in the format yyyy-mm-ddThh:mm (e.g. 2026-12-24T06:00), or an RFC 3339 timestamp
+	// with an explicit UTC offset (e.g. 2026-12-24T06:00:00Z). Exclusions and calendars don't apply to one-off
+	// events. If set, CronSchedule and Schedule are ignored, and Session must not be set
+	// +optional
+	At string `json:"at,omitempty"`
+
+	// Timezone overrides the ControlledJob's timezone for this event only. For example, a job could start at
+	// 08:00 in Europe/London and stop at 16:30 in America/New_York. Exclusions still apply to dates in the
+	// ControlledJob's timezone. Ignored for session events, which always use the exchange's timezone
+	// +optional
+	Timezone *TimezoneSpec `json:"timezone,omitempty"`
+
+	// RunFor can only be set on start events. It adds an implied stop event this long after each time the start
+	// event is scheduled, e.g. '7h30m'. This is elapsed time, so if the clocks change during a run then the
+	// implied stop happens an hour earlier or later on the clock than usual
+	// +optional
+	RunFor *metav1.Duration `json:"runFor,omitempty"`
+
+	// Name identifies this event, so that other events can be scheduled relative to it. Names must be unique
+	// within a ControlledJob
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// RelativeTo schedules this event a fixed time before or after each time another event is scheduled, instead
+	// of on a schedule of its own. For example 'stop 10 minutes before the close event'. If set, CronSchedule,
+	// Schedule, Session and At must not be
+	// +optional
+	RelativeTo *v1.RelativeEventSpec `json:"relativeTo,omitempty"`
+}
+
+// ControlledJobSpec defines the desired state of ControlledJob
+type ControlledJobSpec struct {
+
+	// Timezone which governs the timing of all Events, other than session events which happen in the timezone of
+	// their exchange and events which specify their own timezone
+	Timezone TimezoneSpec `json:"timezone"`
+
+	// Events are a list of timings and operations to perform at those times. For example, 'start at 09:00', 'stop every hour on the half hour'
+	Events []EventSpec `json:"events"`
+
+	// Exclusions are dates on which start events are skipped, for example bank holidays. Only start events are
+	// affected: a Job which was started before an excluded date keeps running until the next stop event as usual,
+	// even if that falls on the excluded date. Stop and restart events are never skipped
+	// +optional
+	Exclusions []v1.ExclusionSpec `json:"exclusions,omitempty"`
+
+	// CalendarRef refers to a Calendar (in the same namespace) or ClusterCalendar whose holidays, early closes
+	// and extra working days apply to this ControlledJob's Events, in addition to its own Exclusions
+	// +optional
+	CalendarRef *v1.CalendarReference `json:"calendarRef,omitempty"`
+
+	// Specifies the job that will be created when executing a CronJob. Uses the native Kubernetes JobTemplateSpec, and so supports all features
+	// Kubernetes Jobs natively support
+	JobTemplate batchv1.JobTemplateSpec `json:"jobTemplate"`
+
+	//+kubebuilder:validation:Minimum=0
+
+	// Optional deadline in seconds for starting the job if it misses scheduled
+	// time for any reason. In other words, if a new job is expected to be running, but it's more than
+	// startingDeadlineSeconds after the scheduled start time, no job will be created.
+	// If not set or set to < 1 this has no effect, and jobs will always be started however long after the start
+	// time it is.
+	// WARNING!!! Be aware that enabling this setting makes it impossible to restart a controlled job this number of seconds after
+	// a scheduled start time. For example if the scheduled start time is 9am and this is set to 3600 (1h), then if you try to restart
+	// the controlled job by deleting the current Job any time after 10am, it will have no effect.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Specifies options on how to deal with job restart behaviour for various triggers
+	// +optional
+	RestartStrategy v1.RestartStrategy `json:"restartStrategy,omitempty"`
+
+	// ConcurrencyPolicy only applies to start-only schedules (that is, schedules with no stop events), and must be set
+	// to use one. In a start-only schedule each start event begins a new run period, and Jobs run to completion
+	// instead of being stopped. ConcurrencyPolicy says what to do with Jobs from an earlier run period which are still
+	// running when a new run period starts:
+	//
+	// - "Allow": start the new Job anyway, so that they run concurrently
+	//
+	// - "Forbid": skip the new run period, so that no Job is started until the next start event
+	//
+	// - "Replace": stop the earlier Jobs, and start the new Job once they have fully terminated
+	//
+	// Jobs from earlier run periods which have already completed or failed are deleted when a new run period starts
+	// +optional
+	ConcurrencyPolicy v1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// EventPrecedence says what happens when a start event and a stop event (including a stop implied by runFor,
+	// or an early close in the calendar) happen at the same instant:
+	//
+	// - "Start": the current run period ends and a new one starts straight away
+	//
+	// - "Stop": the ControlledJob stops, and the start event has no effect
+	//
+	// If not set, the stop event wins, as with "Stop", and the ScheduleWarnings condition warns about it
+	// +optional
+	EventPrecedence v1.EventPrecedence `json:"eventPrecedence,omitempty"`
+
+	// This flag tells the controller to suspend subsequent executions, it does
+	// not apply to already started executions.  Defaults to false.
+	// This is only ever set by the user. When the controller suspends a ControlledJob itself
+	// (see SuspendAfterFailedRunPeriods) it records that in status.autoSuspended instead
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	//+kubebuilder:validation:Minimum=1
+
+	// SuspendAfterFailedRunPeriods tells the controller to suspend this ControlledJob once this many consecutive
+	// run periods have ended up with a failed Job. A run period counts as failed once its Job has failed and will
+	// not be replaced (see RestartStrategy.FailurePolicy).
+	// The controller records this in status.autoSuspended, which must be cleared explicitly before any more
+	// Jobs are started. If not set, the controller never suspends a ControlledJob itself
+	// +optional
+	SuspendAfterFailedRunPeriods *int32 `json:"suspendAfterFailedRunPeriods,omitempty"`
+}
+
+// ControlledJobStatus defines the observed state of ControlledJob
+type ControlledJobStatus struct {
+
+	// A list of pointers to currently running jobs.
+	// +optional
+	Active []corev1.ObjectReference `json:"active,omitempty"`
+
+	// The most recent scheduled start time that was actioned
+	// +optional
+	LastScheduledStartTime *metav1.Time `json:"lastScheduledStartTime,omitempty"`
+
+	// ShouldBeRunning is true if we're between a start/stop event
+	// +optional
+	ShouldBeRunning *bool `json:"shouldBeRunning,omitempty"`
+
+	// IsRunning is true if there are any active events
+	// +optional
+	IsRunning *bool `json:"isRunning,omitempty"`
+
+	// IsSuspended is true if the ControlledJob is suspended, either because the user set
+	// spec.suspend, or because the controller suspended it (see AutoSuspended)
+	// +optional
+	IsSuspended *bool `json:"isSuspended,omitempty"`
+
+	// AutoSuspended is set by the controller when it suspends the ControlledJob because of repeated
+	// failures (see spec.suspendAfterFailedRunPeriods). It is never cleared by the controller: the user must
+	// clear it explicitly to resume the ControlledJob
+	// +optional
+	AutoSuspended *v1.AutoSuspendedStatus `json:"autoSuspended,omitempty"`
+
+	// FailedRunPeriods tracks failed run periods. Only maintained if spec.suspendAfterFailedRunPeriods is set
+	// +optional
+	FailedRunPeriods *v1.FailedRunPeriodsStatus `json:"failedRunPeriods,omitempty"`
+
+	// SkippedRunPeriodStartTime is the start of the most recent run period of a start-only schedule which was skipped
+	// because a Job from an earlier run period was still running and the ConcurrencyPolicy is Forbid. No Job is
+	// created in that run period, even once the earlier Job finishes
+	// +optional
+	SkippedRunPeriodStartTime *metav1.Time `json:"skippedRunPeriodStartTime,omitempty"`
+
+	// MostRecentAction is the most recent action taken by this ControlledJob
+	// +optional
+	MostRecentAction *ControlledJobActionHistoryEntry `json:"mostRecentAction,omitempty"`
+
+	// ActionHistory gives the recent history of actions taken by this ControlledJob
+	// e.g. job started, job killed etc. in reverse chronological order
+	// The number of recent actions is limited to 16
+	// +optional
+	ActionHistory []ControlledJobActionHistoryEntry `json:"actionHistory,omitempty"`
+
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// ControlledJobActionHistoryEntry records an action taken by a ControlledJob
+type ControlledJobActionHistoryEntry struct {
+	// Type is the action the ControlledJob took
+	Type string `json:"type"`
+	// Timestamp is the time the condition was last observed
+	Timestamp *metav1.Time `json:"timestamp"`
+	// Message contains human-readable message indicating details about the action
+	// +optional
+	Message string `json:"message,omitempty"`
+	// The most recent scheduled start time prior to this action. This allows grouping of
+	// actions by start time to see a 'history for today'
+	// NOW DEPRECATED AND NOT SET ANYMORE. It's kept so that history entries recorded by older versions of the
+	// operator aren't lost when they're converted between API versions
+	// +optional
+	ScheduledStartTime *metav1.Time `json:"scheduledStartTime,omitempty"`
+	// JobIndex is an incrementing number of jobs for the current run period.
+	// At a start time in the schedule, a job with index 0 will be created. If that
+	// fails and auto-restart is enabled a new job with index 1 will be created in its
+	// place. This field records the JobIndex of the affected job
+	// +optional
+	JobIndex *int `json:"jobIndex,omitempty"`
+	// JobName is the name of the job affected by this action (if any). e.g. the job
+	// that was started, or stopped
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Is running",type=boolean,JSONPath=`.status.isRunning`
+//+kubebuilder:printcolumn:name="Should be running",type=boolean,JSONPath=`.status.shouldBeRunning`
+//+kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=`.status.isSuspended`
+//+kubebuilder:printcolumn:name="Last scheduled start time",type=date,JSONPath=`.status.lastScheduledStartTime`
+//+kubebuilder:resource:shortName="ctj"
+
+// ControlledJob is the Schema for the controlledjobs API
+type ControlledJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlledJobSpec   `json:"spec,omitempty"`
+	Status ControlledJobStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ControlledJobList contains a list of ControlledJob
+type ControlledJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControlledJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControlledJob{}, &ControlledJobList{})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/G-Research/controlled-job/cli/operate"
 	"github.com/G-Research/controlled-job/cli/util"
 )
 
@@ -14,10 +15,145 @@ var app = &cli.App{
 	Name:  "ControlledJob CLI",
 	Usage: "CLI for the ControlledJob custom resource type in Kubernetes",
 	Commands: []*cli.Command{
+		{
+			Name:        "start",
+			Usage:       "start a job for a ControlledJob now",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Creates a manually scheduled Job, which the operator starts once no other Job is running, and stops at the next stop event. Does nothing if a Job is already running",
+			Flags:       append(append([]cli.Flag{}, operate.Flags...), jobAdmissionWebhookUrlFlag),
+			Action:      operate.DoStart,
+		},
+		{
+			Name:        "stop",
+			Usage:       "stop the running job of a ControlledJob",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Suspends the running Job, marking it as stopped by the user so that the operator doesn't start it again until the next run period",
+			Flags:       operate.Flags,
+			Action:      operate.DoStop,
+		},
+		{
+			Name:        "restart",
+			Usage:       "replace the running job of a ControlledJob with a new one",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Creates a new Job in the same run period with the next job run id. The operator adopts it, deletes the running Job and then starts the new one",
+			Flags:       append(append([]cli.Flag{}, operate.Flags...), jobAdmissionWebhookUrlFlag),
+			Action:      operate.DoRestart,
+		},
+		{
+			Name:        "suspend",
+			Usage:       "suspend a ControlledJob",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Sets suspend on the ControlledJob. The operator deletes its Jobs, and doesn't create any more until it's resumed",
+			Flags:       operate.Flags,
+			Action:      operate.DoSuspend,
+		},
+		{
+			Name:        "resume",
+			Usage:       "resume a suspended ControlledJob",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Clears suspend on the ControlledJob, and any automatic suspension after repeated failures",
+			Flags:       operate.Flags,
+			Action:      operate.DoResume,
+		},
+		{
+			Name:        "status",
+			Usage:       "show whether a ControlledJob is running, and whether it should be",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Summarises the status conditions of the ControlledJob, lists its Jobs by run period and job run id, and shows its upcoming run periods",
+			Flags: append(append([]cli.Flag{}, operate.ClusterFlags...),
+				operate.OutputFlag,
+				&cli.IntFlag{
+					Name:  "upcoming",
+					Usage: "How many upcoming run periods to show",
+					Value: 5,
+				},
+			),
+			Action: operate.DoStatus,
+		},
+		{
+			Name:        "history",
+			Usage:       "show the recent actions taken on a ControlledJob",
+			ArgsUsage:   "<controlled-job>",
+			Description: "Lists the action history of the ControlledJob, newest first, with the run period and job run id of the Job each action affected",
+			Flags:       append(append([]cli.Flag{}, operate.ClusterFlags...), operate.OutputFlag),
+			Action:      operate.DoHistory,
+		},
+		{
+			Name:        "explain",
+			Usage:       "show what the operator would do with a ControlledJob's jobs, and why",
+			ArgsUsage:   "[<controlled-job>]",
+			Description: "Runs the operator's decision logic against the ControlledJob and its Jobs, without changing anything, and gives a reason for keeping, creating, deleting, suspending or unsuspending each Job. They're fetched from the cluster, unless --controlled-job-file is given, in which case they're read from files in YAML or JSON, e.g. from kubectl get -o yaml",
+			Flags: append(append([]cli.Flag{}, operate.ClusterFlags...),
+				&cli.TimestampFlag{
+					Name:   "now",
+					Usage:  "Timestamp to make the decision at, in RFC3339 format, e.g. 2022-11-03T11:01:01Z. Defaults to now",
+					Layout: time.RFC3339,
+				},
+				&cli.StringFlag{
+					Name:  "controlled-job-file",
+					Usage: "Path of the ControlledJob manifest, to explain a snapshot instead of fetching it from the cluster",
+				},
+				&cli.StringFlag{
+					Name:  "jobs-file",
+					Usage: "With --controlled-job-file, the path of a list of the ControlledJob's Jobs",
+				},
+				&cli.StringFlag{
+					Name:  "calendar-file",
+					Usage: "With --controlled-job-file, the path of the Calendar or ClusterCalendar manifest the ControlledJob refers to, if any",
+				},
+				&cli.BoolFlag{
+					Name:  "enable-auto-recreate-jobs-on-spec-change",
+					Usage: "Whether the operator was started with --enable-auto-recreate-jobs-on-spec-change",
+				},
+				operate.OutputFlag,
+			),
+			Action: operate.DoExplain,
+		},
+		{
+			Name:        "simulate",
+			Usage:       "show what the operator would do with a ControlledJob over a period of time",
+			Description: "Runs the operator's reconciler against an in-memory cluster, in which Jobs start and stop instantly, and prints a timeline of the Jobs it creates, suspends, unsuspends and deletes and the changes to the ControlledJob's status conditions. Write a complete ControlledJob manifest in json to stdin. Use --fail-job-at and --delete-job-at to check how it deals with failures",
+			Flags: []cli.Flag{
+				&cli.TimestampFlag{
+					Name:   "from",
+					Usage:  "Timestamp to start the simulation at, in RFC3339 format, e.g. 2022-11-03T11:01:01Z. Defaults to now",
+					Layout: time.RFC3339,
+				},
+				&cli.TimestampFlag{
+					Name:   "to",
+					Usage:  "Timestamp to end the simulation at, in RFC3339 format. Overrides --for",
+					Layout: time.RFC3339,
+				},
+				&cli.DurationFlag{
+					Name:  "for",
+					Usage: "How long to simulate for",
+					Value: 7 * 24 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:  "calendar-file",
+					Usage: "If the ControlledJob refers to a calendar, the path of the Calendar or ClusterCalendar manifest in json",
+				},
+				&cli.StringSliceFlag{
+					Name:  "fail-job-at",
+					Usage: "Timestamp, in RFC3339 format, at which the running Job fails. Can be given more than once",
+				},
+				&cli.StringSliceFlag{
+					Name:  "delete-job-at",
+					Usage: "Timestamp, in RFC3339 format, at which a user deletes the running Job. Can be given more than once",
+				},
+				operate.OutputFlag,
+			},
+			Action: util.DoSimulate,
+		},
 		utilCommand,
 	},
 }
 
+var jobAdmissionWebhookUrlFlag = &cli.StringFlag{
+	Name:  "job-admission-webhook-url",
+	Usage: "If set, new jobs will be sent to this URL prior to creation. The remote service is expected to behave like a K8s MutatingAdmissionWebhook and return a patch to be applied",
+}
+
 var utilCommand = &cli.Command{
 	Name:        "util",
 	Usage:       "various helpers related to ControlledJobs",
@@ -47,12 +183,26 @@ var utilCommand = &cli.Command{
 					Name:  "start-suspended",
 					Usage: "Should the job be started in a suspended state?",
 				},
+				jobAdmissionWebhookUrlFlag,
+			},
+			Action: util.DoGenerateJob,
+		},
+		{
+			Name:        "analyze-schedule",
+			Usage:       "find conflicting and redundant events in the schedule of a ControlledJob",
+			Description: "Looks for start and stop events at the same instant, start events which never start a run period and stop events which never stop one. Write a complete ControlledJob manifest in json to stdin. Exits with a non-zero status if any problems are found",
+			Flags: []cli.Flag{
+				&cli.TimestampFlag{
+					Name:   "from",
+					Usage:  "Timestamp to analyze the schedule from, in RFC3339 format, e.g. 2022-11-03T11:01:01Z. Defaults to now",
+					Layout: time.RFC3339,
+				},
 				&cli.StringFlag{
-					Name:  "job-admission-webhook-url",
-					Usage: "If set, new jobs will be sent to this URL prior to creation. The remote service is expected to behave like a K8s MutatingAdmissionWebhook and return a patch to be applied",
+					Name:  "calendar-file",
+					Usage: "If the ControlledJob refers to a calendar, the path of the Calendar or ClusterCalendar manifest in json",
 				},
 			},
-			Action: util.DoGenerateJob,
+			Action: util.DoAnalyzeSchedule,
 		},
 	},
 }
@@ -65,6 +215,7 @@ var (
 func main() {
 	app.Version = fmt.Sprintf("%s-%s", Version, GitRevision)
 	if err := app.Run(os.Args); err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 }
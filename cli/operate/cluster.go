@@ -0,0 +1,134 @@
+package operate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/k8s"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+// pollInterval is how often to check whether the operator has done its part
+const pollInterval = 2 * time.Second
+
+// ClusterFlags are the flags shared by all the commands which connect to a cluster
+var ClusterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "kubeconfig",
+		Usage: "Path to the kubeconfig file. Defaults to $KUBECONFIG, then ~/.kube/config",
+	},
+	&cli.StringFlag{
+		Name:  "context",
+		Usage: "The kubeconfig context to use. Defaults to the current context",
+	},
+	&cli.StringFlag{
+		Name:    "namespace",
+		Aliases: []string{"n"},
+		Usage:   "Namespace of the ControlledJob. Defaults to the namespace of the kubeconfig context",
+	},
+}
+
+// Flags are the flags shared by all the commands which operate a ControlledJob in a cluster
+var Flags = append(append([]cli.Flag{}, ClusterFlags...),
+	&cli.BoolFlag{
+		Name:  "wait",
+		Usage: "Wait for the operator to bring the ControlledJob's Jobs into the resulting state. Use --wait=false to return straight away",
+		Value: true,
+	},
+	&cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "How long to wait for the resulting state",
+		Value: 5 * time.Minute,
+	},
+)
+
+// session is a connection to the cluster, to operate one ControlledJob
+type session struct {
+	client.Client
+	key types.NamespacedName
+}
+
+func newSession(c *cli.Context) (*session, error) {
+	name := c.Args().First()
+	if name == "" {
+		return nil, fmt.Errorf("the name of the ControlledJob is required")
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.String("kubeconfig")
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.String("context")}
+	overrides.Context.Namespace = c.String("namespace")
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to work out the namespace: %w", err)
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig: %w", err)
+	}
+	k8sClient, err := client.New(restConfig, client.Options{Scheme: k8s.GetScheme()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the cluster: %w", err)
+	}
+	return &session{
+		Client: k8sClient,
+		key:    types.NamespacedName{Namespace: namespace, Name: name},
+	}, nil
+}
+
+func (s *session) getControlledJob(ctx context.Context) (*v1.ControlledJob, error) {
+	controlledJob := &v1.ControlledJob{}
+	if err := s.Get(ctx, s.key, controlledJob); err != nil {
+		return nil, fmt.Errorf("failed to get ControlledJob %s in namespace %s: %w", s.key.Name, s.key.Namespace, err)
+	}
+	return controlledJob, nil
+}
+
+// listJobs lists the Jobs owned by the ControlledJob. The operator uses a field index to do this, which isn't
+// available outside of its cache, so this selects them by label and then checks the owner
+func (s *session) listJobs(ctx context.Context, controlledJob *v1.ControlledJob) ([]*kbatch.Job, error) {
+	var jobList kbatch.JobList
+	if err := s.List(ctx, &jobList, client.InNamespace(s.key.Namespace), client.MatchingLabels{metadata.ControlledJobLabel: s.key.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list jobs for ControlledJob %s: %w", s.key.Name, err)
+	}
+	var jobs []*kbatch.Job
+	for i := range jobList.Items {
+		if metav1.IsControlledBy(&jobList.Items[i], controlledJob) {
+			jobs = append(jobs, &jobList.Items[i])
+		}
+	}
+	return jobs, nil
+}
+
+// getJob gets the named Job. ok is false if it doesn't exist
+func (s *session) getJob(ctx context.Context, name string) (job *kbatch.Job, ok bool, err error) {
+	job = &kbatch.Job{}
+	err = s.Get(ctx, types.NamespacedName{Namespace: s.key.Namespace, Name: name}, job)
+	ok = err == nil
+	err = client.IgnoreNotFound(err)
+	return
+}
+
+// waitFor polls the condition until it's true, unless --wait=false was given. The operator carries out most
+// operations in its next reconcile, so the resulting state isn't reached straight away
+func waitFor(c *cli.Context, description string, condition wait.ConditionWithContextFunc) error {
+	if !c.Bool("wait") {
+		return nil
+	}
+	fmt.Printf("Waiting for %s...\n", description)
+	if err := wait.PollUntilContextTimeout(c.Context, pollInterval, c.Duration("timeout"), true, condition); err != nil {
+		return fmt.Errorf("gave up waiting for %s: %w", description, err)
+	}
+	return nil
+}
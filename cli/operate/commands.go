@@ -0,0 +1,265 @@
+package operate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	kbatch "k8s.io/api/batch/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/G-Research/controlled-job/pkg/actions"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/mutators"
+)
+
+// DoStart creates a manually scheduled Job, unless one is already running, and waits for the operator to start it
+func DoStart(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	if err := enableRemoteMutator(c); err != nil {
+		return err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return err
+	}
+	jobs, err := s.listJobs(c.Context, controlledJob)
+	if err != nil {
+		return err
+	}
+	if running := actions.RunningJobs(jobs); len(running) > 0 {
+		fmt.Printf("Job %s is already running\n", running[0].Name)
+		return nil
+	}
+	if actions.IsSuspended(controlledJob) {
+		return fmt.Errorf("ControlledJob %s is suspended, so no job can be started. Resume it first", controlledJob.Name)
+	}
+
+	job, err := actions.BuildStartedJob(c.Context, controlledJob, jobs, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build job: %w", err)
+	}
+	if err := s.Create(c.Context, job); err != nil {
+		return fmt.Errorf("failed to create job %s: %w", job.Name, err)
+	}
+	fmt.Printf("Created job %s\n", job.Name)
+
+	return waitFor(c, fmt.Sprintf("job %s to start", job.Name), s.jobIsRunning(job.Name))
+}
+
+// DoStop stops the running Job until the next run period, and waits for its Pods to terminate
+func DoStop(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return err
+	}
+	jobs, err := s.listJobs(c.Context, controlledJob)
+	if err != nil {
+		return err
+	}
+	running := actions.RunningJobs(jobs)
+	if len(running) == 0 {
+		fmt.Println("No job is running")
+		return nil
+	}
+
+	for _, job := range running {
+		if err := s.stopJob(c.Context, job.Name); err != nil {
+			return err
+		}
+		fmt.Printf("Stopped job %s\n", job.Name)
+	}
+
+	names := jobNames(running)
+	return waitFor(c, fmt.Sprintf("the pods of job %s to terminate", strings.Join(names, ", ")), func(ctx context.Context) (bool, error) {
+		for _, name := range names {
+			job, ok, err := s.getJob(ctx, name)
+			if err != nil {
+				return false, err
+			}
+			if ok && job.Status.Active > 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// DoRestart creates a Job to replace the running Job, and waits for the operator to swap them over
+func DoRestart(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	if err := enableRemoteMutator(c); err != nil {
+		return err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return err
+	}
+	jobs, err := s.listJobs(c.Context, controlledJob)
+	if err != nil {
+		return err
+	}
+	running := actions.RunningJobs(jobs)
+	if len(running) == 0 {
+		return fmt.Errorf("no job is running, so there is nothing to restart. Use start to start one")
+	}
+
+	job, err := actions.BuildRestartedJob(c.Context, controlledJob, running[0], jobs)
+	if err != nil {
+		return fmt.Errorf("failed to build job: %w", err)
+	}
+	if err := s.Create(c.Context, job); err != nil {
+		return fmt.Errorf("failed to create job %s: %w", job.Name, err)
+	}
+	fmt.Printf("Created job %s to replace %s\n", job.Name, running[0].Name)
+
+	oldJobs := jobNames(running)
+	return waitFor(c, fmt.Sprintf("job %s to replace %s", job.Name, strings.Join(oldJobs, ", ")), func(ctx context.Context) (bool, error) {
+		for _, name := range oldJobs {
+			if _, ok, err := s.getJob(ctx, name); err != nil || ok {
+				return false, err
+			}
+		}
+		return s.jobIsRunning(job.Name)(ctx)
+	})
+}
+
+// DoSuspend sets suspend on the ControlledJob, and waits for the operator to delete its Jobs
+func DoSuspend(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		controlledJob, err := s.getControlledJob(c.Context)
+		if err != nil {
+			return err
+		}
+		suspend := true
+		controlledJob.Spec.Suspend = &suspend
+		return s.Update(c.Context, controlledJob)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to suspend ControlledJob %s: %w", s.key.Name, err)
+	}
+	fmt.Printf("Suspended ControlledJob %s\n", s.key.Name)
+
+	return waitFor(c, fmt.Sprintf("the jobs of ControlledJob %s to be deleted", s.key.Name), func(ctx context.Context) (bool, error) {
+		controlledJob, err := s.getControlledJob(ctx)
+		if err != nil {
+			return false, err
+		}
+		jobs, err := s.listJobs(ctx, controlledJob)
+		return len(jobs) == 0, err
+	})
+}
+
+// DoResume clears suspend on the ControlledJob, as well as any suspension by the operator after repeated failures,
+// and waits for the operator to notice
+func DoResume(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		controlledJob, err := s.getControlledJob(c.Context)
+		if err != nil {
+			return err
+		}
+		if controlledJob.Spec.Suspend == nil || !*controlledJob.Spec.Suspend {
+			return nil
+		}
+		suspend := false
+		controlledJob.Spec.Suspend = &suspend
+		return s.Update(c.Context, controlledJob)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resume ControlledJob %s: %w", s.key.Name, err)
+	}
+	// An automatic suspension is recorded in the status, so that clearing it is a deliberate action. This is it
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		controlledJob, err := s.getControlledJob(c.Context)
+		if err != nil {
+			return err
+		}
+		if controlledJob.Status.AutoSuspended == nil {
+			return nil
+		}
+		fmt.Printf("Clearing the automatic suspension: %s\n", controlledJob.Status.AutoSuspended.Message)
+		controlledJob.Status.AutoSuspended = nil
+		return s.Status().Update(c.Context, controlledJob)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear the automatic suspension of ControlledJob %s: %w", s.key.Name, err)
+	}
+	fmt.Printf("Resumed ControlledJob %s\n", s.key.Name)
+
+	return waitFor(c, fmt.Sprintf("ControlledJob %s to be resumed", s.key.Name), func(ctx context.Context) (bool, error) {
+		controlledJob, err := s.getControlledJob(ctx)
+		if err != nil {
+			return false, err
+		}
+		return controlledJob.Status.IsSuspended != nil && !*controlledJob.Status.IsSuspended, nil
+	})
+}
+
+// stopJob suspends the named Job, marking it as stopped by the user so the operator doesn't unsuspend it
+func (s *session) stopJob(ctx context.Context, name string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		job, ok, err := s.getJob(ctx, name)
+		if err != nil || !ok {
+			return err
+		}
+		actions.MarkStoppedByUser(job)
+		suspend := true
+		job.Spec.Suspend = &suspend
+		return s.Update(ctx, job)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop job %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *session) jobIsRunning(name string) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		job, ok, err := s.getJob(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, fmt.Errorf("job %s was deleted", name)
+		}
+		if metadata.IsJobCompleted(job) {
+			return false, fmt.Errorf("job %s finished before it was seen running", name)
+		}
+		return metadata.IsJobRunning(job), nil
+	}
+}
+
+func enableRemoteMutator(c *cli.Context) error {
+	if url := c.String("job-admission-webhook-url"); len(url) > 0 {
+		return mutators.EnableRemoteMutator(url)
+	}
+	return nil
+}
+
+func jobNames(jobs []*kbatch.Job) []string {
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.Name
+	}
+	return names
+}
@@ -0,0 +1,146 @@
+package operate
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/urfave/cli/v2"
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	v2 "github.com/G-Research/controlled-job/api/v2"
+	"github.com/G-Research/controlled-job/cli/view"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+	"github.com/G-Research/controlled-job/pkg/reconciliation"
+)
+
+// DoExplain shows what the operator would do with a ControlledJob and its Jobs at a given time, and why. They're
+// either fetched from the cluster, or read from files so that a snapshot taken when something went wrong can be
+// explained later
+func DoExplain(c *cli.Context) error {
+	now := time.Now()
+	if c.IsSet("now") {
+		now = *c.Timestamp("now")
+	}
+
+	var controlledJob *v1.ControlledJob
+	var jobs []*kbatch.Job
+	var calendarSpec *v1.CalendarSpec
+	var err error
+	if c.IsSet("controlled-job-file") {
+		controlledJob, jobs, calendarSpec, err = readSnapshot(c)
+	} else {
+		controlledJob, jobs, calendarSpec, err = fetchSnapshot(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	jobList := &kbatch.JobList{}
+	for _, job := range jobs {
+		jobList.Items = append(jobList.Items, *job)
+	}
+	options := reconciliation.ReconcileOptions{
+		EnableAutoRecreateJobsOnSpecChange: c.Bool("enable-auto-recreate-jobs-on-spec-change"),
+	}
+	// The reconciler logs every decision it makes, which the reasons in the explanation already cover
+	ctx := log.IntoContext(c.Context, logr.Discard())
+	planned := controlledJob.DeepCopy()
+	decision, planErr := reconciliation.Plan(ctx, planned, calendarSpec, jobList, now, options)
+	return view.Render(os.Stdout, c.String("output"), view.NewExplanation(planned, jobs, decision, planErr, now), now)
+}
+
+// fetchSnapshot gets the ControlledJob, its Jobs and its calendar from the cluster
+func fetchSnapshot(c *cli.Context) (*v1.ControlledJob, []*kbatch.Job, *v1.CalendarSpec, error) {
+	s, err := newSession(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	jobs, err := s.listJobs(c.Context, controlledJob)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var calendarSpec *v1.CalendarSpec
+	if ref := controlledJob.Spec.CalendarRef; ref != nil {
+		calendarSpec, err = clientadapter.NewFromClient(s.Client).GetCalendar(c.Context, controlledJob.Namespace, *ref)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get the calendar of ControlledJob %s: %w", controlledJob.Name, err)
+		}
+	}
+	return controlledJob, jobs, calendarSpec, nil
+}
+
+// readSnapshot reads the ControlledJob, its Jobs and its calendar from the files given on the command line
+func readSnapshot(c *cli.Context) (*v1.ControlledJob, []*kbatch.Job, *v1.CalendarSpec, error) {
+	controlledJob, err := readControlledJob(c.String("controlled-job-file"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var jobs []*kbatch.Job
+	if jobsFile := c.String("jobs-file"); jobsFile != "" {
+		jobList := &kbatch.JobList{}
+		if err := readManifest(jobsFile, jobList); err != nil {
+			return nil, nil, nil, err
+		}
+		for i := range jobList.Items {
+			jobs = append(jobs, &jobList.Items[i])
+		}
+	}
+
+	var calendarSpec *v1.CalendarSpec
+	if calendarFile := c.String("calendar-file"); calendarFile != "" {
+		// Calendars and ClusterCalendars have the same spec
+		calendar := &v1.Calendar{}
+		if err := readManifest(calendarFile, calendar); err != nil {
+			return nil, nil, nil, err
+		}
+		calendarSpec = &calendar.Spec
+	} else if ref := controlledJob.Spec.CalendarRef; ref != nil {
+		return nil, nil, nil, fmt.Errorf("ControlledJob %s refers to %s %s, so --calendar-file is required", controlledJob.Name, ref.Kind, ref.Name)
+	}
+	return controlledJob, jobs, calendarSpec, nil
+}
+
+// readControlledJob reads a ControlledJob manifest in either API version. kubectl returns ControlledJobs in v2, but
+// the reconciler deals with v1
+func readControlledJob(path string) (*v1.ControlledJob, error) {
+	var typeMeta metav1.TypeMeta
+	if err := readManifest(path, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion != v2.GroupVersion.String() {
+		controlledJob := &v1.ControlledJob{}
+		return controlledJob, readManifest(path, controlledJob)
+	}
+	stored := &v2.ControlledJob{}
+	if err := readManifest(path, stored); err != nil {
+		return nil, err
+	}
+	controlledJob := &v1.ControlledJob{}
+	if err := stored.ConvertTo(controlledJob); err != nil {
+		return nil, fmt.Errorf("failed to convert %s to %s: %w", path, v1.GroupVersion, err)
+	}
+	return controlledJob, nil
+}
+
+// readManifest reads a manifest in either YAML or JSON
+func readManifest(path string, into interface{}) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(contents, into); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
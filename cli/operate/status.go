@@ -0,0 +1,62 @@
+package operate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/cli/view"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+)
+
+// OutputFlag chooses the format of the status, history, explain and simulate commands
+var OutputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   fmt.Sprintf("Output format. One of: %s", strings.Join(view.Formats, ", ")),
+	Value:   "table",
+}
+
+// DoStatus shows whether the ControlledJob is running and whether it should be, its Jobs, and its upcoming run
+// periods
+func DoStatus(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return err
+	}
+	jobs, err := s.listJobs(c.Context, controlledJob)
+	if err != nil {
+		return err
+	}
+	var calendarSpec *v1.CalendarSpec
+	if ref := controlledJob.Spec.CalendarRef; ref != nil {
+		calendarSpec, err = clientadapter.NewFromClient(s.Client).GetCalendar(c.Context, controlledJob.Namespace, *ref)
+		if err != nil {
+			return fmt.Errorf("failed to get the calendar of ControlledJob %s: %w", controlledJob.Name, err)
+		}
+	}
+	now := time.Now()
+	status := view.NewStatus(controlledJob, jobs, calendarSpec, now, c.Int("upcoming"))
+	return view.Render(os.Stdout, c.String("output"), status, now)
+}
+
+// DoHistory shows the actions the operator has recently taken on the ControlledJob
+func DoHistory(c *cli.Context) error {
+	s, err := newSession(c)
+	if err != nil {
+		return err
+	}
+	controlledJob, err := s.getControlledJob(c.Context)
+	if err != nil {
+		return err
+	}
+	return view.Render(os.Stdout, c.String("output"), view.NewHistory(controlledJob), time.Now())
+}
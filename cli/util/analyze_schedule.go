@@ -0,0 +1,55 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/schedule"
+	"github.com/urfave/cli/v2"
+)
+
+func DoAnalyzeSchedule(c *cli.Context) error {
+	from := time.Now()
+	if c.IsSet("from") {
+		from = *c.Timestamp("from")
+	}
+
+	stdin, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	controlledJob := &v1.ControlledJob{}
+	if err := json.Unmarshal(stdin, controlledJob); err != nil {
+		return err
+	}
+
+	var calendarSpec *v1.CalendarSpec
+	if calendarFile := c.String("calendar-file"); calendarFile != "" {
+		contents, err := os.ReadFile(calendarFile)
+		if err != nil {
+			return err
+		}
+		// Calendars and ClusterCalendars have the same spec
+		calendar := &v1.Calendar{}
+		if err := json.Unmarshal(contents, calendar); err != nil {
+			return err
+		}
+		calendarSpec = &calendar.Spec
+	}
+
+	warnings, err := schedule.AnalyzeSchedule(controlledJob, calendarSpec, from)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Printf("%s: %s\n", warning.Type, warning.Message)
+	}
+	if len(warnings) > 0 {
+		return cli.Exit(fmt.Sprintf("found %d problems with the schedule", len(warnings)), 1)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/cli/view"
+	"github.com/G-Research/controlled-job/pkg/simulation"
+)
+
+// DoSimulate runs the reconciler against the ControlledJob on stdin over a period of time, without a cluster, and
+// prints a timeline of what it did
+func DoSimulate(c *cli.Context) error {
+	from := time.Now()
+	if c.IsSet("from") {
+		from = *c.Timestamp("from")
+	}
+	to := from.Add(c.Duration("for"))
+	if c.IsSet("to") {
+		to = *c.Timestamp("to")
+	}
+
+	stdin, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	controlledJob := &v1.ControlledJob{}
+	if err := json.Unmarshal(stdin, controlledJob); err != nil {
+		return err
+	}
+
+	var calendarSpec *v1.CalendarSpec
+	if calendarFile := c.String("calendar-file"); calendarFile != "" {
+		contents, err := os.ReadFile(calendarFile)
+		if err != nil {
+			return err
+		}
+		// Calendars and ClusterCalendars have the same spec
+		calendar := &v1.Calendar{}
+		if err := json.Unmarshal(contents, calendar); err != nil {
+			return err
+		}
+		calendarSpec = &calendar.Spec
+	}
+
+	var failures []simulation.Failure
+	for _, injected := range []struct {
+		flag        string
+		failureType simulation.FailureType
+	}{
+		{"fail-job-at", simulation.JobFails},
+		{"delete-job-at", simulation.JobDeletedByUser},
+	} {
+		for _, value := range c.StringSlice(injected.flag) {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", injected.flag, err)
+			}
+			failures = append(failures, simulation.Failure{At: at, Type: injected.failureType})
+		}
+	}
+
+	options := simulation.Options{
+		From:     from,
+		To:       to,
+		Calendar: calendarSpec,
+		Failures: failures,
+	}
+	// The reconciler logs every decision it makes, which would drown out the timeline
+	ctx := log.IntoContext(c.Context, logr.Discard())
+	result, err := simulation.Run(ctx, controlledJob, options)
+	if err != nil {
+		return err
+	}
+	return view.Render(os.Stdout, c.String("output"), view.NewTimeline(controlledJob, options, result), to)
+}
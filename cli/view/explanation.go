@@ -0,0 +1,128 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/reconciliation"
+)
+
+// Explanation is what the operator would do with a ControlledJob and its Jobs at a given time, and why
+type Explanation struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Now       metav1.Time `json:"now"`
+	// ShouldBeRunning says whether the schedule says the ControlledJob should be running now
+	ShouldBeRunning *Condition `json:"shouldBeRunning,omitempty"`
+	// Error is set if the reconciler would fail, in which case it would make no changes
+	Error string `json:"error,omitempty"`
+	// AutoSuspendMessage is set if the ControlledJob would be suspended because of repeated failures
+	AutoSuspendMessage string        `json:"autoSuspendMessage,omitempty"`
+	Jobs               []JobDecision `json:"jobs"`
+	// RequeueAt is when the operator would next reconcile the ControlledJob, if nothing changes before then
+	RequeueAt *metav1.Time `json:"requeueAt,omitempty"`
+	location  *time.Location
+}
+
+// JobDecision is what the operator would do with one of the Jobs, including any it would create
+type JobDecision struct {
+	Name string `json:"name"`
+	// State is the state of an existing Job, as in Status, or New for a Job which would be created
+	State string `json:"state"`
+	// Action is Create, Delete, Suspend, Unsuspend or Keep
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// NewExplanation builds the view of what reconciliation.Plan decided for the ControlledJob and its Jobs. The
+// ControlledJob is the one Plan was given, so has the status the reconciler would set
+func NewExplanation(controlledJob *batch.ControlledJob, jobs []*kbatch.Job, decision reconciliation.Decision, planErr error, now time.Time) *Explanation {
+	result := &Explanation{
+		Name:               controlledJob.Name,
+		Namespace:          controlledJob.Namespace,
+		Now:                metav1.NewTime(now),
+		AutoSuspendMessage: decision.AutoSuspendMessage,
+		Jobs:               []JobDecision{},
+		location:           locationOf(controlledJob),
+	}
+	if condition := batch.FindCondition(controlledJob.Status, batch.ConditionTypeShouldBeRunning); condition != nil {
+		shouldBeRunning := conditionFrom(*condition)
+		// The transition time is when the plan was made, not when it would be carried out, so would only mislead
+		shouldBeRunning.LastTransitionTime = metav1.Time{}
+		result.ShouldBeRunning = &shouldBeRunning
+	}
+	if planErr != nil {
+		result.Error = planErr.Error()
+	}
+	if !decision.RequeueAt.IsZero() {
+		requeueAt := metav1.NewTime(decision.RequeueAt)
+		result.RequeueAt = &requeueAt
+	}
+
+	actions := map[string]string{}
+	for action, jobsForAction := range map[string][]*kbatch.Job{
+		"Delete":    decision.JobsToDelete,
+		"Suspend":   decision.JobsToSuspend,
+		"Unsuspend": decision.JobsToUnsuspend,
+	} {
+		for _, job := range jobsForAction {
+			actions[job.Name] = action
+		}
+	}
+
+	sorted := append([]*kbatch.Job{}, jobs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, job := range sorted {
+		action, ok := actions[job.Name]
+		if !ok {
+			action = "Keep"
+		}
+		result.Jobs = append(result.Jobs, JobDecision{Name: job.Name, State: jobState(job), Action: action, Reason: decision.Reasons[job.Name]})
+	}
+	for _, job := range decision.JobsToCreate {
+		result.Jobs = append(result.Jobs, JobDecision{Name: job.Name, State: "New", Action: "Create", Reason: decision.Reasons[job.Name]})
+	}
+	return result
+}
+
+func (e *Explanation) writeTables(w io.Writer, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", e.Name)
+	fmt.Fprintf(tw, "Namespace:\t%s\n", e.Namespace)
+	fmt.Fprintf(tw, "At:\t%s\n", formatTime(e.Now, e.location))
+	if e.ShouldBeRunning != nil {
+		fmt.Fprintf(tw, "Should be running:\t%s\n", joinNonEmpty(" - ", e.ShouldBeRunning.Status, e.ShouldBeRunning.Message))
+	}
+	if e.AutoSuspendMessage != "" {
+		fmt.Fprintf(tw, "Auto-suspending:\t%s\n", e.AutoSuspendMessage)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(tw, "Error:\t%s\n", e.Error)
+	}
+	if e.RequeueAt != nil {
+		fmt.Fprintf(tw, "Next reconcile:\t%s\n", formatTime(*e.RequeueAt, e.location))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Jobs:")
+	if len(e.Jobs) == 0 {
+		fmt.Fprintln(w, "  <none>")
+		return
+	}
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tSTATE\tACTION\tREASON")
+	for _, job := range e.Jobs {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", job.Name, job.State, job.Action, job.Reason)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,168 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+// Formats are the output formats Render supports
+var Formats = []string{"table", "json", "yaml"}
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// tabular is a view which can be rendered as tables for people to read
+type tabular interface {
+	writeTables(w io.Writer, now time.Time)
+}
+
+// Render writes the view in the given format. The table format shows times in the ControlledJob's timezone,
+// relative to now
+func Render(w io.Writer, format string, view tabular, now time.Time) error {
+	switch format {
+	case "table":
+		view.writeTables(w, now)
+		return nil
+	case "json":
+		contents, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(contents))
+		return err
+	case "yaml":
+		contents, err := yaml.Marshal(view)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(contents)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func (s *Status) writeTables(w io.Writer, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", s.Name)
+	fmt.Fprintf(tw, "Namespace:\t%s\n", s.Namespace)
+	fmt.Fprintf(tw, "Summary:\t%s\n", joinNonEmpty(" - ", s.Summary, s.SummaryMessage))
+	suspended := fmt.Sprint(s.Suspended)
+	if s.AutoSuspended != nil {
+		suspended = fmt.Sprintf("%s (by the controller at %s: %s)", suspended, formatTime(s.AutoSuspended.SuspendedAt, s.location), s.AutoSuspended.Message)
+	}
+	fmt.Fprintf(tw, "Suspended:\t%s\n", suspended)
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Conditions:")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TYPE\tSTATUS\tREASON\tSINCE\tMESSAGE")
+	for _, condition := range s.Conditions {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, since(condition.LastTransitionTime, now), condition.Message)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Jobs:")
+	if len(s.RunPeriods) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  RUN PERIOD\tRUN ID\tNAME\tSTATE\tMANUAL\tAGE")
+		for _, runPeriod := range s.RunPeriods {
+			for i, job := range runPeriod.Jobs {
+				scheduledAt := ""
+				if i == 0 {
+					scheduledAt = formatTime(runPeriod.ScheduledAt, s.location)
+				}
+				fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%t\t%s\n", scheduledAt, job.JobRunId, job.Name, job.State, job.ManuallyScheduled, since(job.CreationTimestamp, now))
+			}
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Upcoming:")
+	if len(s.Upcoming) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  START\tSTART EVENT\tSTOP\tSTOP EVENT")
+		for _, window := range s.Upcoming {
+			start := formatTime(window.Start, s.location)
+			if window.Start.After(now) {
+				start = fmt.Sprintf("%s (in %s)", start, duration.HumanDuration(window.Start.Sub(now)))
+			}
+			stop := "<never>"
+			if window.Stop != nil {
+				stop = fmt.Sprintf("%s (in %s)", formatTime(*window.Stop, s.location), duration.HumanDuration(window.Stop.Sub(now)))
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", start, window.StartEvent, stop, window.StopEvent)
+		}
+		tw.Flush()
+	}
+
+	if len(s.Warnings) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Warnings:")
+		for _, warning := range s.Warnings {
+			fmt.Fprintf(w, "  %s\n", warning)
+		}
+	}
+}
+
+func (h *History) writeTables(w io.Writer, now time.Time) {
+	if len(h.Actions) == 0 {
+		fmt.Fprintf(w, "No actions recorded for ControlledJob %s\n", h.Name)
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tAGE\tTYPE\tRUN PERIOD\tRUN ID\tMESSAGE")
+	for _, action := range h.Actions {
+		runPeriod, jobRunId := "", ""
+		if action.RunPeriod != nil {
+			runPeriod = formatTime(*action.RunPeriod, h.location)
+		}
+		if action.JobRunId != nil {
+			jobRunId = fmt.Sprint(*action.JobRunId)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", formatTime(action.Timestamp, h.location), since(action.Timestamp, now), action.Type, runPeriod, jobRunId, action.Message)
+	}
+	tw.Flush()
+}
+
+func formatTime(t metav1.Time, location *time.Location) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return t.In(location).Format(timeLayout)
+}
+
+// since is how long ago t was, like the AGE column kubectl shows
+func since(t metav1.Time, now time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t.Time))
+}
+
+func joinNonEmpty(separator string, values ...string) string {
+	var nonEmpty []string
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return strings.Join(nonEmpty, separator)
+}
@@ -0,0 +1,50 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/simulation"
+)
+
+// Timeline is what happened in a simulation of a ControlledJob
+type Timeline struct {
+	Name       string             `json:"name"`
+	From       metav1.Time        `json:"from"`
+	To         metav1.Time        `json:"to"`
+	Reconciles int                `json:"reconciles"`
+	Entries    []simulation.Entry `json:"entries"`
+	location   *time.Location
+}
+
+// NewTimeline builds the view of a simulation of the ControlledJob
+func NewTimeline(controlledJob *batch.ControlledJob, options simulation.Options, result *simulation.Result) *Timeline {
+	return &Timeline{
+		Name:       controlledJob.Name,
+		From:       metav1.NewTime(options.From),
+		To:         metav1.NewTime(options.To),
+		Reconciles: result.Reconciles,
+		Entries:    result.Timeline,
+		location:   locationOf(controlledJob),
+	}
+}
+
+func (t *Timeline) writeTables(w io.Writer, now time.Time) {
+	fmt.Fprintf(w, "Simulated ControlledJob %s from %s to %s (%d reconciles)\n\n", t.Name,
+		formatTime(t.From, t.location), formatTime(t.To, t.location), t.Reconciles)
+	if len(t.Entries) == 0 {
+		fmt.Fprintln(w, "Nothing happened")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTYPE\tJOB\tMESSAGE")
+	for _, entry := range t.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", formatTime(metav1.NewTime(entry.Time), t.location), entry.Type, entry.JobName, entry.Message)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,297 @@
+// Package view turns a ControlledJob, its Jobs and its schedule into summaries for on-call engineers, which can be
+// rendered as a table, JSON or YAML
+package view
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/schedule"
+)
+
+// headlineConditions between them say whether the ControlledJob is running, and whether it should be. Exactly one is
+// True once the controller has reconciled the ControlledJob
+var headlineConditions = []batch.ControlledJobConditionType{
+	batch.ConditionTypeRunningExpectedly,
+	batch.ConditionTypeRunningUnexpectedly,
+	batch.ConditionTypeNotRunningExpectedly,
+	batch.ConditionTypeNotRunningUnexpectedly,
+}
+
+// Status is the current state of a ControlledJob
+type Status struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Summary is the headline condition which is True, e.g. NotRunningUnexpectedly, or Unknown if none is
+	Summary string `json:"summary"`
+	// SummaryMessage is the message of that condition
+	SummaryMessage string                     `json:"summaryMessage,omitempty"`
+	Suspended      bool                       `json:"suspended"`
+	AutoSuspended  *batch.AutoSuspendedStatus `json:"autoSuspended,omitempty"`
+	// Conditions are the headline conditions, followed by any other conditions which are True
+	Conditions []Condition `json:"conditions"`
+	RunPeriods []RunPeriod `json:"runPeriods"`
+	Upcoming   []RunWindow `json:"upcoming"`
+	Warnings   []string    `json:"warnings,omitempty"`
+	Now        metav1.Time `json:"now"`
+	location   *time.Location
+}
+
+// Condition is one of the ControlledJob's status conditions
+type Condition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// RunPeriod is the Jobs created for one run period, identified by their scheduled-at time, in order of job run id
+type RunPeriod struct {
+	ScheduledAt metav1.Time `json:"scheduledAt"`
+	Jobs        []Job       `json:"jobs"`
+}
+
+// Job is one of the ControlledJob's Jobs
+type Job struct {
+	Name     string `json:"name"`
+	JobRunId int    `json:"jobRunId"`
+	// State is Running, Pending, Suspended, StoppedByUser, Succeeded, Failed or Deleting
+	State             string      `json:"state"`
+	ManuallyScheduled bool        `json:"manuallyScheduled,omitempty"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+// RunWindow is an upcoming period when the ControlledJob should be running
+type RunWindow struct {
+	Start      metav1.Time  `json:"start"`
+	StartEvent string       `json:"startEvent"`
+	Stop       *metav1.Time `json:"stop,omitempty"`
+	StopEvent  string       `json:"stopEvent,omitempty"`
+}
+
+// History is the recent actions the controller took on a ControlledJob
+type History struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Actions are newest first
+	Actions  []Action `json:"actions"`
+	location *time.Location
+}
+
+// Action is an entry in the ControlledJob's action history
+type Action struct {
+	Timestamp metav1.Time `json:"timestamp"`
+	Type      string      `json:"type"`
+	JobName   string      `json:"jobName,omitempty"`
+	// RunPeriod and JobRunId identify the Job the action affected, if any
+	RunPeriod *metav1.Time `json:"runPeriod,omitempty"`
+	JobRunId  *int         `json:"jobRunId,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// NewStatus summarises the ControlledJob, its Jobs, and up to upcoming run windows of its schedule after now.
+// calendarSpec is the spec of the Calendar or ClusterCalendar it refers to, if any. A schedule which can't be worked
+// out is reported as a warning rather than an error, so that the rest of the status can still be shown
+func NewStatus(controlledJob *batch.ControlledJob, jobs []*kbatch.Job, calendarSpec *batch.CalendarSpec, now time.Time, upcoming int) *Status {
+	result := &Status{
+		Name:       controlledJob.Name,
+		Namespace:  controlledJob.Namespace,
+		Summary:    string(metav1.ConditionUnknown),
+		Suspended:  controlledJob.Status.IsSuspended != nil && *controlledJob.Status.IsSuspended,
+		Conditions: []Condition{},
+		RunPeriods: groupByRunPeriod(jobs),
+		Upcoming:   []RunWindow{},
+		Now:        metav1.NewTime(now),
+		location:   locationOf(controlledJob),
+	}
+	if controlledJob.Status.AutoSuspended != nil {
+		result.AutoSuspended = controlledJob.Status.AutoSuspended.DeepCopy()
+	}
+
+	for _, conditionType := range headlineConditions {
+		condition := batch.FindCondition(controlledJob.Status, conditionType)
+		if condition == nil {
+			result.Conditions = append(result.Conditions, Condition{Type: string(conditionType), Status: string(metav1.ConditionUnknown)})
+			continue
+		}
+		result.Conditions = append(result.Conditions, conditionFrom(*condition))
+		if condition.Status == metav1.ConditionTrue {
+			result.Summary = condition.Type
+			result.SummaryMessage = condition.Message
+		}
+	}
+	for _, condition := range controlledJob.Status.Conditions {
+		if condition.Status == metav1.ConditionTrue && !isHeadline(condition.Type) {
+			result.Conditions = append(result.Conditions, conditionFrom(condition))
+		}
+	}
+
+	compiled, err := schedule.Compile(controlledJob, calendarSpec)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to work out the schedule: %v", err))
+		return result
+	}
+	windows := compiled.WindowsFrom(now)
+	for len(result.Upcoming) < upcoming {
+		window, err := windows.Next()
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to work out the schedule: %v", err))
+			break
+		}
+		if window == nil {
+			break
+		}
+		result.Upcoming = append(result.Upcoming, runWindowFrom(controlledJob, window))
+	}
+	return result
+}
+
+// NewHistory lists the ControlledJob's action history
+func NewHistory(controlledJob *batch.ControlledJob) *History {
+	result := &History{
+		Name:      controlledJob.Name,
+		Namespace: controlledJob.Namespace,
+		Actions:   []Action{},
+		location:  locationOf(controlledJob),
+	}
+	for _, entry := range controlledJob.Status.ActionHistory {
+		action := Action{Type: entry.Type, JobName: entry.JobName, Message: entry.Message, JobRunId: entry.JobIndex}
+		if entry.Timestamp != nil {
+			action.Timestamp = *entry.Timestamp
+		}
+		if entry.JobName != "" {
+			if _, scheduledTime, jobRunId, err := metadata.ParseJobName(entry.JobName); err == nil {
+				runPeriod := metav1.NewTime(*scheduledTime)
+				action.RunPeriod = &runPeriod
+				if action.JobRunId == nil {
+					action.JobRunId = jobRunId
+				}
+			}
+		}
+		result.Actions = append(result.Actions, action)
+	}
+	// The history is kept newest first, but make sure of it
+	sort.SliceStable(result.Actions, func(i, j int) bool {
+		return result.Actions[i].Timestamp.After(result.Actions[j].Timestamp.Time)
+	})
+	return result
+}
+
+func isHeadline(conditionType string) bool {
+	for _, headline := range headlineConditions {
+		if string(headline) == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+func conditionFrom(condition metav1.Condition) Condition {
+	return Condition{
+		Type:               condition.Type,
+		Status:             string(condition.Status),
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+		LastTransitionTime: condition.LastTransitionTime,
+	}
+}
+
+// groupByRunPeriod groups the Jobs by their scheduled-at time, newest run period first, and orders each run period's
+// Jobs by job run id
+func groupByRunPeriod(jobs []*kbatch.Job) []RunPeriod {
+	byScheduledTime := map[time.Time][]Job{}
+	for _, job := range jobs {
+		scheduledTime, err := metadata.GetScheduledTime(job)
+		if err != nil {
+			// Not a Job the controller recognises, so it doesn't belong to any run period
+			continue
+		}
+		scheduledTime = scheduledTime.UTC()
+		jobRunId, _ := metadata.GetJobRunId(job)
+		byScheduledTime[scheduledTime] = append(byScheduledTime[scheduledTime], Job{
+			Name:              job.Name,
+			JobRunId:          jobRunId,
+			State:             jobState(job),
+			ManuallyScheduled: metadata.IsManuallyScheduledJob(job),
+			CreationTimestamp: job.CreationTimestamp,
+		})
+	}
+
+	result := []RunPeriod{}
+	for scheduledTime, periodJobs := range byScheduledTime {
+		sort.SliceStable(periodJobs, func(i, j int) bool {
+			return periodJobs[i].JobRunId < periodJobs[j].JobRunId
+		})
+		result = append(result, RunPeriod{ScheduledAt: metav1.NewTime(scheduledTime), Jobs: periodJobs})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledAt.After(result[j].ScheduledAt.Time)
+	})
+	return result
+}
+
+func jobState(job *kbatch.Job) string {
+	switch {
+	case metadata.IsJobBeingDeleted(job):
+		return "Deleting"
+	case metadata.JobHasCondition(job, kbatch.JobComplete):
+		return "Succeeded"
+	case metadata.JobHasCondition(job, kbatch.JobFailed):
+		return "Failed"
+	case metadata.WasJobStoppedByTheUser(job):
+		return "StoppedByUser"
+	case metadata.IsJobSuspended(job):
+		return "Suspended"
+	case metadata.IsJobRunning(job):
+		return "Running"
+	default:
+		return "Pending"
+	}
+}
+
+func runWindowFrom(controlledJob *batch.ControlledJob, window *schedule.RunWindow) RunWindow {
+	result := RunWindow{
+		Start:      metav1.NewTime(window.Start.Time),
+		StartEvent: describeBoundary(controlledJob, window.Start),
+	}
+	if window.Stop != nil {
+		stop := metav1.NewTime(window.Stop.Time)
+		result.Stop = &stop
+		result.StopEvent = describeBoundary(controlledJob, *window.Stop)
+	}
+	return result
+}
+
+// describeBoundary says which event started or stopped a run window, e.g. events[1] (close)
+func describeBoundary(controlledJob *batch.ControlledJob, boundary schedule.Boundary) string {
+	if boundary.Source == schedule.EventSourceEarlyClose {
+		return "early close"
+	}
+	description := fmt.Sprintf("events[%d]", boundary.EventIndex)
+	if boundary.EventIndex >= 0 && boundary.EventIndex < len(controlledJob.Spec.Events) {
+		if name := controlledJob.Spec.Events[boundary.EventIndex].Name; name != "" {
+			description = fmt.Sprintf("%s (%s)", description, name)
+		}
+	}
+	if boundary.Source == schedule.EventSourceRunFor {
+		return fmt.Sprintf("runFor of %s", description)
+	}
+	return description
+}
+
+// locationOf returns the location to show times in: the ControlledJob's timezone, so they can be compared with its
+// schedule, or UTC if it can't be loaded. Any offset from the timezone is left out, as it isn't a wall clock anyone reads
+func locationOf(controlledJob *batch.ControlledJob) *time.Location {
+	location, err := time.LoadLocation(controlledJob.Spec.Timezone.Name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
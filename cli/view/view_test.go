@@ -0,0 +1,194 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/reconciliation"
+	"github.com/G-Research/controlled-job/pkg/simulation"
+	"github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+var (
+	// 2024-06-03 is a Monday
+	today     = time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	yesterday = time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC)
+	now       = time.Date(2024, 6, 3, 11, 30, 0, 0, time.UTC)
+)
+
+func newControlledJob() *batch.ControlledJob {
+	controlledJob := testhelpers.NewControlledJob("my-controlled-job",
+		testhelpers.WithTimezone("UTC", 0),
+		testhelpers.WithCronEvent(batch.EventTypeStart, "0 9 * * MON-FRI"),
+		testhelpers.WithCronEvent(batch.EventTypeStop, "0 17 * * MON-FRI"),
+	)
+	controlledJob.Spec.Events[1].Name = "close"
+	controlledJob.Status.Conditions = []metav1.Condition{
+		{Type: string(batch.ConditionTypeShouldBeRunning), Status: metav1.ConditionTrue, Reason: "InsideRunPeriod"},
+		{Type: string(batch.ConditionTypeNotRunningUnexpectedly), Status: metav1.ConditionTrue, Reason: "JobSuspended", Message: "The job is suspended",
+			LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute))},
+		{Type: string(batch.ConditionTypeRunningExpectedly), Status: metav1.ConditionFalse, Reason: "NotRunning"},
+		{Type: string(batch.ConditionTypeError), Status: metav1.ConditionFalse, Reason: "NoError"},
+	}
+	return controlledJob
+}
+
+func newJob(name string, scheduledTime time.Time, jobRunId int, opts ...testhelpers.JobOption) *kbatch.Job {
+	opts = append([]testhelpers.JobOption{metadata.WithScheduledTimeAnnotation(scheduledTime), metadata.WithJobRunIdx(jobRunId)}, opts...)
+	return testhelpers.NewJob(name, opts...)
+}
+
+func Test_NewStatus(t *testing.T) {
+	jobs := []*kbatch.Job{
+		newJob("yesterdays", yesterday, 0, testhelpers.HasSucceeded()),
+		newJob("replacement", today, 1, testhelpers.IsSuspended(true)),
+		newJob("original", today, 0, testhelpers.WithActiveCount(1)),
+		testhelpers.NewJob("unrelated"),
+	}
+
+	sut := NewStatus(newControlledJob(), jobs, nil, now, 2)
+
+	assert.Equal(t, "NotRunningUnexpectedly", sut.Summary)
+	assert.Equal(t, "The job is suspended", sut.SummaryMessage)
+
+	var conditionTypes []string
+	for _, condition := range sut.Conditions {
+		conditionTypes = append(conditionTypes, condition.Type)
+	}
+	assert.Equal(t, []string{"RunningExpectedly", "RunningUnexpectedly", "NotRunningExpectedly", "NotRunningUnexpectedly", "ShouldBeRunning"}, conditionTypes,
+		"should show the headline conditions first, then any others which are True")
+	assert.Equal(t, "Unknown", sut.Conditions[1].Status, "should show missing headline conditions as Unknown")
+
+	if assert.Len(t, sut.RunPeriods, 2, "should leave out jobs which aren't in a run period") {
+		assert.Equal(t, today, sut.RunPeriods[0].ScheduledAt.Time, "should put the latest run period first")
+		assert.Equal(t, []Job{
+			{Name: "original", JobRunId: 0, State: "Running"},
+			{Name: "replacement", JobRunId: 1, State: "Suspended"},
+		}, sut.RunPeriods[0].Jobs)
+		assert.Equal(t, "Succeeded", sut.RunPeriods[1].Jobs[0].State)
+	}
+
+	if assert.Len(t, sut.Upcoming, 2) {
+		stop := metav1.NewTime(time.Date(2024, 6, 3, 17, 0, 0, 0, time.UTC))
+		assert.Equal(t, RunWindow{Start: metav1.NewTime(today), StartEvent: "events[0]", Stop: &stop, StopEvent: "events[1] (close)"}, sut.Upcoming[0],
+			"should start with the current run period")
+		assert.Equal(t, time.Date(2024, 6, 4, 9, 0, 0, 0, time.UTC), sut.Upcoming[1].Start.UTC())
+	}
+	assert.Empty(t, sut.Warnings)
+}
+
+func Test_NewStatus_InvalidSchedule(t *testing.T) {
+	controlledJob := newControlledJob()
+	controlledJob.Spec.Timezone.Name = "Not/AZone"
+
+	sut := NewStatus(controlledJob, nil, nil, now, 2)
+
+	assert.Len(t, sut.Warnings, 1, "should report the schedule problem, rather than failing")
+	assert.Equal(t, "NotRunningUnexpectedly", sut.Summary)
+}
+
+func Test_NewHistory(t *testing.T) {
+	controlledJob := newControlledJob()
+	older := metav1.NewTime(now.Add(-time.Hour))
+	newer := metav1.NewTime(now.Add(-time.Minute))
+	controlledJob.Status.ActionHistory = []batch.ControlledJobActionHistoryEntry{
+		{Type: "JobStarted", Timestamp: &older, JobName: metadata.JobName("my-controlled-job", today, 0), Message: "Started job"},
+		{Type: "AutoSuspended", Timestamp: &newer, Message: "Failed repeatedly"},
+	}
+
+	sut := NewHistory(controlledJob)
+
+	if assert.Len(t, sut.Actions, 2) {
+		assert.Equal(t, "AutoSuspended", sut.Actions[0].Type, "should put the newest first")
+		assert.Nil(t, sut.Actions[0].RunPeriod)
+		if assert.NotNil(t, sut.Actions[1].RunPeriod) && assert.NotNil(t, sut.Actions[1].JobRunId) {
+			assert.Equal(t, today, sut.Actions[1].RunPeriod.UTC(), "should work out the run period from the job name")
+			assert.Equal(t, 0, *sut.Actions[1].JobRunId)
+		}
+	}
+}
+
+func Test_Render(t *testing.T) {
+	status := NewStatus(newControlledJob(), []*kbatch.Job{newJob("original", today, 0)}, nil, now, 1)
+
+	var table bytes.Buffer
+	assert.Nil(t, Render(&table, "table", status, now))
+	assert.Regexp(t, `Summary:\s+NotRunningUnexpectedly - The job is suspended`, table.String())
+	assert.Regexp(t, `NotRunningUnexpectedly\s+True\s+JobSuspended\s+10m\s+The job is suspended`, table.String())
+	assert.Regexp(t, `2024-06-03 09:00:00 UTC\s+0\s+original\s+Pending`, table.String())
+	assert.Regexp(t, `2024-06-03 17:00:00 UTC \(in 5h30m\)\s+events\[1\] \(close\)`, table.String())
+
+	var jsonOutput bytes.Buffer
+	assert.Nil(t, Render(&jsonOutput, "json", status, now))
+	var fromJson Status
+	assert.Nil(t, json.Unmarshal(jsonOutput.Bytes(), &fromJson))
+	assert.Equal(t, "NotRunningUnexpectedly", fromJson.Summary)
+
+	var yamlOutput bytes.Buffer
+	assert.Nil(t, Render(&yamlOutput, "yaml", status, now))
+	var fromYaml Status
+	assert.Nil(t, yaml.Unmarshal(yamlOutput.Bytes(), &fromYaml))
+	assert.Equal(t, "original", fromYaml.RunPeriods[0].Jobs[0].Name)
+
+	assert.NotNil(t, Render(&table, "xml", status, now))
+}
+
+func Test_Timeline(t *testing.T) {
+	controlledJob := newControlledJob()
+	controlledJob.Spec.Timezone.Name = "Europe/London"
+	options := simulation.Options{From: today, To: today.Add(time.Hour)}
+	result := &simulation.Result{
+		Reconciles: 2,
+		Timeline: []simulation.Entry{
+			{Time: today, Type: simulation.EntryJobCreated, JobName: "original", Message: "Created job"},
+		},
+	}
+
+	var table bytes.Buffer
+	assert.Nil(t, Render(&table, "table", NewTimeline(controlledJob, options, result), now))
+	assert.Contains(t, table.String(), "(2 reconciles)")
+	assert.Regexp(t, `2024-06-03 10:00:00 BST\s+JobCreated\s+original\s+Created job`, table.String(),
+		"should show times in the ControlledJob's timezone")
+}
+
+func Test_NewExplanation(t *testing.T) {
+	expired := newJob("yesterdays", yesterday, 0)
+	current := newJob("original", today, 0, testhelpers.WithActiveCount(1))
+	decision := reconciliation.Decision{
+		JobsToCreate: []*kbatch.Job{newJob("replacement", today, 1)},
+		JobsToDelete: []*kbatch.Job{expired, current},
+		RequeueAt:    today.Add(8 * time.Hour),
+		Reasons: map[string]string{
+			"yesterdays":  "it has expired",
+			"original":    "it's out of date, so it's replaced by replacement with the latest spec",
+			"replacement": "it recreates original with the latest spec",
+		},
+	}
+	unchanged := newJob("unchanged", today, 0, testhelpers.HasSucceeded())
+
+	sut := NewExplanation(newControlledJob(), []*kbatch.Job{expired, unchanged, current}, decision, nil, now)
+
+	assert.Equal(t, []JobDecision{
+		{Name: "original", State: "Running", Action: "Delete", Reason: "it's out of date, so it's replaced by replacement with the latest spec"},
+		{Name: "unchanged", State: "Succeeded", Action: "Keep"},
+		{Name: "yesterdays", State: "Pending", Action: "Delete", Reason: "it has expired"},
+		{Name: "replacement", State: "New", Action: "Create", Reason: "it recreates original with the latest spec"},
+	}, sut.Jobs, "should list the existing jobs by name, then the new ones")
+	if assert.NotNil(t, sut.ShouldBeRunning) {
+		assert.Equal(t, "True", sut.ShouldBeRunning.Status)
+	}
+
+	var table bytes.Buffer
+	assert.Nil(t, Render(&table, "table", sut, now))
+	assert.Regexp(t, `Next reconcile:\s+2024-06-03 17:00:00 UTC`, table.String())
+	assert.Regexp(t, `yesterdays\s+Pending\s+Delete\s+it has expired`, table.String())
+}
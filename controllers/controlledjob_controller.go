@@ -31,9 +31,12 @@ import (
 	kbatch "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // ControlledJobReconciler reconciles a ControlledJob object
@@ -61,6 +64,8 @@ type Clock interface {
 //+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=controlledjobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=controlledjobs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=controlledjobs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=calendars,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=clustercalendars,verbs=get;list;watch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
@@ -106,14 +111,58 @@ func (r *ControlledJobReconciler) SetupWithManager(mgr ctrl.Manager, options con
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &batch.ControlledJob{}, metadata.CalendarRefKey, func(rawObj client.Object) []string {
+		controlledJob := rawObj.(*batch.ControlledJob)
+		if controlledJob.Spec.CalendarRef == nil {
+			return nil
+		}
+		ref := controlledJob.Spec.CalendarRef
+		return []string{calendarRefIndexValue(ref.IsClusterCalendar(), ref.Name)}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batch.ControlledJob{}).
 		Owns(&kbatch.Job{}).
+		Watches(&batch.Calendar{}, handler.EnqueueRequestsFromMapFunc(r.controlledJobsUsingCalendar(mgr.GetClient(), false))).
+		Watches(&batch.ClusterCalendar{}, handler.EnqueueRequestsFromMapFunc(r.controlledJobsUsingCalendar(mgr.GetClient(), true))).
 		Watches(&batch.ControlledJob{}, &metrics.Watcher{}).
 		WithOptions(options).
 		Complete(r)
 }
 
+// controlledJobsUsingCalendar maps a changed Calendar or ClusterCalendar to reconcile requests for every ControlledJob
+// which references it, so their schedules are recalculated
+func (r *ControlledJobReconciler) controlledJobsUsingCalendar(c client.Client, isClusterCalendar bool) handler.MapFunc {
+	return func(ctx context.Context, calendar client.Object) []reconcile.Request {
+		opts := []client.ListOption{client.MatchingFields{metadata.CalendarRefKey: calendarRefIndexValue(isClusterCalendar, calendar.GetName())}}
+		if !isClusterCalendar {
+			// A Calendar can only be referenced by ControlledJobs in its own namespace
+			opts = append(opts, client.InNamespace(calendar.GetNamespace()))
+		}
+
+		var controlledJobs batch.ControlledJobList
+		if err := c.List(ctx, &controlledJobs, opts...); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list controlled jobs using calendar", "calendar", calendar.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, len(controlledJobs.Items))
+		for i, controlledJob := range controlledJobs.Items {
+			requests[i] = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: controlledJob.Namespace, Name: controlledJob.Name}}
+		}
+		return requests
+	}
+}
+
+func calendarRefIndexValue(isClusterCalendar bool, name string) string {
+	if isClusterCalendar {
+		return batch.ClusterCalendarKind + "/" + name
+	}
+	return batch.CalendarKind + "/" + name
+}
+
 func missedStartingDeadline(startOfCurrentPeriod time.Time, startingDeadlineSeconds *int64) bool {
 	if startingDeadlineSeconds == nil {
 		return false
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	batchv2 "github.com/G-Research/controlled-job/api/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SetupConversionWebhookWithManager serves the /convert endpoint, which the API server calls to convert
+// ControlledJobs between API versions. The conversions themselves are implemented by the API types, using v1 as the
+// hub version
+func SetupConversionWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&batchv2.ControlledJob{}).
+		Complete()
+}
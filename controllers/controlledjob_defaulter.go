@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	batchv2 "github.com/G-Research/controlled-job/api/v2"
+	"github.com/G-Research/controlled-job/pkg/defaults"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-batch-gresearch-co-uk-v1-controlledjob,mutating=true,failurePolicy=fail,sideEffects=None,matchPolicy=Exact,groups=batch.gresearch.co.uk,resources=controlledjobs,verbs=create;update,versions=v1,name=mcontrolledjob.gresearch.co.uk,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-batch-gresearch-co-uk-v2-controlledjob,mutating=true,failurePolicy=fail,sideEffects=None,matchPolicy=Exact,groups=batch.gresearch.co.uk,resources=controlledjobs,verbs=create;update,versions=v2,name=mcontrolledjobv2.gresearch.co.uk,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+// ControlledJobDefaulter is a mutating admission webhook which fills in the fields a ControlledJob leaves unset
+// from the defaults for its namespace, so that the effective spec is visible in the stored object
+//
+// The defaults come from a ConfigMap called ConfigMapName in the ControlledJob's namespace, falling back to the
+// cluster-wide ConfigMap ClusterDefaults (if set) for any defaults the namespace's ConfigMap doesn't set.
+// It's served for both API versions, and ControlledJobs written in v2 are defaulted by way of v1
+type ControlledJobDefaulter struct {
+	// Reader reads the defaults ConfigMaps. It should read directly from the API server, to avoid caching every
+	// ConfigMap in the cluster
+	client.Reader
+	ConfigMapName   string
+	ClusterDefaults *types.NamespacedName
+}
+
+var _ admission.CustomDefaulter = &ControlledJobDefaulter{}
+
+func (d *ControlledJobDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	for _, apiType := range []runtime.Object{&batch.ControlledJob{}, &batchv2.ControlledJob{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			For(apiType).
+			WithDefaulter(d).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *ControlledJobDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	controlledJob, err := asControlledJob(obj)
+	if err != nil {
+		return err
+	}
+
+	var effectiveDefaults defaults.Defaults
+	if d.ClusterDefaults != nil {
+		clusterDefaults, err := d.defaultsFrom(ctx, *d.ClusterDefaults)
+		if err != nil {
+			return err
+		}
+		effectiveDefaults = clusterDefaults
+	}
+	namespaceDefaults, err := d.defaultsFrom(ctx, types.NamespacedName{Namespace: controlledJob.Namespace, Name: d.ConfigMapName})
+	if err != nil {
+		return err
+	}
+	effectiveDefaults.OverriddenBy(namespaceDefaults).ApplyTo(controlledJob)
+
+	if v2ControlledJob, ok := obj.(*batchv2.ControlledJob); ok {
+		// The defaults were applied to a copy converted to v1
+		return v2ControlledJob.ConvertFrom(controlledJob)
+	}
+	return nil
+}
+
+// defaultsFrom reads the defaults in the given ConfigMap. A ConfigMap which doesn't exist has no defaults
+func (d *ControlledJobDefaulter) defaultsFrom(ctx context.Context, name types.NamespacedName) (defaults.Defaults, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := d.Get(ctx, name, configMap); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return defaults.Defaults{}, nil
+		}
+		return defaults.Defaults{}, errors.Wrapf(err, "failed to get defaults ConfigMap %s", name)
+	}
+	result, err := defaults.FromConfigMap(configMap)
+	if err != nil {
+		return defaults.Defaults{}, errors.Wrapf(err, "invalid defaults ConfigMap %s", name)
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	batchv2 "github.com/G-Research/controlled-job/api/v2"
+	"github.com/G-Research/controlled-job/pkg/k8s"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+func Test_ControlledJobDefaulter(t *testing.T) {
+	clusterDefaults := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "cluster-defaults"},
+		Data:       map[string]string{"timezone": "UTC", "specChangePolicy": "Recreate"},
+	}
+	namespaceDefaults := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "controlled-job-defaults"},
+		Data:       map[string]string{"timezone": "Europe/London", "applyMutations": "true"},
+	}
+	invalidDefaults := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "controlled-job-defaults"},
+		Data:       map[string]string{"startingDeadlineSeconds": "soon"},
+	}
+	sut := &ControlledJobDefaulter{
+		Reader:          fake.NewClientBuilder().WithScheme(k8s.GetScheme()).WithObjects(clusterDefaults, namespaceDefaults, invalidDefaults).Build(),
+		ConfigMapName:   "controlled-job-defaults",
+		ClusterDefaults: &types.NamespacedName{Namespace: "operator", Name: "cluster-defaults"},
+	}
+
+	teamA := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-job"}}
+	err := sut.Default(context.Background(), teamA)
+
+	assert.Nil(t, err, "should not return an error")
+	assert.Equal(t, "Europe/London", teamA.Spec.Timezone.Name, "the namespace's defaults should take precedence")
+	assert.Equal(t, batch.RecreateSpecChangePolicy, teamA.Spec.RestartStrategy.SpecChangePolicy, "should fall back to the cluster-wide defaults")
+	assert.Equal(t, "true", teamA.Annotations[metadata.ApplyMutationsAnnotation])
+	assert.Nil(t, teamA.Spec.StartingDeadlineSeconds, "there is no default startingDeadlineSeconds")
+
+	teamB := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "my-job"}}
+	err = sut.Default(context.Background(), teamB)
+
+	assert.Nil(t, err, "a namespace without a defaults ConfigMap should not return an error")
+	assert.Equal(t, "UTC", teamB.Spec.Timezone.Name)
+
+	teamC := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "my-job"}}
+	err = sut.Default(context.Background(), teamC)
+
+	assert.NotNil(t, err, "an invalid defaults ConfigMap should return an error")
+
+	teamAV2 := &batchv2.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-job"}}
+	err = sut.Default(context.Background(), teamAV2)
+
+	assert.Nil(t, err, "should not return an error for a v2 ControlledJob")
+	assert.Equal(t, "Europe/London", teamAV2.Spec.Timezone.Name, "should apply the defaults to a v2 ControlledJob")
+	assert.Equal(t, batch.RecreateSpecChangePolicy, teamAV2.Spec.RestartStrategy.SpecChangePolicy)
+	assert.Equal(t, "true", teamAV2.Annotations[metadata.ApplyMutationsAnnotation])
+}
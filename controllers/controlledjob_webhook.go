@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	batchv2 "github.com/G-Research/controlled-job/api/v2"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+	"github.com/G-Research/controlled-job/pkg/schedule"
+	"github.com/G-Research/controlled-job/pkg/validation"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-batch-gresearch-co-uk-v1-controlledjob,mutating=false,failurePolicy=fail,sideEffects=None,matchPolicy=Exact,groups=batch.gresearch.co.uk,resources=controlledjobs,verbs=create;update,versions=v1,name=vcontrolledjob.gresearch.co.uk,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-batch-gresearch-co-uk-v2-controlledjob,mutating=false,failurePolicy=fail,sideEffects=None,matchPolicy=Exact,groups=batch.gresearch.co.uk,resources=controlledjobs,verbs=create;update,versions=v2,name=vcontrolledjobv2.gresearch.co.uk,admissionReviewVersions=v1
+
+// ControlledJobValidator is a validating admission webhook which rejects ControlledJobs that would fail to
+// reconcile, so that mistakes are found at `kubectl apply` time rather than as a FailedToCalculateSchedule event.
+// It's served for both API versions, and ControlledJobs written in v2 are checked after converting them to v1
+type ControlledJobValidator struct {
+	clientadapter.ControlledJobClient
+	Clock
+}
+
+var _ admission.CustomValidator = &ControlledJobValidator{}
+
+func (v *ControlledJobValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// set up a real clock, since we're not in a test
+	if v.Clock == nil {
+		v.Clock = realClock{}
+	}
+
+	for _, apiType := range []runtime.Object{&batch.ControlledJob{}, &batchv2.ControlledJob{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			For(apiType).
+			WithValidator(v).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *ControlledJobValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	controlledJob, err := asControlledJob(obj)
+	if err != nil {
+		return nil, err
+	}
+	return v.validate(ctx, controlledJob, validation.ValidateControlledJob)
+}
+
+// ValidateUpdate only checks the schedule, and only if the spec has changed. The name can't be changed, so checking
+// it would only stop a ControlledJob created before the check was added from being updated at all. Likewise, updates
+// to metadata, such as removing a finalizer while it's being deleted, mustn't fail just because the schedule has
+// since stopped working (e.g. because its exchange calendar has run out)
+func (v *ControlledJobValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldControlledJob, err := asControlledJob(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	controlledJob, err := asControlledJob(newObj)
+	if err != nil {
+		return nil, err
+	}
+	if controlledJob.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldControlledJob.Spec, controlledJob.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, controlledJob, validation.ValidateControlledJobSpec)
+}
+
+func (v *ControlledJobValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// asControlledJob returns the v1 ControlledJob which the admission webhooks work with. A v1 ControlledJob is returned
+// as it is, so changes to it change obj, whereas a v2 ControlledJob is converted to a new v1 ControlledJob
+func asControlledJob(obj runtime.Object) (*batch.ControlledJob, error) {
+	switch controlledJob := obj.(type) {
+	case *batch.ControlledJob:
+		return controlledJob, nil
+	case *batchv2.ControlledJob:
+		hub := &batch.ControlledJob{}
+		if err := controlledJob.ConvertTo(hub); err != nil {
+			return nil, fmt.Errorf("failed to convert ControlledJob %s to %s: %w", controlledJob.Name, batch.GroupVersion, err)
+		}
+		return hub, nil
+	default:
+		return nil, fmt.Errorf("expected a ControlledJob but got a %T", obj)
+	}
+}
+
+func (v *ControlledJobValidator) validate(ctx context.Context, controlledJob *batch.ControlledJob,
+	validateFunc func(*batch.ControlledJob, *batch.CalendarSpec, time.Time) field.ErrorList) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var calendar *batch.CalendarSpec
+	if ref := controlledJob.Spec.CalendarRef; ref != nil {
+		var err error
+		calendar, err = v.GetCalendar(ctx, controlledJob.Namespace, *ref)
+		if err != nil {
+			// The calendar may simply not have been created yet, so don't reject the ControlledJob because of it.
+			// The reconciler reports the problem if it's still there later
+			warnings = append(warnings, fmt.Sprintf("calendar %s could not be loaded, so the schedule has been checked without it: %s", ref.Name, err))
+			calendar = nil
+		}
+	}
+
+	if errs := validateFunc(controlledJob, calendar, v.Now()); len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(batch.GroupVersion.WithKind("ControlledJob").GroupKind(), controlledJob.Name, errs)
+	}
+
+	// The schedule works, but may not do what was intended
+	scheduleWarnings, err := schedule.AnalyzeSchedule(controlledJob, calendar, v.Now())
+	if err != nil {
+		return warnings, err
+	}
+	for _, warning := range scheduleWarnings {
+		warnings = append(warnings, warning.Message)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	batchv2 "github.com/G-Research/controlled-job/api/v2"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func Test_ControlledJobValidator(t *testing.T) {
+	client := &clientadapter.ControlledJobClientMock{
+		GetCalendarFunc: func(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+			return nil, errors.New("not found")
+		},
+	}
+	sut := &ControlledJobValidator{
+		ControlledJobClient: client,
+		Clock:               fixedClock{time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	controlledJob := &batch.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "my-namespace"},
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "UTC"},
+			Events: []batch.EventSpec{
+				{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+				{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+			},
+		},
+	}
+
+	warnings, err := sut.ValidateCreate(context.Background(), controlledJob)
+	assert.Nil(t, err, "should accept a valid ControlledJob")
+	assert.Empty(t, warnings)
+
+	controlledJob.Spec.CalendarRef = &batch.CalendarReference{Name: "my-calendar"}
+	warnings, err = sut.ValidateCreate(context.Background(), controlledJob)
+	assert.Nil(t, err, "should accept a ControlledJob whose calendar doesn't exist yet")
+	assert.Len(t, warnings, 1, "should warn that the calendar couldn't be loaded")
+
+	conflicting := controlledJob.DeepCopy()
+	conflicting.Spec.CalendarRef = nil
+	conflicting.Spec.Events[1].CronSchedule = "0 9 * * FRI"
+	warnings, err = sut.ValidateCreate(context.Background(), conflicting)
+	assert.Nil(t, err, "should accept a ControlledJob whose schedule has conflicting events")
+	assert.Len(t, warnings, 1, "should warn about the start and stop events at the same instant")
+
+	invalid := controlledJob.DeepCopy()
+	invalid.Spec.Events[1].CronSchedule = "not a cron schedule"
+	_, err = sut.ValidateUpdate(context.Background(), controlledJob, invalid)
+	assert.True(t, apierrors.IsInvalid(err), "should reject an invalid ControlledJob, but got %v", err)
+
+	_, err = sut.ValidateDelete(context.Background(), invalid)
+	assert.Nil(t, err, "should never reject a delete")
+}
+
+func Test_ControlledJobValidator_ValidateUpdate(t *testing.T) {
+	sut := &ControlledJobValidator{
+		ControlledJobClient: &clientadapter.ControlledJobClientMock{},
+		Clock:               fixedClock{time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	controlledJob := &batch.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 50), Namespace: "my-namespace"},
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "UTC"},
+			Events: []batch.EventSpec{
+				{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+				{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+			},
+		},
+	}
+
+	_, err := sut.ValidateCreate(context.Background(), controlledJob)
+	assert.True(t, apierrors.IsInvalid(err), "should reject a new ControlledJob whose name is too long, but got %v", err)
+
+	suspended := controlledJob.DeepCopy()
+	suspended.Spec.Suspend = pointer.Bool(true)
+	_, err = sut.ValidateUpdate(context.Background(), controlledJob, suspended)
+	assert.Nil(t, err, "should not check the name of an existing ControlledJob")
+
+	invalid := controlledJob.DeepCopy()
+	invalid.Spec.Events[1].CronSchedule = "not a cron schedule"
+	annotated := invalid.DeepCopy()
+	annotated.Annotations = map[string]string{"foo": "bar"}
+	_, err = sut.ValidateUpdate(context.Background(), invalid, annotated)
+	assert.Nil(t, err, "should accept an update which doesn't change the spec")
+
+	fixed := invalid.DeepCopy()
+	fixed.Spec.Events[1].CronSchedule = "0 18 * * MON-FRI"
+	_, err = sut.ValidateUpdate(context.Background(), invalid, fixed)
+	assert.Nil(t, err, "should accept an update which fixes the spec")
+
+	deleted := invalid.DeepCopy()
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)}
+	deleted.Spec.Events[0].CronSchedule = "not a cron schedule either"
+	_, err = sut.ValidateUpdate(context.Background(), invalid, deleted)
+	assert.Nil(t, err, "should accept any update to a ControlledJob which is being deleted")
+}
+
+func Test_ControlledJobValidator_V2(t *testing.T) {
+	sut := &ControlledJobValidator{
+		ControlledJobClient: &clientadapter.ControlledJobClientMock{},
+		Clock:               fixedClock{time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	controlledJob := &batchv2.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "my-namespace"},
+		Spec: batchv2.ControlledJobSpec{
+			Timezone: batchv2.TimezoneSpec{Name: "UTC"},
+			Events: []batchv2.EventSpec{
+				{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+				{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+			},
+		},
+	}
+
+	warnings, err := sut.ValidateCreate(context.Background(), controlledJob)
+	assert.Nil(t, err, "should accept a valid v2 ControlledJob")
+	assert.Empty(t, warnings)
+
+	invalid := controlledJob.DeepCopy()
+	invalid.Spec.Events[1].CronSchedule = "not a cron schedule"
+	_, err = sut.ValidateCreate(context.Background(), invalid)
+	assert.True(t, apierrors.IsInvalid(err), "should reject an invalid v2 ControlledJob, but got %v", err)
+	_, err = sut.ValidateUpdate(context.Background(), controlledJob, invalid)
+	assert.True(t, apierrors.IsInvalid(err), "should reject an invalid update to a v2 ControlledJob, but got %v", err)
+}
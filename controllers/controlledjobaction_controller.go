@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/actions"
+)
+
+// ControlledJobActionReconciler carries out each ControlledJobAction once, and records its outcome in its status
+type ControlledJobActionReconciler struct {
+	client.Client
+	Clock
+	Executor *actions.Executor
+}
+
+//+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=controlledjobactions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch.gresearch.co.uk,resources=controlledjobactions/status,verbs=get;update;patch
+
+// Reconcile carries out the given ControlledJobAction, unless it has already been processed
+func (r *ControlledJobActionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var action batch.ControlledJobAction
+	if err := r.Get(ctx, req.NamespacedName, &action); err != nil {
+		// An action which has been deleted doesn't need carrying out
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if action.Status.IsProcessed() {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Executor.Execute(ctx, &action, r.Now()); err != nil {
+		log.FromContext(ctx).Error(err, "failed to carry out ControlledJobAction", "req", req)
+		return ctrl.Result{}, err
+	}
+	if err := r.Status().Update(ctx, &action); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ControlledJobActionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = realClock{}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&batch.ControlledJobAction{}).
+		Complete(r)
+}
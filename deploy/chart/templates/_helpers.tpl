@@ -61,3 +61,39 @@ app.kubernetes.io/name: {{ include "controlled-job.name" . }}
 app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
 {{- end -}}
+
+{{/*
+Name of the Service in front of the operator's webhook server
+*/}}
+{{- define "controlled-job.webhookServiceName" -}}
+{{- printf "%s-webhook" (include "controlled-job.fullname" .) -}}
+{{- end -}}
+
+{{/*
+Annotation asking cert-manager to inject the webhook server's CA into a webhook configuration or CRD
+*/}}
+{{- define "controlled-job.webhookCAInjection" -}}
+{{- if .Values.webhook.certManager.enabled -}}
+cert-manager.io/inject-ca-from: {{ printf "%s/%s-webhook" .Values.namespace.name (include "controlled-job.fullname" .) }}
+{{- end -}}
+{{- end -}}
+
+{{/*
+Conversion settings for the ControlledJob CRD. It serves more than one API version, so the API server calls the
+operator's conversion webhook to convert between them
+*/}}
+{{- define "controlled-job.crdConversion" -}}
+conversion:
+  strategy: Webhook
+  webhook:
+    clientConfig:
+      {{- with .Values.webhook.caBundle }}
+      caBundle: {{ . }}
+      {{- end }}
+      service:
+        name: {{ include "controlled-job.webhookServiceName" . }}
+        namespace: {{ .Values.namespace.name }}
+        path: /convert
+    conversionReviewVersions:
+    - v1
+{{- end -}}
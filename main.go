@@ -18,6 +18,9 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
@@ -28,13 +31,17 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/G-Research/controlled-job/controllers"
+	"github.com/G-Research/controlled-job/pkg/actions"
 	"github.com/G-Research/controlled-job/pkg/clientadapter"
 	"github.com/G-Research/controlled-job/pkg/events"
 	"github.com/G-Research/controlled-job/pkg/k8s"
+	"github.com/G-Research/controlled-job/pkg/migration"
 	"github.com/G-Research/controlled-job/pkg/mutators"
 	"github.com/G-Research/controlled-job/pkg/reconciliation"
+	"github.com/G-Research/controlled-job/pkg/schedule"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,6 +56,14 @@ func main() {
 	var enableAutoRecreateJobsOnSpecChange bool
 	var concurrency int
 	var remoteWebhookUrl string
+	var exchangeCalendarOverridesDir string
+	var enableValidatingWebhook bool
+	var webhookPort int
+	var enableDefaultingWebhook bool
+	var enableConversionWebhook bool
+	var migrateStorageVersion bool
+	var defaultsConfigMapName string
+	var clusterDefaultsConfigMap string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -58,6 +73,18 @@ func main() {
 		"Enable the new feature to auto-recreate jobs when a spec change is detected")
 	flag.IntVar(&concurrency, "concurrency", 1, "Maximum number of controlledJobs to process in parallel")
 	flag.StringVar(&remoteWebhookUrl, "job-admission-webhook-url", "", "If set, new jobs will be sent to this URL prior to creation. The remote service is expected to behave like a K8s MutatingAdmissionWebhook and return a patch to be applied")
+	flag.BoolVar(&enableValidatingWebhook, "enable-validating-webhook", false,
+		"Serve a validating admission webhook which rejects invalid ControlledJobs. Needs a TLS certificate in the webhook server's certificate directory")
+	flag.BoolVar(&enableDefaultingWebhook, "enable-defaulting-webhook", false,
+		"Serve a mutating admission webhook which fills in unset fields of ControlledJobs from namespace-level defaults. Needs a TLS certificate in the webhook server's certificate directory")
+	flag.StringVar(&defaultsConfigMapName, "defaults-configmap-name", "controlled-job-defaults", "The name of the ConfigMap in each namespace which holds the defaults for ControlledJobs in that namespace")
+	flag.StringVar(&clusterDefaultsConfigMap, "cluster-defaults-configmap", "", "If set, the namespace/name of a ConfigMap which holds defaults for ControlledJobs in every namespace. A namespace's own defaults take precedence")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false,
+		"Serve the conversion webhook which converts ControlledJobs between the v1 and v2 APIs. Needed whenever the CRD serves both versions. Needs a TLS certificate in the webhook server's certificate directory")
+	flag.BoolVar(&migrateStorageVersion, "migrate-storage-version", false,
+		"On startup, rewrite every ControlledJob in the CRD's storage version and update the CRD's status.storedVersions, so that older API versions can later be removed")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on.")
+	flag.StringVar(&exchangeCalendarOverridesDir, "exchange-calendar-overrides-dir", "", "If set, any *.yaml files in this directory are applied on top of the bundled exchange trading calendars")
 
 	opts := zap.Options{
 		Development: true,
@@ -77,12 +104,21 @@ func main() {
 		}
 	}
 
+	exchangeCalendars, err := schedule.LoadExchangeCalendars(exchangeCalendarOverridesDir)
+	if err != nil {
+		setupLog.Error(err, "unable to load exchange calendars", "overridesDir", exchangeCalendarOverridesDir)
+		os.Exit(1)
+	}
+	setupLog.Info("loaded exchange calendars", "versions", exchangeCalendars.Versions())
+	schedule.UseExchangeCalendars(exchangeCalendars)
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 k8s.GetScheme(),
 		MetricsBindAddress:     metricsAddr,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "4a7b6ad8.gresearch.co.uk",
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: webhookPort}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -100,6 +136,57 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ControlledJob")
 		os.Exit(1)
 	}
+	if err = (&controllers.ControlledJobActionReconciler{
+		Client: mgr.GetClient(),
+		Executor: &actions.Executor{
+			ControlledJobClient: clientadapter.NewFromClient(mgr.GetClient()),
+			EventHandler:        events.NewHandler(mgr.GetEventRecorderFor("controlled-job-operator")),
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ControlledJobAction")
+		os.Exit(1)
+	}
+	if enableValidatingWebhook {
+		setupLog.Info("enabling validating webhook", "port", webhookPort)
+		if err = (&controllers.ControlledJobValidator{
+			ControlledJobClient: clientadapter.NewFromClient(mgr.GetClient()),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ControlledJob")
+			os.Exit(1)
+		}
+	}
+	if enableDefaultingWebhook {
+		defaulter := &controllers.ControlledJobDefaulter{
+			Reader:        mgr.GetAPIReader(),
+			ConfigMapName: defaultsConfigMapName,
+		}
+		if clusterDefaultsConfigMap != "" {
+			namespace, name, ok := strings.Cut(clusterDefaultsConfigMap, "/")
+			if !ok {
+				setupLog.Error(nil, "cluster defaults ConfigMap must be in the format namespace/name", "clusterDefaultsConfigMap", clusterDefaultsConfigMap)
+				os.Exit(1)
+			}
+			defaulter.ClusterDefaults = &types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		setupLog.Info("enabling defaulting webhook", "port", webhookPort, "defaultsConfigMapName", defaultsConfigMapName, "clusterDefaultsConfigMap", clusterDefaultsConfigMap)
+		if err = defaulter.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ControlledJob")
+			os.Exit(1)
+		}
+	}
+	if enableConversionWebhook {
+		setupLog.Info("enabling conversion webhook", "port", webhookPort)
+		if err = controllers.SetupConversionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ControlledJob")
+			os.Exit(1)
+		}
+	}
+	if migrateStorageVersion {
+		if err = mgr.Add(&migration.StorageVersionMigrator{Client: mgr.GetClient()}); err != nil {
+			setupLog.Error(err, "unable to set up storage version migration")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
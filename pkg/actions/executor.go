@@ -0,0 +1,228 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+	"github.com/G-Research/controlled-job/pkg/events"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/schedule"
+)
+
+// Executor carries out ControlledJobActions. It only creates and suspends Jobs, and adds exclusions: the
+// ControlledJob's own reconcile then takes over, exactly as if a user had done the same by hand. For example, a
+// Job created by a restart action is adopted in place of the running Job, which is then deleted
+type Executor struct {
+	clientadapter.ControlledJobClient
+	EventHandler events.Handler
+}
+
+// outcome is the result of carrying out an action, before it's recorded in its status
+type outcome struct {
+	failed  bool
+	message string
+	jobName string
+}
+
+func succeeded(jobName, format string, args ...interface{}) outcome {
+	return outcome{message: fmt.Sprintf(format, args...), jobName: jobName}
+}
+
+func failed(format string, args ...interface{}) outcome {
+	return outcome{failed: true, message: fmt.Sprintf(format, args...)}
+}
+
+// Execute carries out the given action, and records its outcome in the action's status and in the ControlledJob's
+// action history. The caller is responsible for saving the action's status.
+//
+// An action which can't be carried out, for example because its ControlledJob doesn't exist, is marked as Failed.
+// An error is only returned if it might work if tried again, for example if a Job couldn't be created. Trying
+// again is safe: Jobs are annotated with the action which created or stopped them, so an action is never carried out
+// twice
+func (e *Executor) Execute(ctx context.Context, action *batch.ControlledJobAction, now time.Time) error {
+	key := types.NamespacedName{Namespace: action.Namespace, Name: action.Spec.ControlledJobName}
+	controlledJob, ok, err := e.GetControlledJob(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get ControlledJob %s", key.Name)
+	}
+	if !ok {
+		setOutcome(action, failed("ControlledJob %s not found", key.Name), now)
+		return nil
+	}
+	jobList, err := e.ListJobsForControlledJob(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list jobs for ControlledJob %s", key.Name)
+	}
+	jobs := make([]*kbatch.Job, len(jobList.Items))
+	for i := range jobList.Items {
+		jobs[i] = &jobList.Items[i]
+	}
+
+	var result outcome
+	switch action.Spec.Type {
+	case batch.ActionTypeStart:
+		result, err = e.start(ctx, action, controlledJob, jobs, now)
+	case batch.ActionTypeStop:
+		result, err = e.stop(ctx, action, jobs)
+	case batch.ActionTypeRestart:
+		result, err = e.restart(ctx, action, controlledJob, jobs)
+	case batch.ActionTypeSkipToday:
+		result, err = e.skipToday(ctx, action, controlledJob, jobs, now)
+	default:
+		result = failed("unknown action type %q", action.Spec.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	setOutcome(action, result, now)
+	log.FromContext(ctx).Info("carried out ControlledJobAction", "action", action.Name, "type", action.Spec.Type,
+		"controlledJob", controlledJob.Name, "phase", action.Status.Phase, "message", action.Status.Message)
+	e.EventHandler.RecordEvent(ctx, controlledJob, events.NewControlledJobActionAction(action))
+	if err := e.UpdateStatus(ctx, controlledJob); err != nil {
+		// The action has been carried out, so don't fail it just because it couldn't be recorded in the history
+		log.FromContext(ctx).Error(err, "failed to record ControlledJobAction in the action history", "action", action.Name, "controlledJob", controlledJob.Name)
+	}
+	return nil
+}
+
+// start creates a manually scheduled Job, unless one is already running. It's adopted in place of any Job which
+// was stopped by the user, and is stopped at the next stop event
+func (e *Executor) start(ctx context.Context, action *batch.ControlledJobAction, controlledJob *batch.ControlledJob, jobs []*kbatch.Job, now time.Time) (outcome, error) {
+	if job := jobCarryingOut(action, jobs); job != nil {
+		return succeeded(job.Name, "Started job: %s", job.Name), nil
+	}
+	if running := RunningJobs(jobs); len(running) > 0 {
+		return succeeded(running[0].Name, "Job %s is already running", running[0].Name), nil
+	}
+	if IsSuspended(controlledJob) {
+		return failed("ControlledJob %s is suspended, so no job can be started", controlledJob.Name), nil
+	}
+
+	job, err := BuildStartedJob(ctx, controlledJob, jobs, now)
+	if err != nil {
+		return failed("failed to build job: %v", err), nil
+	}
+	job.Annotations[metadata.ActionAnnotation] = action.Name
+	if err := e.CreateJob(ctx, job); err != nil {
+		return outcome{}, events.WrapError(err, events.FailedToCreateJob, fmt.Sprintf("failed to create job %s in namespace %s", job.Name, job.Namespace))
+	}
+	return succeeded(job.Name, "Started job: %s", job.Name), nil
+}
+
+// stop suspends any running Job, marking it as stopped by the user so that it isn't unsuspended again until the
+// end of its run period
+func (e *Executor) stop(ctx context.Context, action *batch.ControlledJobAction, jobs []*kbatch.Job) (outcome, error) {
+	running := RunningJobs(jobs)
+	if len(running) == 0 {
+		if job := jobCarryingOut(action, jobs); job != nil {
+			return succeeded(job.Name, "Stopped job: %s", job.Name), nil
+		}
+		return succeeded("", "No job is running"), nil
+	}
+	names, err := e.stopJobs(ctx, action, running)
+	if err != nil {
+		return outcome{}, err
+	}
+	return succeeded(running[0].Name, "Stopped job: %s", strings.Join(names, ", ")), nil
+}
+
+// restart creates a new Job to replace the running Job. The new Job is in the same run period, with a higher job
+// run id, so the ControlledJob's reconcile adopts it and deletes the old one
+func (e *Executor) restart(ctx context.Context, action *batch.ControlledJobAction, controlledJob *batch.ControlledJob, jobs []*kbatch.Job) (outcome, error) {
+	if job := jobCarryingOut(action, jobs); job != nil {
+		return succeeded(job.Name, "Created job: %s", job.Name), nil
+	}
+	running := RunningJobs(jobs)
+	if len(running) == 0 {
+		return failed("No job is running, so there is nothing to restart. Use a start action to start one"), nil
+	}
+
+	job, err := BuildRestartedJob(ctx, controlledJob, running[0], jobs)
+	if err != nil {
+		return failed("failed to build job: %v", err), nil
+	}
+	job.Annotations[metadata.ActionAnnotation] = action.Name
+	if err := e.CreateJob(ctx, job); err != nil {
+		return outcome{}, events.WrapError(err, events.FailedToCreateJob, fmt.Sprintf("failed to create job %s in namespace %s", job.Name, job.Namespace))
+	}
+	return succeeded(job.Name, "Created job: %s to replace %s", job.Name, running[0].Name), nil
+}
+
+// skipToday adds today's date, in the ControlledJob's timezone, to its exclusions, so that the rest of today's start
+// events are skipped, and stops any running Job
+func (e *Executor) skipToday(ctx context.Context, action *batch.ControlledJobAction, controlledJob *batch.ControlledJob, jobs []*kbatch.Job, now time.Time) (outcome, error) {
+	today, err := schedule.LocalDate(controlledJob.Spec.Timezone, now)
+	if err != nil {
+		return failed("failed to work out today's date: %v", err), nil
+	}
+	excluded, err := schedule.IsExcludedDate(controlledJob.Spec.Exclusions, today)
+	if err != nil {
+		return failed("failed to check the exclusions: %v", err), nil
+	}
+	message := fmt.Sprintf("%s is already excluded", today)
+	if !excluded {
+		controlledJob.Spec.Exclusions = append(controlledJob.Spec.Exclusions, batch.ExclusionSpec{
+			Date:        today,
+			Description: fmt.Sprintf("Skipped by ControlledJobAction %s", action.Name),
+		})
+		if err := e.UpdateControlledJob(ctx, controlledJob); err != nil {
+			return outcome{}, errors.Wrapf(err, "failed to add an exclusion to ControlledJob %s", controlledJob.Name)
+		}
+		message = fmt.Sprintf("Excluded %s", today)
+	}
+
+	running := RunningJobs(jobs)
+	if len(running) == 0 {
+		return succeeded("", message), nil
+	}
+	names, err := e.stopJobs(ctx, action, running)
+	if err != nil {
+		return outcome{}, err
+	}
+	return succeeded(running[0].Name, "%s, and stopped job: %s", message, strings.Join(names, ", ")), nil
+}
+
+func (e *Executor) stopJobs(ctx context.Context, action *batch.ControlledJobAction, jobs []*kbatch.Job) ([]string, error) {
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		MarkStoppedByUser(job)
+		job.Annotations[metadata.ActionAnnotation] = action.Name
+		if err := e.SuspendJob(ctx, job); err != nil {
+			return nil, events.WrapError(err, events.FailedToSuspendJob, fmt.Sprintf("failed to suspend job %s in namespace %s", job.Name, job.Namespace))
+		}
+		names[i] = job.Name
+	}
+	return names, nil
+}
+
+func setOutcome(action *batch.ControlledJobAction, result outcome, now time.Time) {
+	action.Status.Phase = batch.ActionPhaseSucceeded
+	if result.failed {
+		action.Status.Phase = batch.ActionPhaseFailed
+	}
+	action.Status.Message = result.message
+	action.Status.JobName = result.jobName
+	action.Status.CompletionTime = &metav1.Time{Time: now}
+}
+
+// jobCarryingOut returns the Job which was created or stopped by the given action, if it has already been carried
+// out
+func jobCarryingOut(action *batch.ControlledJobAction, jobs []*kbatch.Job) *kbatch.Job {
+	for _, job := range jobs {
+		if job.Annotations[metadata.ActionAnnotation] == action.Name {
+			return job
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	kbatch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+	"github.com/G-Research/controlled-job/pkg/events"
+	jobpkg "github.com/G-Research/controlled-job/pkg/job"
+	"github.com/G-Research/controlled-job/pkg/k8s"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+var (
+	// 2024-06-03 is a Monday. The ControlledJob runs from 09:00 to 17:00 UTC on weekdays
+	runPeriodStart = time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	now            = time.Date(2024, 6, 3, 11, 30, 0, 0, time.UTC)
+)
+
+type executorTest struct {
+	*testing.T
+	client        client.Client
+	controlledJob *batch.ControlledJob
+	sut           *Executor
+}
+
+func newExecutorTest(t *testing.T, opts ...testhelpers.ControlledJobOption) *executorTest {
+	opts = append([]testhelpers.ControlledJobOption{
+		testhelpers.WithUID("my-uid"),
+		testhelpers.WithTimezone("UTC", 0),
+		testhelpers.WithCronEvent(batch.EventTypeStart, "0 9 * * MON-FRI"),
+		testhelpers.WithCronEvent(batch.EventTypeStop, "0 17 * * MON-FRI"),
+		testhelpers.WithDefaultJobTemplate(),
+	}, opts...)
+	controlledJob := testhelpers.NewControlledJob("my-controlled-job", opts...)
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(k8s.GetScheme()).
+		WithObjects(controlledJob).
+		WithStatusSubresource(controlledJob).
+		WithIndex(&kbatch.Job{}, metadata.JobOwnerKey, func(obj client.Object) []string {
+			owner := metav1.GetControllerOf(obj)
+			if owner == nil {
+				return nil
+			}
+			return []string{owner.Name}
+		}).
+		Build()
+	recorder := &events.EventRecorderMock{EventFunc: func(runtime.Object, string, string, string) {}}
+	return &executorTest{
+		T:             t,
+		client:        fakeClient,
+		controlledJob: controlledJob,
+		sut: &Executor{
+			ControlledJobClient: clientadapter.NewFromClient(fakeClient),
+			EventHandler:        events.NewHandler(recorder),
+		},
+	}
+}
+
+func (et *executorTest) givenAJob(scheduledTime time.Time, jobRunId int, opts ...func(job *kbatch.Job)) *kbatch.Job {
+	job, err := jobpkg.BuildForControlledJob(context.Background(), et.controlledJob, scheduledTime, jobRunId, false, false)
+	assert.Nil(et, err)
+	for _, opt := range opts {
+		opt(job)
+	}
+	assert.Nil(et, et.client.Create(context.Background(), job))
+	return job
+}
+
+func (et *executorTest) execute(actionType batch.ControlledJobActionType) *batch.ControlledJobAction {
+	action := &batch.ControlledJobAction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testhelpers.DefaultNamespace, Name: "my-action"},
+		Spec:       batch.ControlledJobActionSpec{ControlledJobName: et.controlledJob.Name, Type: actionType, Reason: "testing"},
+	}
+	assert.Nil(et, et.sut.Execute(context.Background(), action, now), "should not return an error")
+	return action
+}
+
+func (et *executorTest) jobs() []kbatch.Job {
+	var jobs kbatch.JobList
+	assert.Nil(et, et.client.List(context.Background(), &jobs))
+	return jobs.Items
+}
+
+func (et *executorTest) currentControlledJob() *batch.ControlledJob {
+	var controlledJob batch.ControlledJob
+	assert.Nil(et, et.client.Get(context.Background(), client.ObjectKeyFromObject(et.controlledJob), &controlledJob))
+	return &controlledJob
+}
+
+func (et *executorTest) shouldHaveRecordedInHistory(action *batch.ControlledJobAction) {
+	mostRecent := et.currentControlledJob().Status.MostRecentAction
+	if assert.NotNil(et, mostRecent, "should have recorded the action in the action history") {
+		assert.Equal(et, events.NewControlledJobActionAction(action).Message, mostRecent.Message)
+	}
+}
+
+func Test_Executor_Start(t *testing.T) {
+	et := newExecutorTest(t)
+
+	action := et.execute(batch.ActionTypeStart)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase, action.Status.Message)
+	jobs := et.jobs()
+	if assert.Len(t, jobs, 1) {
+		job := jobs[0]
+		assert.Equal(t, job.Name, action.Status.JobName)
+		assert.True(t, metadata.IsManuallyScheduledJob(&job), "should be manually scheduled")
+		assert.True(t, metadata.IsJobSuspended(&job), "should be left for the ControlledJob's reconcile to unsuspend")
+		assert.Equal(t, "my-action", job.Annotations[metadata.ActionAnnotation])
+		scheduledTime, _ := metadata.GetScheduledTime(&job)
+		assert.Equal(t, now, scheduledTime.UTC())
+	}
+	et.shouldHaveRecordedInHistory(action)
+
+	action = et.execute(batch.ActionTypeStart)
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase)
+	assert.Len(t, et.jobs(), 1, "should not start a second job when the same action is carried out again")
+}
+
+func Test_Executor_StartWhenAlreadyRunning(t *testing.T) {
+	et := newExecutorTest(t)
+	running := et.givenAJob(runPeriodStart, 0)
+
+	action := et.execute(batch.ActionTypeStart)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase)
+	assert.Equal(t, "Job "+running.Name+" is already running", action.Status.Message)
+	assert.Len(t, et.jobs(), 1, "should not start another job")
+}
+
+func Test_Executor_StartWhenSuspended(t *testing.T) {
+	suspend := true
+	et := newExecutorTest(t, func(controlledJob *batch.ControlledJob) { controlledJob.Spec.Suspend = &suspend })
+
+	action := et.execute(batch.ActionTypeStart)
+
+	assert.Equal(t, batch.ActionPhaseFailed, action.Status.Phase)
+	assert.Empty(t, et.jobs())
+	et.shouldHaveRecordedInHistory(action)
+}
+
+func Test_Executor_Stop(t *testing.T) {
+	et := newExecutorTest(t)
+	running := et.givenAJob(runPeriodStart, 0)
+
+	action := et.execute(batch.ActionTypeStop)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase)
+	assert.Equal(t, running.Name, action.Status.JobName)
+	jobs := et.jobs()
+	if assert.Len(t, jobs, 1) {
+		assert.True(t, metadata.WasJobStoppedByTheUser(&jobs[0]), "should be stopped by the user")
+	}
+	et.shouldHaveRecordedInHistory(action)
+}
+
+func Test_Executor_StopWhenNotRunning(t *testing.T) {
+	et := newExecutorTest(t)
+
+	action := et.execute(batch.ActionTypeStop)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase)
+	assert.Equal(t, "No job is running", action.Status.Message)
+}
+
+func Test_Executor_Restart(t *testing.T) {
+	et := newExecutorTest(t)
+	running := et.givenAJob(runPeriodStart, 0)
+
+	action := et.execute(batch.ActionTypeRestart)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase, action.Status.Message)
+	jobs := et.jobs()
+	if assert.Len(t, jobs, 2) {
+		for _, job := range jobs {
+			if job.Name == running.Name {
+				assert.False(t, metadata.IsJobSuspended(&job), "should leave the old job for the ControlledJob's reconcile to delete")
+				continue
+			}
+			assert.Equal(t, job.Name, action.Status.JobName)
+			scheduledTime, _ := metadata.GetScheduledTime(&job)
+			assert.Equal(t, runPeriodStart, scheduledTime.UTC(), "should be in the same run period")
+			runId, _ := metadata.GetJobRunId(&job)
+			assert.Equal(t, 1, runId, "should have a higher job run id, so that it's adopted in place of the old job")
+		}
+	}
+}
+
+func Test_Executor_RestartWhenNotRunning(t *testing.T) {
+	et := newExecutorTest(t)
+	et.givenAJob(runPeriodStart, 0, func(job *kbatch.Job) {
+		suspend := true
+		job.Spec.Suspend = &suspend
+		job.Annotations[metadata.SuspendReason] = metadata.UserStopSuspendReason
+	})
+
+	action := et.execute(batch.ActionTypeRestart)
+
+	assert.Equal(t, batch.ActionPhaseFailed, action.Status.Phase)
+	assert.Len(t, et.jobs(), 1, "should not create a job")
+}
+
+func Test_Executor_SkipToday(t *testing.T) {
+	// It's already the next day in Tokyo
+	et := newExecutorTest(t, testhelpers.WithTimezone("Asia/Tokyo", 0))
+	et.givenAJob(runPeriodStart, 0)
+
+	action := et.execute(batch.ActionTypeSkipToday)
+
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase, action.Status.Message)
+	exclusions := et.currentControlledJob().Spec.Exclusions
+	if assert.Len(t, exclusions, 1) {
+		assert.Equal(t, "2024-06-03", exclusions[0].Date)
+	}
+	jobs := et.jobs()
+	if assert.Len(t, jobs, 1) {
+		assert.True(t, metadata.WasJobStoppedByTheUser(&jobs[0]), "should stop the running job")
+	}
+	et.shouldHaveRecordedInHistory(action)
+
+	action = et.execute(batch.ActionTypeSkipToday)
+	assert.Equal(t, batch.ActionPhaseSucceeded, action.Status.Phase)
+	assert.Len(t, et.currentControlledJob().Spec.Exclusions, 1, "should not exclude the same date twice")
+}
+
+func Test_Executor_UnknownControlledJob(t *testing.T) {
+	et := newExecutorTest(t)
+	et.controlledJob = testhelpers.NewControlledJob("not-my-controlled-job")
+
+	action := et.execute(batch.ActionTypeStart)
+
+	assert.Equal(t, batch.ActionPhaseFailed, action.Status.Phase)
+	assert.Equal(t, "ControlledJob not-my-controlled-job not found", action.Status.Message)
+}
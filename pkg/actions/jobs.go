@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	kbatch "k8s.io/api/batch/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	jobpkg "github.com/G-Research/controlled-job/pkg/job"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+// The helpers in this file work out which Jobs to create or stop to operate a ControlledJob by hand. They're shared by
+// the Executor and the CLI, so that both leave Jobs in exactly the state the ControlledJob's reconcile expects
+
+// IsSuspended returns true if the ControlledJob has been suspended, either by the user or by the controller
+func IsSuspended(controlledJob *batch.ControlledJob) bool {
+	return (controlledJob.Spec.Suspend != nil && *controlledJob.Spec.Suspend) || controlledJob.Status.AutoSuspended != nil
+}
+
+// RunningJobs returns the Jobs which may be running and haven't already been stopped, newest first
+func RunningJobs(jobs []*kbatch.Job) []*kbatch.Job {
+	var result []*kbatch.Job
+	for _, job := range jobs {
+		if metadata.IsJobPotentiallyRunning(job) && !metadata.IsJobBeingDeleted(job) && !metadata.WasJobStoppedByTheUser(job) {
+			result = append(result, job)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return isNewer(result[i], result[j])
+	})
+	return result
+}
+
+// NextJobRunId returns the job run id for a new Job, which must be higher than any existing Job's for the new Job
+// to be adopted in their place
+func NextJobRunId(jobs []*kbatch.Job) int {
+	result := -1
+	for _, job := range jobs {
+		if runId, err := metadata.GetJobRunId(job); err == nil && runId > result {
+			result = runId
+		}
+	}
+	return result + 1
+}
+
+// BuildStartedJob builds a manually scheduled Job to start the ControlledJob now. It's scheduled at the current time,
+// so it counts as the latest Job in the current run period, and it's created suspended so that the ControlledJob's
+// reconcile can unsuspend it once no other Job is running
+func BuildStartedJob(ctx context.Context, controlledJob *batch.ControlledJob, jobs []*kbatch.Job, now time.Time) (*kbatch.Job, error) {
+	return jobpkg.BuildForControlledJob(ctx, controlledJob, now.Truncate(time.Second), NextJobRunId(jobs), true, true)
+}
+
+// BuildRestartedJob builds a Job to replace the given running Job. It's in the same run period, with a higher job
+// run id, so the ControlledJob's reconcile adopts it, deletes the running Job and then unsuspends it
+func BuildRestartedJob(ctx context.Context, controlledJob *batch.ControlledJob, running *kbatch.Job, jobs []*kbatch.Job) (*kbatch.Job, error) {
+	return jobpkg.RecreateJobWithNewSpec(ctx, running, controlledJob, NextJobRunId(jobs), true)
+}
+
+// MarkStoppedByUser annotates the Job so that, once it's suspended, the ControlledJob's reconcile doesn't unsuspend
+// it again until the end of its run period
+func MarkStoppedByUser(job *kbatch.Job) {
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[metadata.SuspendReason] = metadata.UserStopSuspendReason
+}
+
+func isNewer(job, other *kbatch.Job) bool {
+	scheduledTime, err := metadata.GetScheduledTime(job)
+	otherScheduledTime, otherErr := metadata.GetScheduledTime(other)
+	if err == nil && otherErr == nil && !scheduledTime.Equal(otherScheduledTime) {
+		return scheduledTime.After(otherScheduledTime)
+	}
+	runId, err := metadata.GetJobRunId(job)
+	otherRunId, otherErr := metadata.GetJobRunId(other)
+	if err == nil && otherErr == nil && runId != otherRunId {
+		return runId > otherRunId
+	}
+	return job.Name > other.Name
+}
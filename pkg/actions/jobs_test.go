@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	kbatch "k8s.io/api/batch/v1"
+
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_RunningJobs(t *testing.T) {
+	earlier := runPeriodStart.Add(-24 * time.Hour)
+	yesterdays := testhelpers.NewJob("yesterdays", metadata.WithScheduledTimeAnnotation(earlier), metadata.WithJobRunIdx(3))
+	first := testhelpers.NewJob("first", metadata.WithScheduledTimeAnnotation(runPeriodStart), metadata.WithJobRunIdx(0))
+	second := testhelpers.NewJob("second", metadata.WithScheduledTimeAnnotation(runPeriodStart), metadata.WithJobRunIdx(1))
+	completed := testhelpers.NewJob("completed", metadata.WithScheduledTimeAnnotation(now), metadata.WithJobRunIdx(4), testhelpers.HasSucceeded())
+	stopped := testhelpers.NewJob("stopped", metadata.WithScheduledTimeAnnotation(now), metadata.WithJobRunIdx(5),
+		testhelpers.IsSuspended(true), testhelpers.WithJobAnnotation(metadata.SuspendReason, metadata.UserStopSuspendReason))
+
+	jobs := []*kbatch.Job{first, completed, yesterdays, stopped, second}
+
+	assert.Equal(t, []*kbatch.Job{second, first, yesterdays}, RunningJobs(jobs), "should leave out finished and stopped jobs, and put the newest first")
+	assert.Equal(t, 6, NextJobRunId(jobs))
+	assert.Equal(t, 0, NextJobRunId(nil))
+}
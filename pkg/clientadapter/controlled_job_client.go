@@ -40,6 +40,15 @@ type ControlledJobClient interface {
 	// It will return any error returned by the underlying implementation.
 	UpdateStatus(ctx context.Context, controlledJob *batch.ControlledJob) error
 
+	// GetCalendar gets the spec of the Calendar or ClusterCalendar the given reference points at. A Calendar
+	// is looked up in the given namespace, a ClusterCalendar is not namespaced.
+	//
+	// Unlike GetControlledJob, a calendar which is not found is reported as an error, as the ControlledJob
+	// referencing it can't be scheduled without it.
+	//
+	// It will return any error returned by the underlying implementation.
+	GetCalendar(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error)
+
 	// ListJobsForControlledJob finds all jobs in the same namespace as namespacedName.Namespace
 	// which are owned by the controlled job named namespacedName.Name.
 	//
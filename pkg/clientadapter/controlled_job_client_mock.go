@@ -28,6 +28,9 @@ var _ ControlledJobClient = &ControlledJobClientMock{}
 //			DeleteJobFunc: func(ctx context.Context, job *kbatch.Job, propagation metav1.DeletionPropagation) error {
 //				panic("mock out the DeleteJob method")
 //			},
+//			GetCalendarFunc: func(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+//				panic("mock out the GetCalendar method")
+//			},
 //			GetControlledJobFunc: func(ctx context.Context, namespacedName types.NamespacedName) (*batch.ControlledJob, bool, error) {
 //				panic("mock out the GetControlledJob method")
 //			},
@@ -59,6 +62,9 @@ type ControlledJobClientMock struct {
 	// DeleteJobFunc mocks the DeleteJob method.
 	DeleteJobFunc func(ctx context.Context, job *kbatch.Job, propagation metav1.DeletionPropagation) error
 
+	// GetCalendarFunc mocks the GetCalendar method.
+	GetCalendarFunc func(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error)
+
 	// GetControlledJobFunc mocks the GetControlledJob method.
 	GetControlledJobFunc func(ctx context.Context, namespacedName types.NamespacedName) (*batch.ControlledJob, bool, error)
 
@@ -95,6 +101,15 @@ type ControlledJobClientMock struct {
 			// Propagation is the propagation argument value.
 			Propagation metav1.DeletionPropagation
 		}
+		// GetCalendar holds details about calls to the GetCalendar method.
+		GetCalendar []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Namespace is the namespace argument value.
+			Namespace string
+			// Ref is the ref argument value.
+			Ref batch.CalendarReference
+		}
 		// GetControlledJob holds details about calls to the GetControlledJob method.
 		GetControlledJob []struct {
 			// Ctx is the ctx argument value.
@@ -140,6 +155,7 @@ type ControlledJobClientMock struct {
 	}
 	lockCreateJob                sync.RWMutex
 	lockDeleteJob                sync.RWMutex
+	lockGetCalendar              sync.RWMutex
 	lockGetControlledJob         sync.RWMutex
 	lockListJobsForControlledJob sync.RWMutex
 	lockSuspendJob               sync.RWMutex
@@ -224,6 +240,46 @@ func (mock *ControlledJobClientMock) DeleteJobCalls() []struct {
 	return calls
 }
 
+// GetCalendar calls GetCalendarFunc.
+func (mock *ControlledJobClientMock) GetCalendar(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+	if mock.GetCalendarFunc == nil {
+		panic("ControlledJobClientMock.GetCalendarFunc: method is nil but ControlledJobClient.GetCalendar was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Namespace string
+		Ref       batch.CalendarReference
+	}{
+		Ctx:       ctx,
+		Namespace: namespace,
+		Ref:       ref,
+	}
+	mock.lockGetCalendar.Lock()
+	mock.calls.GetCalendar = append(mock.calls.GetCalendar, callInfo)
+	mock.lockGetCalendar.Unlock()
+	return mock.GetCalendarFunc(ctx, namespace, ref)
+}
+
+// GetCalendarCalls gets all the calls that were made to GetCalendar.
+// Check the length with:
+//
+//	len(mockedControlledJobClient.GetCalendarCalls())
+func (mock *ControlledJobClientMock) GetCalendarCalls() []struct {
+	Ctx       context.Context
+	Namespace string
+	Ref       batch.CalendarReference
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Namespace string
+		Ref       batch.CalendarReference
+	}
+	mock.lockGetCalendar.RLock()
+	calls = mock.calls.GetCalendar
+	mock.lockGetCalendar.RUnlock()
+	return calls
+}
+
 // GetControlledJob calls GetControlledJobFunc.
 func (mock *ControlledJobClientMock) GetControlledJob(ctx context.Context, namespacedName types.NamespacedName) (*batch.ControlledJob, bool, error) {
 	if mock.GetControlledJobFunc == nil {
@@ -41,6 +41,21 @@ func (c *ControllerClientAdapter) UpdateStatus(ctx context.Context, controlledJo
 	return c.Status().Update(ctx, controlledJob)
 }
 
+func (c *ControllerClientAdapter) GetCalendar(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+	if ref.IsClusterCalendar() {
+		clusterCalendar := &batch.ClusterCalendar{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, clusterCalendar); err != nil {
+			return nil, err
+		}
+		return &clusterCalendar.Spec, nil
+	}
+	calendar := &batch.Calendar{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, calendar); err != nil {
+		return nil, err
+	}
+	return &calendar.Spec, nil
+}
+
 func (c *ControllerClientAdapter) ListJobsForControlledJob(ctx context.Context, namespacedName types.NamespacedName) (childJobs kbatch.JobList, err error) {
 	err = c.List(ctx, &childJobs, client.InNamespace(namespacedName.Namespace), client.MatchingFields{metadata.JobOwnerKey: namespacedName.Name})
 	return
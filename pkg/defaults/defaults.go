@@ -0,0 +1,103 @@
+package defaults
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+// The keys of a defaults ConfigMap
+const (
+	TimezoneKey                = "timezone"
+	SpecChangePolicyKey        = "specChangePolicy"
+	StartingDeadlineSecondsKey = "startingDeadlineSeconds"
+	ApplyMutationsKey          = "applyMutations"
+)
+
+// Defaults are the values filled in for the fields a ControlledJob leaves unset. A zero or nil field means there is
+// no default for it
+type Defaults struct {
+	Timezone                string
+	SpecChangePolicy        batch.SpecChangePolicy
+	StartingDeadlineSeconds *int64
+	ApplyMutations          *bool
+}
+
+// FromConfigMap reads defaults from a ConfigMap. Keys which aren't set mean there is no default for that field
+func FromConfigMap(configMap *corev1.ConfigMap) (Defaults, error) {
+	var result Defaults
+	if configMap == nil {
+		return result, nil
+	}
+	result.Timezone = configMap.Data[TimezoneKey]
+
+	if value, ok := configMap.Data[SpecChangePolicyKey]; ok {
+		switch policy := batch.SpecChangePolicy(value); policy {
+		case batch.IgnoreSpecChangePolicy, batch.RecreateSpecChangePolicy:
+			result.SpecChangePolicy = policy
+		default:
+			return Defaults{}, errors.Errorf("%s must be %s or %s, not %q", SpecChangePolicyKey, batch.IgnoreSpecChangePolicy, batch.RecreateSpecChangePolicy, value)
+		}
+	}
+
+	if value, ok := configMap.Data[StartingDeadlineSecondsKey]; ok {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds < 0 {
+			return Defaults{}, errors.Errorf("%s must be a whole number of seconds, not %q", StartingDeadlineSecondsKey, value)
+		}
+		result.StartingDeadlineSeconds = &seconds
+	}
+
+	if value, ok := configMap.Data[ApplyMutationsKey]; ok {
+		applyMutations, err := strconv.ParseBool(value)
+		if err != nil {
+			return Defaults{}, errors.Errorf("%s must be true or false, not %q", ApplyMutationsKey, value)
+		}
+		result.ApplyMutations = &applyMutations
+	}
+	return result, nil
+}
+
+// OverriddenBy returns these defaults, with any defaults set in overrides taking precedence. This is how a
+// namespace's defaults take precedence over the cluster-wide defaults
+func (d Defaults) OverriddenBy(overrides Defaults) Defaults {
+	result := d
+	if overrides.Timezone != "" {
+		result.Timezone = overrides.Timezone
+	}
+	if overrides.SpecChangePolicy != "" {
+		result.SpecChangePolicy = overrides.SpecChangePolicy
+	}
+	if overrides.StartingDeadlineSeconds != nil {
+		result.StartingDeadlineSeconds = overrides.StartingDeadlineSeconds
+	}
+	if overrides.ApplyMutations != nil {
+		result.ApplyMutations = overrides.ApplyMutations
+	}
+	return result
+}
+
+// ApplyTo fills in the defaults for any fields the ControlledJob leaves unset. Fields which are already set are never
+// changed
+func (d Defaults) ApplyTo(controlledJob *batch.ControlledJob) {
+	if controlledJob.Spec.Timezone.Name == "" && d.Timezone != "" {
+		controlledJob.Spec.Timezone.Name = d.Timezone
+	}
+	if controlledJob.Spec.RestartStrategy.SpecChangePolicy == "" && d.SpecChangePolicy != "" {
+		controlledJob.Spec.RestartStrategy.SpecChangePolicy = d.SpecChangePolicy
+	}
+	if controlledJob.Spec.StartingDeadlineSeconds == nil && d.StartingDeadlineSeconds != nil {
+		startingDeadlineSeconds := *d.StartingDeadlineSeconds
+		controlledJob.Spec.StartingDeadlineSeconds = &startingDeadlineSeconds
+	}
+	if _, ok := controlledJob.Annotations[metadata.ApplyMutationsAnnotation]; !ok && d.ApplyMutations != nil {
+		if controlledJob.Annotations == nil {
+			controlledJob.Annotations = map[string]string{}
+		}
+		controlledJob.Annotations[metadata.ApplyMutationsAnnotation] = strconv.FormatBool(*d.ApplyMutations)
+	}
+}
@@ -0,0 +1,81 @@
+package defaults
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+func Test_FromConfigMap(t *testing.T) {
+	result, err := FromConfigMap(&corev1.ConfigMap{Data: map[string]string{
+		TimezoneKey:                "Europe/London",
+		SpecChangePolicyKey:        "Recreate",
+		StartingDeadlineSecondsKey: "600",
+		ApplyMutationsKey:          "true",
+	}})
+
+	assert.Nil(t, err, "should not return an error")
+	assert.Equal(t, "Europe/London", result.Timezone)
+	assert.Equal(t, batch.RecreateSpecChangePolicy, result.SpecChangePolicy)
+	assert.Equal(t, int64(600), *result.StartingDeadlineSeconds)
+	assert.True(t, *result.ApplyMutations)
+
+	result, err = FromConfigMap(&corev1.ConfigMap{})
+	assert.Nil(t, err, "should not return an error")
+	assert.Equal(t, Defaults{}, result, "an empty ConfigMap has no defaults")
+
+	for key, value := range map[string]string{
+		SpecChangePolicyKey:        "Restart",
+		StartingDeadlineSecondsKey: "ten minutes",
+		ApplyMutationsKey:          "yes please",
+	} {
+		_, err := FromConfigMap(&corev1.ConfigMap{Data: map[string]string{key: value}})
+		assert.NotNil(t, err, "should reject an invalid %s", key)
+	}
+}
+
+func Test_OverriddenBy(t *testing.T) {
+	clusterDeadline, namespaceDeadline := int64(600), int64(60)
+	applyMutations := true
+	cluster := Defaults{Timezone: "UTC", SpecChangePolicy: batch.IgnoreSpecChangePolicy, StartingDeadlineSeconds: &clusterDeadline, ApplyMutations: &applyMutations}
+	namespace := Defaults{Timezone: "Europe/London", StartingDeadlineSeconds: &namespaceDeadline}
+
+	result := cluster.OverriddenBy(namespace)
+
+	assert.Equal(t, Defaults{Timezone: "Europe/London", SpecChangePolicy: batch.IgnoreSpecChangePolicy, StartingDeadlineSeconds: &namespaceDeadline, ApplyMutations: &applyMutations}, result)
+}
+
+func Test_ApplyTo(t *testing.T) {
+	deadline := int64(600)
+	applyMutations := false
+	defaults := Defaults{Timezone: "Europe/London", SpecChangePolicy: batch.RecreateSpecChangePolicy, StartingDeadlineSeconds: &deadline, ApplyMutations: &applyMutations}
+
+	unset := &batch.ControlledJob{}
+	defaults.ApplyTo(unset)
+
+	assert.Equal(t, "Europe/London", unset.Spec.Timezone.Name)
+	assert.Equal(t, batch.RecreateSpecChangePolicy, unset.Spec.RestartStrategy.SpecChangePolicy)
+	assert.Equal(t, int64(600), *unset.Spec.StartingDeadlineSeconds)
+	assert.Equal(t, "false", unset.Annotations[metadata.ApplyMutationsAnnotation])
+
+	ownDeadline := int64(30)
+	set := &batch.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{metadata.ApplyMutationsAnnotation: "true"}},
+		Spec: batch.ControlledJobSpec{
+			Timezone:                batch.TimezoneSpec{Name: "America/New_York"},
+			RestartStrategy:         batch.RestartStrategy{SpecChangePolicy: batch.IgnoreSpecChangePolicy},
+			StartingDeadlineSeconds: &ownDeadline,
+		},
+	}
+	defaults.ApplyTo(set)
+
+	assert.Equal(t, "America/New_York", set.Spec.Timezone.Name, "should not override the timezone")
+	assert.Equal(t, batch.IgnoreSpecChangePolicy, set.Spec.RestartStrategy.SpecChangePolicy, "should not override the specChangePolicy")
+	assert.Equal(t, int64(30), *set.Spec.StartingDeadlineSeconds, "should not override the startingDeadlineSeconds")
+	assert.Equal(t, "true", set.Annotations[metadata.ApplyMutationsAnnotation], "should not override the apply-mutations annotation")
+}
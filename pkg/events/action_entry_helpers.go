@@ -22,6 +22,10 @@ func NewJobStartedAction(jobName string) *batch.ControlledJobActionHistoryEntry
 	return newActionForJob(string(EventJobStarted), fmt.Sprintf("Created job: %s", jobName), jobName)
 }
 
+func NewJobRestartedAction(jobName, reason string) *batch.ControlledJobActionHistoryEntry {
+	return newActionForJob(string(EventJobRestarted), fmt.Sprintf("Created job: %s (%s)", jobName, reason), jobName)
+}
+
 func NewJobSuspendedAction(jobName string) *batch.ControlledJobActionHistoryEntry {
 	return newActionForJob(string(EventJobSuspended), fmt.Sprintf("Suspended job: %s", jobName), jobName)
 }
@@ -45,6 +49,28 @@ func NewFailedAction(event WarningEvent, err error) *batch.ControlledJobActionHi
 	}
 }
 
+func NewAutoSuspendedAction(message string) *batch.ControlledJobActionHistoryEntry {
+	return &batch.ControlledJobActionHistoryEntry{
+		Type:      string(FailedRepeatedly),
+		Timestamp: timeOrNilIfZero(NowFunc()),
+		Message:   message,
+	}
+}
+
+// NewControlledJobActionAction records the outcome of a ControlledJobAction, once it has been carried out (or has
+// failed)
+func NewControlledJobActionAction(action *batch.ControlledJobAction) *batch.ControlledJobActionHistoryEntry {
+	eventType := string(EventActionCarriedOut)
+	if action.Status.Phase == batch.ActionPhaseFailed {
+		eventType = string(FailedToCarryOutAction)
+	}
+	message := fmt.Sprintf("%s requested by ControlledJobAction %s", action.Spec.Type, action.Name)
+	if action.Spec.Reason != "" {
+		message = fmt.Sprintf("%s (%s)", message, action.Spec.Reason)
+	}
+	return newActionForJob(eventType, fmt.Sprintf("%s: %s", message, action.Status.Message), action.Status.JobName)
+}
+
 func newActionForJob(eventType, message string, jobName string) *batch.ControlledJobActionHistoryEntry {
 	return &batch.ControlledJobActionHistoryEntry{
 		Type:      eventType,
@@ -8,6 +8,7 @@ import (
 
 	batch "github.com/G-Research/controlled-job/api/v1"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 /*
@@ -32,6 +33,17 @@ func Test_ActionEntriesSerializeCorrectly(t *testing.T) {
 	"timestamp": "` + now.Format(time.RFC3339) + `",
 	"message": "Created job: my-job",
 	"jobName": "my-job"
+}`,
+		},
+		"NewJobRestartedAction": {
+			ctor: func() *batch.ControlledJobActionHistoryEntry {
+				return NewJobRestartedAction("my-job", "scheduled restart")
+			},
+			expectedJson: `{
+	"type": "JobRestarted",
+	"timestamp": "` + now.Format(time.RFC3339) + `",
+	"message": "Created job: my-job (scheduled restart)",
+	"jobName": "my-job"
 }`,
 		},
 		"NewJobStoppedAction": {
@@ -64,6 +76,45 @@ func Test_ActionEntriesSerializeCorrectly(t *testing.T) {
 	"type": "FailedToCalculateDesiredStatus",
 	"timestamp": "` + now.Format(time.RFC3339) + `",
 	"message": "this is an error"
+}`,
+		},
+		"NewAutoSuspendedAction": {
+			ctor: func() *batch.ControlledJobActionHistoryEntry {
+				return NewAutoSuspendedAction("Suspended after 3 consecutive failed run periods")
+			},
+			expectedJson: `{
+	"type": "FailedRepeatedly",
+	"timestamp": "` + now.Format(time.RFC3339) + `",
+	"message": "Suspended after 3 consecutive failed run periods"
+}`,
+		},
+		"NewControlledJobActionAction": {
+			ctor: func() *batch.ControlledJobActionHistoryEntry {
+				return NewControlledJobActionAction(&batch.ControlledJobAction{
+					ObjectMeta: metav1.ObjectMeta{Name: "stop-for-release"},
+					Spec:       batch.ControlledJobActionSpec{ControlledJobName: "my-controlled-job", Type: batch.ActionTypeStop, Reason: "Release"},
+					Status:     batch.ControlledJobActionStatus{Phase: batch.ActionPhaseSucceeded, Message: "Stopped job: my-job", JobName: "my-job"},
+				})
+			},
+			expectedJson: `{
+	"type": "ActionCarriedOut",
+	"timestamp": "` + now.Format(time.RFC3339) + `",
+	"message": "stop requested by ControlledJobAction stop-for-release (Release): Stopped job: my-job",
+	"jobName": "my-job"
+}`,
+		},
+		"NewControlledJobActionAction when it failed": {
+			ctor: func() *batch.ControlledJobActionHistoryEntry {
+				return NewControlledJobActionAction(&batch.ControlledJobAction{
+					ObjectMeta: metav1.ObjectMeta{Name: "restart"},
+					Spec:       batch.ControlledJobActionSpec{ControlledJobName: "my-controlled-job", Type: batch.ActionTypeRestart},
+					Status:     batch.ControlledJobActionStatus{Phase: batch.ActionPhaseFailed, Message: "No job is running"},
+				})
+			},
+			expectedJson: `{
+	"type": "FailedToCarryOutAction",
+	"timestamp": "` + now.Format(time.RFC3339) + `",
+	"message": "restart requested by ControlledJobAction restart: No job is running"
 }`,
 		},
 	}
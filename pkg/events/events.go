@@ -10,17 +10,19 @@ type NormalEvent string
 type WarningEvent string
 
 const (
-	EventJobStarted     NormalEvent = "JobStarted"
-	EventJobStopped     NormalEvent = "JobStopped"
-	EventJobRestarted   NormalEvent = "JobRestarted"
-	EventJobSuspended   NormalEvent = "JobSuspended"
-	EventJobUnsuspended NormalEvent = "JobUnsuspended"
+	EventJobStarted       NormalEvent = "JobStarted"
+	EventJobStopped       NormalEvent = "JobStopped"
+	EventJobRestarted     NormalEvent = "JobRestarted"
+	EventJobSuspended     NormalEvent = "JobSuspended"
+	EventJobUnsuspended   NormalEvent = "JobUnsuspended"
+	EventActionCarriedOut NormalEvent = "ActionCarriedOut"
 
 	// All warning events must start with 'Failed'
 	FailedToReconcile              WarningEvent = "FailedToReconcile"
 	FailedToListJobs               WarningEvent = "FailedToListJobs"
 	FailedToListJobsForPeriod      WarningEvent = "FailedToListJobsForPeriod"
 	FailedToUpdateStatus           WarningEvent = "FailedToUpdateStatus"
+	FailedToGetCalendar            WarningEvent = "FailedToGetCalendar"
 	FailedToCalculateSchedule      WarningEvent = "FailedToCalculateSchedule"
 	FailedToCalculateDesiredStatus WarningEvent = "FailedToCalculateDesiredStatus"
 	FailedToTemplateJob            WarningEvent = "FailedToTemplateJob"
@@ -28,6 +30,8 @@ const (
 	FailedToDeleteJob              WarningEvent = "FailedToDeleteJob"
 	FailedToSuspendJob             WarningEvent = "FailedToSuspendJob"
 	FailedToUnsuspendJob           WarningEvent = "FailedToUnsuspendJob"
+	FailedRepeatedly               WarningEvent = "FailedRepeatedly"
+	FailedToCarryOutAction         WarningEvent = "FailedToCarryOutAction"
 )
 
 func IsWarningEvent(event string) bool {
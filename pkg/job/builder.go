@@ -36,10 +36,50 @@ func RecreateJobWithNewSpec(ctx context.Context, existingJob *kbatch.Job, contro
 	if err != nil {
 		return nil, err
 	}
-	return buildJob(ctx, controlledJob, oldScheduledTime, jobRunIdx, wasManuallyScheduled, startSuspended)
+	job, err := buildJob(ctx, controlledJob, oldScheduledTime, jobRunIdx, wasManuallyScheduled, startSuspended)
+	if err != nil {
+		return nil, err
+	}
+	// If the existing job was itself created by a restart, carry that over so we don't restart it again
+	if restartedAt, ok := existingJob.Annotations[metadata.RestartedAtAnnotation]; ok {
+		job.Annotations[metadata.RestartedAtAnnotation] = restartedAt
+	}
+	// The new job is in the same run period, so shares the existing job's budget of failure restarts
+	if failureRestartCount, ok := existingJob.Annotations[metadata.FailureRestartCountAnnotation]; ok {
+		job.Annotations[metadata.FailureRestartCountAnnotation] = failureRestartCount
+	}
+	return job, nil
+}
+
+// RestartJob builds the Job which replaces existingJob following a scheduled restart event at restartTime.
+// The new Job stays in the same run period as the existing Job, and records the restart it was created for
+// so that we don't restart it again
+func RestartJob(ctx context.Context, existingJob *kbatch.Job, controlledJob *batch.ControlledJob, jobRunIdx int, restartTime time.Time) (*kbatch.Job, error) {
+	job, err := RecreateJobWithNewSpec(ctx, existingJob, controlledJob, jobRunIdx, true)
+	if err != nil {
+		return nil, err
+	}
+	job.Annotations[metadata.RestartedAtAnnotation] = restartTime.Format(time.RFC3339)
+	return job, nil
+}
+
+// ReplaceFailedJob builds the Job which replaces failedJob in the same run period, recording how many failed
+// jobs have now been replaced in that run period
+func ReplaceFailedJob(ctx context.Context, failedJob *kbatch.Job, controlledJob *batch.ControlledJob, jobRunIdx int) (*kbatch.Job, error) {
+	job, err := RecreateJobWithNewSpec(ctx, failedJob, controlledJob, jobRunIdx, true)
+	if err != nil {
+		return nil, err
+	}
+	job.Annotations[metadata.FailureRestartCountAnnotation] = fmt.Sprintf("%d", metadata.GetFailureRestartCount(failedJob)+1)
+	return job, nil
 }
 
 func buildJob(ctx context.Context, controlledJob *batch.ControlledJob, scheduledTime time.Time, jobRunId int, isManuallyScheduled, startSuspended bool) (*kbatch.Job, error) {
+	if jobRunId > metadata.MaxJobRunId {
+		// The Job's name could be too long
+		return nil, fmt.Errorf("ControlledJob %s already has a Job with the largest allowed job run id, %d", controlledJob.Name, metadata.MaxJobRunId)
+	}
+
 	// We want job names for a given nominal start time to have a deterministic name to avoid the same job being created twice
 	name := metadata.JobName(controlledJob.Name, scheduledTime, jobRunId)
 
@@ -62,11 +102,12 @@ func buildJob(ctx context.Context, controlledJob *batch.ControlledJob, scheduled
 	job.Annotations[metadata.ScheduledTimeAnnotation] = scheduledTime.Format(time.RFC3339)
 	job.Annotations[metadata.JobRunIdAnnotation] = fmt.Sprintf("%d", jobRunId)
 	job.Annotations[metadata.TemplateHashAnnotation] = metadata.CalculateHashFor(controlledJob.Spec.JobTemplate)
-	if len(controlledJob.Spec.Timezone.Name) > 0 {
-		job.Annotations[metadata.TimeZoneAnnotation] = controlledJob.Spec.Timezone.Name
+	timezone := startEventsTimezone(controlledJob)
+	if len(timezone.Name) > 0 {
+		job.Annotations[metadata.TimeZoneAnnotation] = timezone.Name
 	}
-	if controlledJob.Spec.Timezone.OffsetSeconds != 0 {
-		job.Annotations[metadata.TimeZoneOffsetSecondsAnnotation] = fmt.Sprintf("%d", controlledJob.Spec.Timezone.OffsetSeconds)
+	if timezone.OffsetSeconds != 0 {
+		job.Annotations[metadata.TimeZoneOffsetSecondsAnnotation] = fmt.Sprintf("%d", timezone.OffsetSeconds)
 	}
 	if isManuallyScheduled {
 		job.Annotations[metadata.ManualJobAnnotation] = "true"
@@ -93,3 +134,27 @@ func buildJob(ctx context.Context, controlledJob *batch.ControlledJob, scheduled
 
 	return job, nil
 }
+
+// startEventsTimezone is the timezone in which the ControlledJob's start events are scheduled. If the start events
+// all override the ControlledJob's timezone with the same timezone of their own, then that's the timezone which
+// determined the Job's scheduled time. Otherwise, we fall back to the ControlledJob's timezone
+func startEventsTimezone(controlledJob *batch.ControlledJob) batch.TimezoneSpec {
+	var result *batch.TimezoneSpec
+	for _, event := range controlledJob.Spec.Events {
+		if event.Action != batch.EventTypeStart {
+			continue
+		}
+		if event.Timezone == nil || (result != nil && !sameTimezone(*result, *event.Timezone)) {
+			return controlledJob.Spec.Timezone
+		}
+		result = event.Timezone
+	}
+	if result == nil {
+		return controlledJob.Spec.Timezone
+	}
+	return *result
+}
+
+func sameTimezone(a, b batch.TimezoneSpec) bool {
+	return a.Name == b.Name && a.OffsetSeconds == b.OffsetSeconds
+}
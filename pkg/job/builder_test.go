@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,3 +105,89 @@ func Test_BuildForControlledJob(t *testing.T) {
 		})
 	}
 }
+
+func Test_BuildForControlledJob_TimeZoneAnnotation(t *testing.T) {
+	scheduledTime := time.Date(2022, 1, 14, 15, 9, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		controlledJob    *batch.ControlledJob
+		expectedTimeZone string
+	}{
+		"Uses the ControlledJob's timezone": {
+			controlledJob: NewControlledJob("cj",
+				WithTimezone("Europe/London", 0),
+				WithCronEvent(batch.EventTypeStart, "0 9 * * *")),
+			expectedTimeZone: "Europe/London",
+		},
+		"Uses the timezone of the start events": {
+			controlledJob: NewControlledJob("cj",
+				WithTimezone("Europe/London", 0),
+				WithCronEventInTimezone(batch.EventTypeStart, "0 9 * * MON-THU", "America/New_York"),
+				WithCronEventInTimezone(batch.EventTypeStart, "0 8 * * FRI", "America/New_York"),
+				WithCronEventInTimezone(batch.EventTypeStop, "0 17 * * *", "Asia/Tokyo")),
+			expectedTimeZone: "America/New_York",
+		},
+		"Uses the ControlledJob's timezone if the start events have different timezones": {
+			controlledJob: NewControlledJob("cj",
+				WithTimezone("Europe/London", 0),
+				WithCronEventInTimezone(batch.EventTypeStart, "0 9 * * MON-THU", "America/New_York"),
+				WithCronEvent(batch.EventTypeStart, "0 8 * * FRI")),
+			expectedTimeZone: "Europe/London",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actualJob, actualErr := BuildForControlledJob(context.Background(), tc.controlledJob, scheduledTime, 0, false, false)
+
+			assert.Nil(t, actualErr, "should not return an error")
+			assert.Equal(t, tc.expectedTimeZone, actualJob.Annotations[metadata.TimeZoneAnnotation])
+		})
+	}
+}
+
+func Test_RecreatedJobsKeepTheirFailureRestartCount(t *testing.T) {
+	scheduledTime := time.Date(2022, 1, 14, 15, 9, 0, 0, time.UTC)
+	restartTime := time.Date(2022, 1, 14, 16, 0, 0, 0, time.UTC)
+	controlledJob := NewControlledJob("cj")
+	existingJob := NewJob("cj-1642172940-2",
+		metadata.WithControlledJobAnnotations(scheduledTime, 2, false, v1beta1.JobTemplateSpec{}),
+		metadata.WithFailureRestartCount(2))
+
+	testCases := map[string]func() (*kbatch.Job, error){
+		"RecreateJobWithNewSpec": func() (*kbatch.Job, error) {
+			return RecreateJobWithNewSpec(context.Background(), existingJob, controlledJob, 3, true)
+		},
+		"RestartJob": func() (*kbatch.Job, error) {
+			return RestartJob(context.Background(), existingJob, controlledJob, 3, restartTime)
+		},
+	}
+
+	for name, build := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actualJob, actualErr := build()
+
+			assert.Nil(t, actualErr, "should not return an error")
+			assert.Equal(t, 2, metadata.GetFailureRestartCount(actualJob), "should carry over the failure restart count")
+		})
+	}
+
+	t.Run("ReplaceFailedJob", func(t *testing.T) {
+		actualJob, actualErr := ReplaceFailedJob(context.Background(), existingJob, controlledJob, 3)
+
+		assert.Nil(t, actualErr, "should not return an error")
+		assert.Equal(t, 3, metadata.GetFailureRestartCount(actualJob), "should count the failed job")
+	})
+}
+
+func Test_BuildForControlledJob_LargestJobRunId(t *testing.T) {
+	scheduledTime := time.Date(2022, 1, 14, 15, 9, 0, 0, time.UTC)
+	controlledJob := NewControlledJob(strings.Repeat("a", 63-len("-1642172940-999")))
+
+	actualJob, actualErr := BuildForControlledJob(context.Background(), controlledJob, scheduledTime, metadata.MaxJobRunId, false, false)
+	assert.Nil(t, actualErr, "should not return an error")
+	assert.Len(t, actualJob.Name, metadata.MaxJobNameLength)
+
+	_, actualErr = BuildForControlledJob(context.Background(), controlledJob, scheduledTime, metadata.MaxJobRunId+1, false, false)
+	assert.NotNil(t, actualErr, "should not build a Job with a run id which might make its name too long")
+}
@@ -8,8 +8,11 @@ import (
 
 var (
 	JobOwnerKey                     = ".metadata.controller"
+	CalendarRefKey                  = ".spec.calendarRef"
 	ApiGVStr                        = batch.GroupVersion.String()
 	ScheduledTimeAnnotation         = fmt.Sprintf("%s/scheduled-at", batch.GroupVersion.Group)
+	RestartedAtAnnotation           = fmt.Sprintf("%s/restarted-at", batch.GroupVersion.Group)
+	FailureRestartCountAnnotation   = fmt.Sprintf("%s/failure-restart-count", batch.GroupVersion.Group)
 	JobRunIdAnnotation              = fmt.Sprintf("%s/job-run-id", batch.GroupVersion.Group)
 	ControlledJobLabel              = fmt.Sprintf("%s/controlled-job", batch.GroupVersion.Group)
 	ManualJobAnnotation             = fmt.Sprintf("%s/is-manually-scheduled", batch.GroupVersion.Group)
@@ -18,4 +21,9 @@ var (
 	ApplyMutationsAnnotation        = fmt.Sprintf("%s/apply-mutations", batch.GroupVersion.Group)
 	TimeZoneAnnotation              = fmt.Sprintf("%s/timezone", batch.GroupVersion.Group)
 	TimeZoneOffsetSecondsAnnotation = fmt.Sprintf("%s/timezone-offset-seconds", batch.GroupVersion.Group)
+	ActionAnnotation                = fmt.Sprintf("%s/controlled-job-action", batch.GroupVersion.Group)
 )
+
+// UserStopSuspendReason is the SuspendReason of a Job which has been stopped by the user. It stays suspended until
+// the end of its run period
+const UserStopSuspendReason = "user-stop"
@@ -68,7 +68,7 @@ func IsJobSuspended(job *kbatch.Job) bool {
 
 func WasJobStoppedByTheUser(job *kbatch.Job) bool {
 	suspendReason := job.Annotations[SuspendReason]
-	return IsJobSuspended(job) && suspendReason == "user-stop"
+	return IsJobSuspended(job) && suspendReason == UserStopSuspendReason
 }
 
 func JobHasReadyStatus(job *kbatch.Job) bool {
@@ -120,6 +120,40 @@ func GetScheduledTime(job *kbatch.Job) (time.Time, error) {
 	return timeParsed, nil
 }
 
+// GetRestartedAtTime returns the time of the scheduled restart event that this job was created in response to.
+// Returns nil if the job was not created by a restart
+func GetRestartedAtTime(job *kbatch.Job) (*time.Time, error) {
+	timeRaw := job.Annotations[RestartedAtAnnotation]
+	if len(timeRaw) == 0 {
+		return nil, nil
+	}
+
+	timeParsed, err := time.Parse(time.RFC3339, timeRaw)
+	if err != nil {
+		return nil, err
+	}
+	return &timeParsed, nil
+}
+
+// GetFailureRestartCount returns how many times a failed job has been replaced in the run period to get to this job.
+// Returns 0 for jobs that weren't created to replace a failed job
+func GetFailureRestartCount(job *kbatch.Job) int {
+	count, err := strconv.Atoi(job.Annotations[FailureRestartCountAnnotation])
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// GetFailureTime returns the time the job was marked as failed, or nil if it hasn't failed
+func GetFailureTime(job *kbatch.Job) *time.Time {
+	condition := GetJobCondition(job, kbatch.JobFailed)
+	if condition == nil {
+		return nil
+	}
+	return &condition.LastTransitionTime.Time
+}
+
 func GetJobRunId(job *kbatch.Job) (int, error) {
 	idxRaw := job.Annotations[JobRunIdAnnotation]
 	if len(idxRaw) == 0 {
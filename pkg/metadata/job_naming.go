@@ -18,6 +18,28 @@ func JobName(controlledJobName string, scheduledTime time.Time, jobRunId int) st
 	return fmt.Sprintf("%s-%d-%d", controlledJobName, scheduledTime.Unix(), jobRunId)
 }
 
+// MaxJobNameLength is the longest a Job's name can be. Kubernetes allows longer names for Jobs themselves, but the
+// Job controller copies the name into a label on each of the Job's Pods, and label values are limited to 63
+// characters
+const MaxJobNameLength = 63
+
+// MaxJobRunId is the largest job run id a Job can have, which ValidateControlledJobName leaves room for in Job names.
+// Restarting, replacing or recreating a Job gives the new Job the next run id, so once a ControlledJob's Jobs reach it
+// the reconciler stops doing so until the next run period, whose first Job starts again at run id 0
+const MaxJobRunId = 999
+
+// ValidateControlledJobName returns an error if the names of the Jobs created for a ControlledJob with the given
+// name could be too long
+func ValidateControlledJobName(controlledJobName string) error {
+	// Unix times have 10 digits until the year 2286
+	longestJobName := JobName(controlledJobName, time.Unix(9999999999, 0), MaxJobRunId)
+	if len(longestJobName) > MaxJobNameLength {
+		return fmt.Errorf("must be no more than %d characters, so that the names of its Jobs (e.g. %s) are no more than %d characters",
+			len(controlledJobName)-(len(longestJobName)-MaxJobNameLength), longestJobName, MaxJobNameLength)
+	}
+	return nil
+}
+
 func ParseJobName(jobName string) (controlledJobName string, scheduledTime *time.Time, jobRunId *int, err error) {
 	matches := jobNameRegex.FindStringSubmatch(jobName)
 	if matches == nil {
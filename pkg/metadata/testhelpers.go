@@ -52,3 +52,15 @@ func WithJobRunIdx(idx int) testhelpers.JobOption {
 		job.Annotations[JobRunIdAnnotation] = fmt.Sprintf("%d", idx)
 	}
 }
+
+func WithRestartedAtAnnotation(restartedAt time.Time) testhelpers.JobOption {
+	return func(job *kbatch.Job) {
+		job.Annotations[RestartedAtAnnotation] = restartedAt.Format(time.RFC3339)
+	}
+}
+
+func WithFailureRestartCount(count int) testhelpers.JobOption {
+	return func(job *kbatch.Job) {
+		job.Annotations[FailureRestartCountAnnotation] = fmt.Sprintf("%d", count)
+	}
+}
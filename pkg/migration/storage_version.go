@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update;patch
+
+// ControlledJobsCRDName is the name of the ControlledJob CustomResourceDefinition
+const ControlledJobsCRDName = "controlledjobs.batch.gresearch.co.uk"
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// StorageVersionMigrator rewrites every ControlledJob, so that the API server stores them all in the CRD's
+// storage version, and then removes every other version from the CRD's status.storedVersions. After that, older
+// versions can safely be dropped from the CRD
+type StorageVersionMigrator struct {
+	client.Client
+}
+
+var _ manager.LeaderElectionRunnable = &StorageVersionMigrator{}
+
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start migrates the ControlledJobs once. Failures are logged rather than returned, since the operator works
+// whichever version the ControlledJobs are stored in. The migration is tried again next time the operator starts
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	if err := m.Migrate(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "failed to migrate ControlledJobs to the storage version")
+	}
+	return nil
+}
+
+// Migrate rewrites every ControlledJob in the storage version, then updates the CRD's status.storedVersions
+func (m *StorageVersionMigrator) Migrate(ctx context.Context) error {
+	crd, err := m.getCRD(ctx)
+	if err != nil {
+		return err
+	}
+	storageVersion, err := storageVersionOf(crd)
+	if err != nil {
+		return err
+	}
+	storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if err != nil {
+		return errors.Wrap(err, "invalid storedVersions in the ControlledJob CRD")
+	}
+	if len(storedVersions) == 1 && storedVersions[0] == storageVersion {
+		log.FromContext(ctx).Info("ControlledJobs are already all stored in the storage version", "storageVersion", storageVersion)
+		return nil
+	}
+
+	var controlledJobs batch.ControlledJobList
+	if err := m.List(ctx, &controlledJobs); err != nil {
+		return errors.Wrap(err, "failed to list ControlledJobs")
+	}
+	failed := 0
+	for i := range controlledJobs.Items {
+		key := client.ObjectKeyFromObject(&controlledJobs.Items[i])
+		if err := m.rewrite(ctx, key); err != nil {
+			log.FromContext(ctx).Error(err, "failed to migrate ControlledJob", "name", key.Name, "namespace", key.Namespace)
+			failed++
+		}
+	}
+	if failed > 0 {
+		// Some ControlledJobs may still be stored in an older version, so it mustn't be removed from storedVersions
+		return errors.Errorf("failed to migrate %d of %d ControlledJobs", failed, len(controlledJobs.Items))
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crd, err := m.getCRD(ctx)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedStringSlice(crd.Object, []string{storageVersion}, "status", "storedVersions"); err != nil {
+			return err
+		}
+		return m.Status().Update(ctx, crd)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update storedVersions in the ControlledJob CRD")
+	}
+	log.FromContext(ctx).Info("migrated ControlledJobs to the storage version", "storageVersion", storageVersion, "previouslyStoredVersions", storedVersions, "count", len(controlledJobs.Items))
+	return nil
+}
+
+// rewrite updates a ControlledJob without changing it. The API server still writes it, in the storage version
+func (m *StorageVersionMigrator) rewrite(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var controlledJob batch.ControlledJob
+		if err := m.Get(ctx, key, &controlledJob); err != nil {
+			// A ControlledJob which has been deleted since it was listed doesn't need migrating
+			return client.IgnoreNotFound(err)
+		}
+		return m.Update(ctx, &controlledJob)
+	})
+}
+
+func (m *StorageVersionMigrator) getCRD(ctx context.Context) (*unstructured.Unstructured, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Get(ctx, types.NamespacedName{Name: ControlledJobsCRDName}, crd); err != nil {
+		return nil, errors.Wrap(err, "failed to get the ControlledJob CRD")
+	}
+	return crd, nil
+}
+
+func storageVersionOf(crd *unstructured.Unstructured) (string, error) {
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return "", errors.Wrap(err, "invalid versions in the ControlledJob CRD")
+	}
+	for _, version := range versions {
+		version, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if storage, _, _ := unstructured.NestedBool(version, "storage"); storage {
+			name, _, _ := unstructured.NestedString(version, "name")
+			return name, nil
+		}
+	}
+	return "", errors.New("the ControlledJob CRD has no storage version")
+}
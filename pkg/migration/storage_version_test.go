@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/k8s"
+)
+
+func controlledJobsCRD(storedVersions ...interface{}) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": ControlledJobsCRDName},
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "served": true, "storage": false},
+				map[string]interface{}{"name": "v2", "served": true, "storage": true},
+			},
+		},
+		"status": map[string]interface{}{"storedVersions": storedVersions},
+	}}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+func Test_StorageVersionMigrator(t *testing.T) {
+	crd := controlledJobsCRD("v1", "v2")
+	first := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "first"}}
+	second := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "second"}}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(k8s.GetScheme()).
+		WithObjects(crd, first, second).
+		WithStatusSubresource(crd).
+		Build()
+	sut := &StorageVersionMigrator{Client: fakeClient}
+
+	err := sut.Migrate(context.Background())
+
+	assert.Nil(t, err, "should not return an error")
+	for _, original := range []*batch.ControlledJob{first, second} {
+		var migrated batch.ControlledJob
+		assert.Nil(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(original), &migrated))
+		assert.NotEqual(t, original.ResourceVersion, migrated.ResourceVersion, "%s should have been rewritten", original.Name)
+	}
+	updated, err := sut.getCRD(context.Background())
+	assert.Nil(t, err)
+	storedVersions, _, _ := unstructured.NestedStringSlice(updated.Object, "status", "storedVersions")
+	assert.Equal(t, []string{"v2"}, storedVersions)
+}
+
+func Test_StorageVersionMigrator_AlreadyMigrated(t *testing.T) {
+	controlledJob := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "first"}}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(k8s.GetScheme()).
+		WithObjects(controlledJobsCRD("v2"), controlledJob).
+		Build()
+	sut := &StorageVersionMigrator{Client: fakeClient}
+
+	err := sut.Migrate(context.Background())
+
+	assert.Nil(t, err, "should not return an error")
+	var unchanged batch.ControlledJob
+	assert.Nil(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(controlledJob), &unchanged))
+	assert.Equal(t, controlledJob.ResourceVersion, unchanged.ResourceVersion, "should not rewrite ControlledJobs which have already been migrated")
+}
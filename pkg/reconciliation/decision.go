@@ -2,6 +2,8 @@ package reconciliation
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -20,11 +22,50 @@ import (
 )
 
 type Decision struct {
-	JobsToCreate    []*kbatch.Job
+	JobsToCreate []*kbatch.Job
+	// JobsToRestart records which of JobsToCreate replace an existing job in the same run period, and why
+	JobsToRestart   map[*kbatch.Job]string
 	JobsToDelete    []*kbatch.Job
 	JobsToSuspend   []*kbatch.Job
 	JobsToUnsuspend []*kbatch.Job
 	RequeueAt       time.Time
+	// AutoSuspendMessage is set if we have just suspended the ControlledJob because of repeated failures
+	AutoSuspendMessage string
+	// Reasons says, for each Job by name, why it's being created, deleted, suspended, unsuspended or left alone. It
+	// covers all the existing Jobs and all of JobsToCreate
+	Reasons map[string]string
+}
+
+const (
+	restartReasonScheduled = "scheduled restart"
+	restartReasonFailure   = "restart after failure"
+)
+
+func (d *Decision) addRestart(job *kbatch.Job, reason string) {
+	if d.JobsToRestart == nil {
+		d.JobsToRestart = make(map[*kbatch.Job]string)
+	}
+	d.JobsToCreate = append(d.JobsToCreate, job)
+	d.JobsToRestart[job] = reason
+}
+
+func (d *Decision) restartReason(job *kbatch.Job) (reason string, isRestart bool) {
+	reason, isRestart = d.JobsToRestart[job]
+	return
+}
+
+// because records why the decision for the job is what it is, replacing any earlier reason
+func (d *Decision) because(job *kbatch.Job, format string, args ...interface{}) {
+	if d.Reasons == nil {
+		d.Reasons = make(map[string]string)
+	}
+	d.Reasons[job.Name] = fmt.Sprintf(format, args...)
+}
+
+// isExplained returns true if a reason has already been recorded for the job
+func (d *Decision) isExplained(job *kbatch.Job) bool {
+	_, ok := d.Reasons[job.Name]
+	return ok
 }
 
 func (d *Decision) AddToLog(log logr.Logger) logr.Logger {
@@ -43,9 +84,16 @@ func (d *Decision) AddToLog(log logr.Logger) logr.Logger {
 		WithValues("requeueAt", d.RequeueAt)
 }
 
-func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJobs *kbatch.JobList, now time.Time, enableAutoRecreateJobsOnSpecChange bool) (decision Decision, err error) {
+// Plan works out what the reconciler would do with the ControlledJob and its Jobs at the given time, and why, without
+// changing anything in the cluster. Like the reconciler, it updates the ControlledJob's status, so pass in a copy to
+// keep the original
+func Plan(ctx context.Context, controlledJob *v1.ControlledJob, calendar *v1.CalendarSpec, childJobs *kbatch.JobList, now time.Time, options ReconcileOptions) (Decision, error) {
+	return makeDecision(ctx, controlledJob, calendar, childJobs, now, options.EnableAutoRecreateJobsOnSpecChange)
+}
+
+func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, calendar *v1.CalendarSpec, childJobs *kbatch.JobList, now time.Time, enableAutoRecreateJobsOnSpecChange bool) (decision Decision, err error) {
 	var state *state
-	state, err = buildState(ctx, controlledJob, childJobs, now)
+	state, err = buildState(ctx, controlledJob, calendar, childJobs, now)
 	if err != nil {
 		return
 	}
@@ -55,7 +103,8 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 		WithValues("namespace", controlledJob.Namespace).
 		WithValues("childJobsCount", len(state.AllJobs))
 
-	controlledJob.Status.IsSuspended = &state.IsSuspended
+	isSuspended := state.IsSuspended || state.IsAutoSuspended
+	controlledJob.Status.IsSuspended = &isSuspended
 	var lastScheduledStateTime *metav1.Time = nil
 	if state.StartOfCurrentRunPeriod != nil {
 		t := metav1.NewTime(*state.StartOfCurrentRunPeriod)
@@ -64,6 +113,7 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	controlledJob.Status.LastScheduledStartTime = lastScheduledStateTime
 
 	setShouldBeRunningStatus(controlledJob, state)
+	setScheduleWarningsCondition(controlledJob, state.ScheduleWarnings)
 	shouldBeRunning := false
 	if state.ShouldBeRunning != nil {
 		shouldBeRunning = *state.ShouldBeRunning
@@ -73,11 +123,27 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	if state.IsSuspended {
 		log.V(1).Info("ControlledJob is suspended, deleting any running jobs")
 		decision.JobsToDelete = state.AllJobs
+		for _, job := range state.AllJobs {
+			decision.because(job, "the ControlledJob is suspended")
+		}
 		v1.SetCondition(controlledJob, v1.ConditionTypeSuspended, metav1.ConditionTrue, "Suspended", "IsSuspended flag set")
 		// We're suspended, so nothing more to do
 		return
 	}
+	if state.IsAutoSuspended {
+		log.V(1).Info("ControlledJob was suspended by the controller, deleting any running jobs",
+			"reason", controlledJob.Status.AutoSuspended.Reason)
+		decision.JobsToDelete = state.AllJobs
+		for _, job := range state.AllJobs {
+			decision.because(job, "the ControlledJob was suspended by the controller: %s", controlledJob.Status.AutoSuspended.Message)
+		}
+		v1.SetCondition(controlledJob, v1.ConditionTypeSuspended, metav1.ConditionTrue, "AutoSuspended", "Suspended by the controller")
+		v1.SetCondition(controlledJob, v1.ConditionTypeAutoSuspended, metav1.ConditionTrue, controlledJob.Status.AutoSuspended.Reason, controlledJob.Status.AutoSuspended.Message)
+		// We're suspended, so nothing more to do
+		return
+	}
 	v1.SetCondition(controlledJob, v1.ConditionTypeSuspended, metav1.ConditionFalse, "NotSuspended", "IsSuspended flag not set")
+	v1.SetCondition(controlledJob, v1.ConditionTypeAutoSuspended, metav1.ConditionFalse, "NotAutoSuspended", "Not suspended by the controller")
 
 	// Gate restart on spec changes on both env var and spec change policy
 	restartOnSpecChange := enableAutoRecreateJobsOnSpecChange && state.AutoRestartIsEnabled
@@ -104,6 +170,10 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 		}
 	}
 
+	// A new job run id needs room in the Job's name, so once the largest one has been used no more Jobs can be
+	// restarted, replaced or recreated until the next run period
+	jobRunIdsExhausted := maxJobRunId >= metadata.MaxJobRunId
+
 	setConditionsForAllJobs(controlledJob, state.AllJobs)
 	setJobConditions(controlledJob, jobToRecordMetricsAgainst)
 
@@ -119,6 +189,7 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	numberOfPotentiallyRunningJobs := 0
 	expiredJobs := []*kbatch.Job{}
 	nonExpiredJobs := []*kbatch.Job{}
+	previousRunPeriodJobs := []*kbatch.Job{}
 	controlledJob.Status.Active = make([]corev1.ObjectReference, 0)
 	for _, job := range state.AllJobs {
 
@@ -133,6 +204,27 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 			}
 		}
 
+		/*
+		 * Is the job from an earlier run period of a start-only schedule?
+		 *
+		 * There are no stop events, so what happens to these jobs depends on the
+		 * ConcurrencyPolicy (see below)
+		 */
+		if state.IsStartOnly && state.StartOfCurrentRunPeriod != nil {
+			jobStartTime, err := metadata.GetScheduledTime(job)
+			if err != nil {
+				err = errors.Wrap(err, "Could not determine start time of job - this is invalid and should not happen. Will delete it.")
+				log.V(1).Error(err, "", "job", job.Name)
+				expiredJobs = append(expiredJobs, job)
+				decision.because(job, "its scheduled time can't be read: %v", err)
+				continue
+			}
+			if jobStartTime.Before(*state.StartOfCurrentRunPeriod) {
+				previousRunPeriodJobs = append(previousRunPeriodJobs, job)
+				continue
+			}
+		}
+
 		/*
 		 * Is the job expired (we've passed its stop time)?
 		 *
@@ -148,11 +240,14 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 				err = errors.Wrap(err, "Could not determine start time of job - this is invalid and should not happen. Will delete it.")
 				log.V(1).Error(err, "", "job", job.Name)
 				expiredJobs = append(expiredJobs, job)
+				decision.because(job, "its scheduled time can't be read: %v", err)
 				continue
 			}
 			if jobStartTime.Before(*state.LastStopTime) {
 				log.V(1).Info("Job is expired. Will delete it.", "job", job.Name, "jobStartTime", jobStartTime, "lastScheduledStopTime", state.LastStopTime)
 				expiredJobs = append(expiredJobs, job)
+				decision.because(job, "it has expired: it was scheduled at %s, before the last stop event at %s",
+					jobStartTime.Format(time.RFC3339), state.LastStopTime.Format(time.RFC3339))
 				continue
 			}
 		}
@@ -166,6 +261,34 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 		}
 	}
 
+	/*
+	 * Jobs from earlier run periods of a start-only schedule
+	 *
+	 * Completed jobs are cleaned up. Jobs which may still be running are stopped if the ConcurrencyPolicy is Replace,
+	 * and otherwise left to run to completion. With Allow, the new job is allowed to run alongside them, so we don't
+	 * count them when deciding if it's safe to unsuspend the new job. With Forbid, we don't start a new job at all until
+	 * they have finished
+	 */
+	earlierJobStillRunning := false
+	for _, job := range previousRunPeriodJobs {
+		if metadata.IsJobCompleted(job) || state.ConcurrencyPolicy == v1.ReplaceConcurrent {
+			log.V(1).Info("Job is from an earlier run period. Will delete it.", "job", job.Name, "concurrencyPolicy", state.ConcurrencyPolicy)
+			expiredJobs = append(expiredJobs, job)
+			if metadata.IsJobCompleted(job) {
+				decision.because(job, "it's from an earlier run period, and has finished")
+			} else {
+				decision.because(job, "it's from an earlier run period, and the concurrencyPolicy is Replace")
+			}
+			continue
+		}
+		earlierJobStillRunning = true
+		decision.because(job, "it's from an earlier run period and may still be running, so the concurrencyPolicy of %s leaves it to finish",
+			state.ConcurrencyPolicy)
+		if state.ConcurrencyPolicy == v1.AllowConcurrent {
+			numberOfPotentiallyRunningJobs--
+		}
+	}
+
 	/*
 	 * Job is current, but the schedule says we shouldn't be running
 	 *
@@ -177,8 +300,131 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	isNotManuallyScheduled := chosenJob != nil && !metadata.IsManuallyScheduledJob(chosenJob)
 	if shouldBeStopped && isNotManuallyScheduled {
 		log.V(1).Info("We expect to be stopped but found a non-manually scheduled job. Will delete it", "job", chosenJob.Name)
+		decision.because(chosenJob, "we're outside a run period, and it wasn't manually scheduled")
 		// Setting chosenJob to nil means that this job will be added to the JobsToDelete list at the end of this method
 		chosenJob = nil
+	} else if shouldBeStopped && chosenJob != nil {
+		decision.because(chosenJob, "it was manually scheduled, so it's kept although we're outside a run period")
+	}
+
+	/*
+	 * Job was started before the most recent restart event in the schedule
+	 *
+	 * In this case we replace it with a fresh job in the same run period. The new job is created
+	 * suspended, and will only be unsuspended once the old job has fully terminated (see below)
+	 */
+	if chosenJob != nil && needsRestart(chosenJob, state) && jobRunIdsExhausted {
+		log.V(1).Info("Job was started before the most recent restart event, but the largest job run id has been used so will not replace it",
+			"job", chosenJob.Name, "lastRestartTime", state.LastRestartTime, "maxJobRunId", metadata.MaxJobRunId)
+		decision.because(chosenJob, "it started before the restart event at %s, but it can't be replaced as the largest job run id, %d, has been used", state.LastRestartTime.Format(time.RFC3339), metadata.MaxJobRunId)
+	} else if chosenJob != nil && needsRestart(chosenJob, state) {
+		log.V(1).Info("Job was started before the most recent restart event, will replace it with a new job",
+			"job", chosenJob.Name, "lastRestartTime", state.LastRestartTime)
+
+		newJob, e := jobpkg.RestartJob(ctx, chosenJob, controlledJob, maxJobRunId+1, *state.LastRestartTime)
+		if e != nil {
+			err = errors.Wrap(e, "Failed to create job")
+			return
+		}
+		decision.addRestart(newJob, restartReasonScheduled)
+		decision.because(chosenJob, "it started before the restart event at %s, so it's replaced by %s", state.LastRestartTime.Format(time.RFC3339), newJob.Name)
+		decision.because(newJob, "it replaces %s, which started before the restart event at %s", chosenJob.Name, state.LastRestartTime.Format(time.RFC3339))
+		numberOfPotentiallyRunningJobs++
+		nonExpiredJobs = append(nonExpiredJobs, newJob)
+		chosenJob = newJob
+	}
+
+	/*
+	 * Job failed inside the run period, and the FailurePolicy says we should replace it
+	 *
+	 * We wait for an exponential backoff (measured from when the job failed) before creating the new job, and stop
+	 * replacing failed jobs once the restart budget for the run period is used up
+	 */
+	var failureRestartAt *time.Time = nil
+	restartBudgetExhausted := false
+	if shouldBeRunning && chosenJob != nil && state.FailurePolicy == v1.AlwaysRestartFailurePolicy && isFailedJobToReplace(chosenJob) {
+		restartCount := metadata.GetFailureRestartCount(chosenJob)
+		restartAt := metadata.GetFailureTime(chosenJob).Add(failureBackoff(state, restartCount))
+		if state.MaxRestartsPerRunPeriod != nil && restartCount >= int(*state.MaxRestartsPerRunPeriod) {
+			log.V(1).Info("Job failed, but the restart budget for this run period is exhausted so will not replace it",
+				"job", chosenJob.Name, "restartCount", restartCount)
+			restartBudgetExhausted = true
+			decision.because(chosenJob, "it failed, but the %d restarts allowed in this run period have been used up", restartCount)
+		} else if jobRunIdsExhausted {
+			log.V(1).Info("Job failed, but the largest job run id has been used so will not replace it",
+				"job", chosenJob.Name, "restartCount", restartCount, "maxJobRunId", metadata.MaxJobRunId)
+			restartBudgetExhausted = true
+			decision.because(chosenJob, "it failed, but it can't be replaced as the largest job run id, %d, has been used", metadata.MaxJobRunId)
+		} else if now.Before(restartAt) {
+			log.V(1).Info("Job failed, will replace it once the backoff has passed",
+				"job", chosenJob.Name, "restartCount", restartCount, "restartAt", restartAt)
+			failureRestartAt = &restartAt
+			decision.because(chosenJob, "it failed, and will be replaced at %s once the backoff has passed", restartAt.Format(time.RFC3339))
+		} else {
+			log.V(1).Info("Job failed, will replace it with a new job", "job", chosenJob.Name, "restartCount", restartCount)
+
+			newJob, e := jobpkg.ReplaceFailedJob(ctx, chosenJob, controlledJob, maxJobRunId+1)
+			if e != nil {
+				err = errors.Wrap(e, "Failed to create job")
+				return
+			}
+			decision.addRestart(newJob, restartReasonFailure)
+			decision.because(chosenJob, "it failed, so it's replaced by %s", newJob.Name)
+			decision.because(newJob, "it replaces the failed job %s", chosenJob.Name)
+			numberOfPotentiallyRunningJobs++
+			nonExpiredJobs = append(nonExpiredJobs, newJob)
+			chosenJob = newJob
+		}
+	}
+	if shouldBeRunning && chosenJob != nil && state.FailurePolicy == v1.NeverRestartFailurePolicy && isFailedJobToReplace(chosenJob) {
+		decision.because(chosenJob, "it failed, and the failurePolicy is NeverRestart, so it's left in place until the end of the run period")
+	}
+	v1.SetConditionBasedOnFlag(controlledJob, v1.ConditionTypeRestartBudgetExhausted, restartBudgetExhausted,
+		"RestartBudgetExhausted", "The current job failed, but the maximum number of restarts for this run period has been reached",
+		"RestartBudgetNotExhausted", "No failed job is waiting on the restart budget")
+
+	/*
+	 * Job failed and won't be replaced, so this run period has failed
+	 *
+	 * If the user has asked us to, we count consecutive failed run periods, and suspend the ControlledJob once
+	 * there have been too many. This is recorded in the status (not spec.suspend) so that the user has to
+	 * explicitly clear it
+	 */
+	if controlledJob.Spec.SuspendAfterFailedRunPeriods != nil && state.StartOfCurrentRunPeriod != nil {
+		runPeriodFailed := shouldBeRunning &&
+			chosenJob != nil &&
+			metadata.JobHasCondition(chosenJob, kbatch.JobFailed) &&
+			!metadata.IsJobBeingDeleted(chosenJob) &&
+			failureRestartAt == nil
+		newlyFailed := trackFailedRunPeriods(controlledJob, *state.StartOfCurrentRunPeriod, runPeriodFailed)
+		consecutiveFailures := controlledJob.Status.FailedRunPeriods.ConsecutiveFailures
+		if newlyFailed && consecutiveFailures >= *controlledJob.Spec.SuspendAfterFailedRunPeriods {
+			message := fmt.Sprintf("Suspended after %d consecutive failed run periods", consecutiveFailures)
+			log.V(1).Info("Too many consecutive failed run periods, will suspend the ControlledJob",
+				"consecutiveFailures", consecutiveFailures)
+
+			controlledJob.Status.AutoSuspended = &v1.AutoSuspendedStatus{
+				Reason:      "RepeatedFailures",
+				Message:     message,
+				SuspendedAt: metav1.NewTime(now),
+			}
+			// Start counting afresh, so that resuming the ControlledJob gives it the full number of attempts again
+			controlledJob.Status.FailedRunPeriods.ConsecutiveFailures = 0
+			isSuspended = true
+			v1.SetCondition(controlledJob, v1.ConditionTypeSuspended, metav1.ConditionTrue, "AutoSuspended", "Suspended by the controller")
+			v1.SetCondition(controlledJob, v1.ConditionTypeAutoSuspended, metav1.ConditionTrue, "RepeatedFailures", message)
+
+			// Behave exactly as if we were already suspended
+			chosenJob = nil
+			decision = Decision{
+				JobsToDelete:       state.AllJobs,
+				AutoSuspendMessage: message,
+			}
+			for _, job := range state.AllJobs {
+				decision.because(job, "the ControlledJob is being suspended: %s", message)
+			}
+			return
+		}
 	}
 
 	/*
@@ -200,6 +446,9 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 				"job", chosenJob.Name)
 			outOfDateReason = "JobIsNotRunning"
 			outOfDateMessage = "Job is out of date, but is not running so ignoring"
+			if !decision.isExplained(chosenJob) {
+				decision.because(chosenJob, "it's out of date, but isn't running so is left alone")
+			}
 		} else if metadata.IsJobBeingDeleted(chosenJob) {
 			// This is a bit of a subtle edge case. If the job is being deleted, but has an out of date spec, then we
 			// should _not_ recreate it, because the most likely situation is that the user has issued a stop request
@@ -208,6 +457,9 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 				"job", chosenJob.Name)
 			outOfDateReason = "JobIsBeingDeleted"
 			outOfDateMessage = "Job is out of date, but is being deleted so ignoring"
+			if !decision.isExplained(chosenJob) {
+				decision.because(chosenJob, "it's out of date, but is already being deleted so isn't recreated")
+			}
 		} else if !restartOnSpecChange {
 			log.V(1).Info("Job is out of date, but auto-recreation is not enabled so will leave it running as is",
 				"job", chosenJob.Name,
@@ -215,6 +467,17 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 				"enabledGloballyInOperator", enableAutoRecreateJobsOnSpecChange)
 			outOfDateReason = "ShouldNotAutoRestart"
 			outOfDateMessage = "Job is out of date, but auto-recreation is not enabled so will leave it running as is"
+			if !decision.isExplained(chosenJob) {
+				decision.because(chosenJob, "it's out of date, but recreating jobs on spec changes isn't enabled, so it's left running")
+			}
+		} else if jobRunIdsExhausted {
+			log.V(1).Info("Job is out of date, but the largest job run id has been used so will leave it running as is",
+				"job", chosenJob.Name, "maxJobRunId", metadata.MaxJobRunId)
+			outOfDateReason = "JobRunIdsExhausted"
+			outOfDateMessage = "Job is out of date, but the largest job run id has been used so will leave it running as is"
+			if !decision.isExplained(chosenJob) {
+				decision.because(chosenJob, "it's out of date, but it can't be recreated as the largest job run id, %d, has been used, so it's left running", metadata.MaxJobRunId)
+			}
 		} else {
 			log.V(1).Info("Job is out of date, will recreate it with the latest spec", "job", chosenJob.Name)
 
@@ -224,6 +487,8 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 				return
 			}
 			decision.JobsToCreate = append(decision.JobsToCreate, newJob)
+			decision.because(chosenJob, "it's out of date, so it's replaced by %s with the latest spec", newJob.Name)
+			decision.because(newJob, "it recreates %s with the latest spec", chosenJob.Name)
 			numberOfPotentiallyRunningJobs++
 			nonExpiredJobs = append(nonExpiredJobs, newJob)
 			chosenJob = newJob
@@ -242,7 +507,8 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	// Note we only care about whether a job exists, we don't care if they're actually running or completed/suspended/failed. This is for
 	// various reasons:
 	// - When a job completes within its run period, we consider that expected and don't want to automatically restart it
-	// - Users don't necessarily want failing jobs to keep restarting themselves, in case that causes issues (we can't know what their code does)
+	// - Users don't necessarily want failing jobs to keep restarting themselves, in case that causes issues (we can't know what their code does).
+	//   Users who do can opt in with the AlwaysRestart FailurePolicy, which is handled above
 	// - Users need a way to stop a ControlledJob for a period. This is achieved by suspending a running job. In that case we
 	//   don't want to treat that as 'not running' or we'd immediately restart a stopped ControlledJob!
 	//
@@ -250,7 +516,27 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	// - They can use restartPolicy: OnFailure, and backoffLimit in their jobTemplate spec in order to auto restart on pod failure
 	// - They can monitor the status of their jobs in Prometheus and alert if jobs are not running when they should
 	// - They can use application level monitoring to ensure their system has the correct number of running instances
-	if shouldBeRunning && chosenJob == nil {
+	//
+	// With a start-only schedule and the Forbid ConcurrencyPolicy, a run period which starts while a job from an earlier
+	// run period is still running is skipped, as for a CronJob. We record that in the status, so that we don't create
+	// a job later in the run period once the earlier job has finished
+	runPeriodSkipped := false
+	if shouldBeRunning && chosenJob == nil && state.StartOfCurrentRunPeriod != nil {
+		skippedRunPeriodStartTime := controlledJob.Status.SkippedRunPeriodStartTime
+		if skippedRunPeriodStartTime != nil && skippedRunPeriodStartTime.Time.Equal(*state.StartOfCurrentRunPeriod) {
+			runPeriodSkipped = true
+		} else if earlierJobStillRunning && state.ConcurrencyPolicy == v1.ForbidConcurrent {
+			log.V(1).Info("We expect to be running, but a job from an earlier run period is still running and the ConcurrencyPolicy is Forbid, so will skip this run period")
+			startOfSkippedRunPeriod := metav1.NewTime(*state.StartOfCurrentRunPeriod)
+			controlledJob.Status.SkippedRunPeriodStartTime = &startOfSkippedRunPeriod
+			runPeriodSkipped = true
+		}
+	}
+	v1.SetConditionBasedOnFlag(controlledJob, v1.ConditionTypeRunPeriodSkipped, runPeriodSkipped,
+		"ConcurrencyForbidden", "A job from an earlier run period was still running when this run period started, so no job will be started until the next start event",
+		"RunPeriodNotSkipped", "The current run period has not been skipped")
+
+	if shouldBeRunning && chosenJob == nil && !runPeriodSkipped {
 		if startingDeadlineSecondsExceeded(controlledJob, state.StartOfCurrentRunPeriod, now) {
 			log.V(1).Info("We expect to be running, but there is no job")
 			v1.SetCondition(controlledJob, v1.ConditionTypeStartingDeadlineExceeded, metav1.ConditionTrue, "StartingDeadlineExceeded", "We expect to be running, but have exceeded the starting deadline")
@@ -270,7 +556,12 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 			err = errors.Wrap(e, "Failed to create job")
 			return
 		}
+		// If the run period has already had a restart event, this job starts after it, so mustn't be restarted for it
+		if state.LastRestartTime != nil && state.LastRestartTime.After(*state.StartOfCurrentRunPeriod) {
+			newJob.Annotations[metadata.RestartedAtAnnotation] = state.LastRestartTime.Format(time.RFC3339)
+		}
 		decision.JobsToCreate = append(decision.JobsToCreate, newJob)
+		decision.because(newJob, "no job exists for the run period which started at %s", state.StartOfCurrentRunPeriod.Format(time.RFC3339))
 		numberOfPotentiallyRunningJobs++
 		nonExpiredJobs = append(nonExpiredJobs, newJob)
 		chosenJob = newJob
@@ -286,6 +577,7 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	// All expired jobs get deleted
 	for _, job := range expiredJobs {
 		if metadata.IsJobBeingDeleted(job) {
+			decision.because(job, "%s, and it's already being deleted", decision.Reasons[job.Name])
 			continue
 		}
 		decision.JobsToDelete = append(decision.JobsToDelete, job)
@@ -294,10 +586,26 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 	// Non-expired jobs that aren't the chosen job and aren't completed get deleted
 	for _, job := range nonExpiredJobs {
 		if metadata.IsJobBeingDeleted(job) {
+			if !decision.isExplained(job) {
+				decision.because(job, "it's already being deleted")
+			}
 			continue
 		}
 
-		if job == chosenJob || metadata.IsJobCompleted(job) {
+		if job == chosenJob {
+			continue
+		}
+		if !decision.isExplained(job) {
+			switch {
+			case metadata.IsJobCompleted(job):
+				decision.because(job, "it has finished, and is kept so that earlier runs can be seen")
+			case chosenJob == nil:
+				decision.because(job, "no job should be running")
+			default:
+				decision.because(job, "%s is the current job, and only one job may run at a time", chosenJob.Name)
+			}
+		}
+		if metadata.IsJobCompleted(job) {
 			continue
 		}
 		decision.JobsToDelete = append(decision.JobsToDelete, job)
@@ -327,12 +635,30 @@ func makeDecision(ctx context.Context, controlledJob *v1.ControlledJob, childJob
 		} else {
 			decision.JobsToUnsuspend = append(decision.JobsToUnsuspend, chosenJob)
 		}
+		if !decision.isExplained(chosenJob) {
+			decision.because(chosenJob, "it's the current job, and no other job may be running, so it's safe to start it")
+		}
+	} else if chosenJob != nil && !decision.isExplained(chosenJob) {
+		switch {
+		case metadata.IsJobBeingDeleted(chosenJob):
+			decision.because(chosenJob, "it's already being deleted")
+		case metadata.WasJobStoppedByTheUser(chosenJob):
+			decision.because(chosenJob, "it was stopped by the user, so it isn't started again until the next run period")
+		case metadata.IsJobSuspended(chosenJob):
+			decision.because(chosenJob, "it's the current job, but it waits to start until the other jobs have stopped")
+		default:
+			decision.because(chosenJob, "it's the current job")
+		}
 	}
 
 	// Set requeue at next event time
 	if state.NextEventTime != nil {
 		decision.RequeueAt = *state.NextEventTime
 	}
+	// ... unless we're waiting to replace a failed job before then
+	if failureRestartAt != nil && (decision.RequeueAt.IsZero() || failureRestartAt.Before(decision.RequeueAt)) {
+		decision.RequeueAt = *failureRestartAt
+	}
 
 	decision.AddToLog(log).V(1).Info("Made decision")
 
@@ -366,6 +692,31 @@ func setShouldBeRunningStatus(controlledJob *v1.ControlledJob, state *state) {
 	v1.SetCondition(controlledJob, v1.ConditionTypeShouldBeRunning, shouldBeRunningStatus, shouldBeRunningReason, shouldBeRunningMessage)
 }
 
+// maxScheduleWarningsInCondition limits how many warnings are described in the ScheduleWarnings condition's message
+const maxScheduleWarningsInCondition = 5
+
+func setScheduleWarningsCondition(controlledJob *v1.ControlledJob, warnings []schedule.ScheduleWarning) {
+	if len(warnings) == 0 {
+		v1.SetCondition(controlledJob, v1.ConditionTypeScheduleWarnings, metav1.ConditionFalse, "NoWarnings", "No conflicting or redundant events found in the schedule")
+		return
+	}
+
+	reason := string(warnings[0].Type)
+	messages := make([]string, 0, maxScheduleWarningsInCondition+1)
+	for i, warning := range warnings {
+		if warning.Type != warnings[0].Type {
+			reason = "MultipleWarnings"
+		}
+		if i < maxScheduleWarningsInCondition {
+			messages = append(messages, warning.Message)
+		}
+	}
+	if len(warnings) > maxScheduleWarningsInCondition {
+		messages = append(messages, fmt.Sprintf("(and %d more)", len(warnings)-maxScheduleWarningsInCondition))
+	}
+	v1.SetCondition(controlledJob, v1.ConditionTypeScheduleWarnings, metav1.ConditionTrue, reason, strings.Join(messages, " "))
+}
+
 func setConditionsForAllJobs(controlledJob *v1.ControlledJob, allJobs []*kbatch.Job) {
 
 	if len(allJobs) == 0 {
@@ -455,6 +806,70 @@ func isOutOfDate(job *kbatch.Job, state *state) bool {
 	return actualHash != "" && actualHash != state.DesiredHash
 }
 
+// needsRestart returns true if the given job was started before the most recent restart event in the current run period.
+// Jobs that are being deleted, or that the user has explicitly stopped, are left alone
+func needsRestart(job *kbatch.Job, state *state) bool {
+	if state.LastRestartTime == nil || metadata.IsJobBeingDeleted(job) || metadata.WasJobStoppedByTheUser(job) {
+		return false
+	}
+
+	// A job is considered to have started at the later of its scheduled time, or the restart it was created for
+	jobStartTime, err := metadata.GetScheduledTime(job)
+	if err != nil {
+		return false
+	}
+	restartedAt, err := metadata.GetRestartedAtTime(job)
+	if err == nil && restartedAt != nil && restartedAt.After(jobStartTime) {
+		jobStartTime = *restartedAt
+	}
+	return jobStartTime.Before(*state.LastRestartTime)
+}
+
+// trackFailedRunPeriods records whether the current run period has failed in the ControlledJob's status, returning
+// true if this is the first time we've seen it fail. When a new run period starts, the count of consecutive failures
+// carries over only if the previous run period failed
+func trackFailedRunPeriods(controlledJob *v1.ControlledJob, startOfCurrentRunPeriod schedule.RunPeriodStartTime, runPeriodFailed bool) bool {
+	tracker := controlledJob.Status.FailedRunPeriods
+	if tracker == nil || !tracker.RunPeriodStartTime.Time.Equal(startOfCurrentRunPeriod) {
+		consecutiveFailures := int32(0)
+		if tracker != nil && tracker.RunPeriodFailed {
+			consecutiveFailures = tracker.ConsecutiveFailures
+		}
+		tracker = &v1.FailedRunPeriodsStatus{
+			RunPeriodStartTime:  metav1.NewTime(startOfCurrentRunPeriod),
+			ConsecutiveFailures: consecutiveFailures,
+		}
+		controlledJob.Status.FailedRunPeriods = tracker
+	}
+
+	if !runPeriodFailed || tracker.RunPeriodFailed {
+		return false
+	}
+	tracker.RunPeriodFailed = true
+	tracker.ConsecutiveFailures++
+	return true
+}
+
+// isFailedJobToReplace returns true if the given job has failed, and hasn't been deleted or stopped by the user
+func isFailedJobToReplace(job *kbatch.Job) bool {
+	return metadata.JobHasCondition(job, kbatch.JobFailed) &&
+		!metadata.IsJobBeingDeleted(job) &&
+		!metadata.WasJobStoppedByTheUser(job)
+}
+
+// failureBackoff returns how long to wait before replacing a failed job, given how many failed jobs have already been
+// replaced in this run period. The backoff doubles for each restart, up to the configured maximum
+func failureBackoff(state *state, restartCount int) time.Duration {
+	backoff := state.FailureBackoff
+	for i := 0; i < restartCount && backoff < state.MaxFailureBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > state.MaxFailureBackoff {
+		backoff = state.MaxFailureBackoff
+	}
+	return backoff
+}
+
 func isBetterCandidateJob(job *kbatch.Job, currentCandidate *kbatch.Job, state *state) bool {
 	// jobs that are not being deleted are better than ones being deleted
 	if metadata.IsJobBeingDeleted(job) != metadata.IsJobBeingDeleted(currentCandidate) {
@@ -469,6 +884,20 @@ func isBetterCandidateJob(job *kbatch.Job, currentCandidate *kbatch.Job, state *
 		return jobHash == desiredHash
 	}
 
+	// jobs from a later run period are better than jobs from an earlier one
+	jobScheduledTime, jobErr := metadata.GetScheduledTime(job)
+	currentCandidateScheduledTime, currentCandidateErr := metadata.GetScheduledTime(currentCandidate)
+	if jobErr == nil && currentCandidateErr == nil && !jobScheduledTime.Equal(currentCandidateScheduledTime) {
+		return jobScheduledTime.After(currentCandidateScheduledTime)
+	}
+
+	// jobs with a higher job run id are newer, so are better than older jobs
+	jobRunId, jobErr := metadata.GetJobRunId(job)
+	currentCandidateRunId, currentCandidateErr := metadata.GetJobRunId(currentCandidate)
+	if jobErr == nil && currentCandidateErr == nil && jobRunId != currentCandidateRunId {
+		return jobRunId > currentCandidateRunId
+	}
+
 	// Otherwise, the one with the greater name lexicographically wins
 	return job.Name > currentCandidate.Name
 }
@@ -11,6 +11,7 @@ import (
 	v1 "github.com/G-Research/controlled-job/api/v1"
 	"github.com/G-Research/controlled-job/pkg/clientadapter"
 	"github.com/G-Research/controlled-job/pkg/events"
+	"github.com/G-Research/controlled-job/pkg/schedule"
 	"github.com/pkg/errors"
 	kbatch "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +41,9 @@ var (
 	Options = &ReconcileOptions{
 		EnableAutoRecreateJobsOnSpecChange: false,
 	}
+
+	// schedules keeps the compiled schedule of each ControlledJob between reconciles
+	schedules = schedule.NewCache()
 )
 
 // AsControllerResultAndError maps a ReconcileResult to a form that we can
@@ -93,7 +97,8 @@ func Reconcile(ctx context.Context, target types.NamespacedName, now time.Time,
 		return TransientErrorResult(err)
 	}
 	if controlledJob == nil {
-		// No controlled job found, nothing to do
+		// No controlled job found, nothing to do apart from forgetting its schedule
+		schedules.Evict(target)
 		return ReconcileResult{}
 	}
 	defer func() {
@@ -104,7 +109,13 @@ func Reconcile(ctx context.Context, target types.NamespacedName, now time.Time,
 		}
 	}()
 
-	decision, err := makeDecision(ctx, controlledJob, childJobs, now, Options.EnableAutoRecreateJobsOnSpecChange)
+	var calendar *batch.CalendarSpec
+	calendar, err = loadCalendar(ctx, controlledJob, client)
+	if err != nil {
+		return TransientErrorResult(err)
+	}
+
+	decision, err := makeDecision(ctx, controlledJob, calendar, childJobs, now, Options.EnableAutoRecreateJobsOnSpecChange)
 	if err != nil {
 		// Don't requeue, as a failure to build state is (likely) a user error and we need to
 		// wait for them to fix it.
@@ -134,6 +145,9 @@ func Reconcile(ctx context.Context, target types.NamespacedName, now time.Time,
 			err = events.WrapError(err, events.FailedToCreateJob, fmt.Sprintf("failed to create job %s in namespace %s", job.Name, job.Namespace))
 			v1.SetCondition(controlledJob, v1.ConditionTypeFailedToCreateJob, metav1.ConditionTrue, "FailedToCreateJob", err.Error())
 			return TransientErrorResult(err)
+		} else if reason, isRestart := decision.restartReason(job); isRestart {
+			eventHandler.RecordEvent(ctx, controlledJob, events.NewJobRestartedAction(job.Name, reason))
+			v1.SetCondition(controlledJob, v1.ConditionTypeFailedToCreateJob, metav1.ConditionFalse, "CreatedJob", "Successfully created job")
 		} else {
 			eventHandler.RecordEvent(ctx, controlledJob, events.NewJobStartedAction(job.Name))
 			v1.SetCondition(controlledJob, v1.ConditionTypeFailedToCreateJob, metav1.ConditionFalse, "CreatedJob", "Successfully created job")
@@ -165,6 +179,10 @@ func Reconcile(ctx context.Context, target types.NamespacedName, now time.Time,
 		}
 	}
 
+	if decision.AutoSuspendMessage != "" {
+		eventHandler.RecordEvent(ctx, controlledJob, events.NewAutoSuspendedAction(decision.AutoSuspendMessage))
+	}
+
 	return ReconcileResult{RequeueAfter: decision.RequeueAt.Sub(now)}
 }
 
@@ -309,6 +327,19 @@ func loadFromCluster(ctx context.Context, target types.NamespacedName, client cl
 	return controlledJob, &jobList, err
 }
 
+// loadCalendar loads the spec of the calendar referenced by the given ControlledJob, if any
+func loadCalendar(ctx context.Context, controlledJob *batch.ControlledJob, client clientadapter.ControlledJobClient) (*batch.CalendarSpec, error) {
+	if controlledJob.Spec.CalendarRef == nil {
+		return nil, nil
+	}
+	ref := *controlledJob.Spec.CalendarRef
+	calendar, err := client.GetCalendar(ctx, controlledJob.Namespace, ref)
+	if err != nil {
+		return nil, events.WrapError(err, events.FailedToGetCalendar, fmt.Sprintf("Failed to get calendar %s for controlled job %s in namespace %s", ref.Name, controlledJob.Name, controlledJob.Namespace))
+	}
+	return calendar, nil
+}
+
 func recordFailedReconcile(ctx context.Context, controlledJob *batch.ControlledJob, err error, eventHandler events.Handler) {
 	if err == nil {
 		// No error
@@ -20,13 +20,22 @@ import (
 // values in one go, and use them to decide what to do
 type state struct {
 	IsSuspended             bool
+	IsAutoSuspended         bool
 	ShouldBeRunning         *bool
 	StartOfCurrentRunPeriod *schedule.RunPeriodStartTime
 	LastStopTime            *time.Time
+	LastRestartTime         *time.Time
 	NextEventTime           *time.Time
+	IsStartOnly             bool
+	ConcurrencyPolicy       batch.ConcurrencyPolicy
 	AllJobs                 []*kbatch.Job
 	DesiredHash             string
 	AutoRestartIsEnabled    bool
+	FailurePolicy           batch.FailurePolicy
+	FailureBackoff          time.Duration
+	MaxFailureBackoff       time.Duration
+	MaxRestartsPerRunPeriod *int32
+	ScheduleWarnings        []schedule.ScheduleWarning
 }
 
 // GetStateForReconcile loads information from the cluster for the given target ControlledJob we've
@@ -36,9 +45,13 @@ type state struct {
 //
 // - Resolving any Jobs owned by the ControlledJob
 // - Calculating the schedule state - should the job currently be running? When's the next event time etc.
-func buildState(ctx context.Context, controlledJob *batch.ControlledJob, childJobs *kbatch.JobList, now time.Time) (*state, error) {
+func buildState(ctx context.Context, controlledJob *batch.ControlledJob, calendar *batch.CalendarSpec, childJobs *kbatch.JobList, now time.Time) (*state, error) {
 
-	scheduleState, err := schedule.StateFor(controlledJob, now)
+	scheduleState, err := schedules.StateFor(controlledJob, calendar, now)
+	if err != nil {
+		return nil, events.WrapError(err, events.FailedToCalculateSchedule, fmt.Sprintf("Failed to calculate schedule for controlled job %s in namespace %s", controlledJob.Name, controlledJob.Namespace))
+	}
+	scheduleWarnings, err := schedules.WarningsFor(controlledJob, calendar, now)
 	if err != nil {
 		return nil, events.WrapError(err, events.FailedToCalculateSchedule, fmt.Sprintf("Failed to calculate schedule for controlled job %s in namespace %s", controlledJob.Name, controlledJob.Namespace))
 	}
@@ -56,15 +69,38 @@ func buildState(ctx context.Context, controlledJob *batch.ControlledJob, childJo
 		shouldBeRunning = &val
 	}
 
+	restartStrategy := controlledJob.Spec.RestartStrategy
+	failurePolicy := batch.NeverRestartFailurePolicy
+	if strings.EqualFold(string(restartStrategy.FailurePolicy), string(batch.AlwaysRestartFailurePolicy)) {
+		failurePolicy = batch.AlwaysRestartFailurePolicy
+	}
+	failureBackoffSeconds := batch.DefaultFailureBackoffSeconds
+	if restartStrategy.BackoffSeconds != nil {
+		failureBackoffSeconds = *restartStrategy.BackoffSeconds
+	}
+	maxFailureBackoffSeconds := batch.DefaultMaxFailureBackoffSeconds
+	if restartStrategy.MaxBackoffSeconds != nil {
+		maxFailureBackoffSeconds = *restartStrategy.MaxBackoffSeconds
+	}
+
 	return &state{
 		IsSuspended:             controlledJob.Spec.Suspend != nil && *controlledJob.Spec.Suspend,
+		IsAutoSuspended:         controlledJob.Status.AutoSuspended != nil,
 		ShouldBeRunning:         shouldBeRunning,
 		StartOfCurrentRunPeriod: startOfCurrentRunPeriod,
 		LastStopTime:            scheduleState.LastStopTime(),
+		LastRestartTime:         scheduleState.LastRestartTime(),
 		NextEventTime:           scheduleState.NextEventTime(),
+		IsStartOnly:             scheduleState.IsStartOnly(),
+		ConcurrencyPolicy:       controlledJob.Spec.ConcurrencyPolicy,
 		AllJobs:                 allJobs,
 		DesiredHash:             metadata.CalculateHashFor(controlledJob.Spec.JobTemplate),
-		AutoRestartIsEnabled:    strings.EqualFold(string(controlledJob.Spec.RestartStrategy.SpecChangePolicy), string(v1.RecreateSpecChangePolicy)),
+		AutoRestartIsEnabled:    strings.EqualFold(string(restartStrategy.SpecChangePolicy), string(v1.RecreateSpecChangePolicy)),
+		FailurePolicy:           failurePolicy,
+		FailureBackoff:          time.Duration(failureBackoffSeconds) * time.Second,
+		MaxFailureBackoff:       time.Duration(maxFailureBackoffSeconds) * time.Second,
+		MaxRestartsPerRunPeriod: restartStrategy.MaxRestartsPerRunPeriod,
+		ScheduleWarnings:        scheduleWarnings,
 	}, nil
 }
 
@@ -0,0 +1,152 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/events"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	kbatch "k8s.io/api/batch/v1"
+)
+
+func Test_AutoSuspend(t *testing.T) {
+	// Monday 12th December 2022
+	var yesterdayStartTime = time.Date(2022, time.December, 11, 9, 0, 0, 0, time.UTC)
+	var startTime = time.Date(2022, time.December, 12, 9, 0, 0, 0, time.UTC)
+	var failedAt = time.Date(2022, time.December, 12, 10, 0, 0, 0, time.UTC)
+	var tomorrowStartTime = time.Date(2022, time.December, 13, 9, 0, 0, 0, time.UTC)
+
+	var givenControlledJobWithAutoSuspend = func(tc *testContext, opts ...ControlledJobOption) {
+		tc.GivenAControlledJob(append([]ControlledJobOption{
+			WithControlledJobName("suspend-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStart, "09:00"),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStop, "17:00"),
+			WithSuspendAfterFailedRunPeriods(3),
+		}, opts...)...)
+	}
+
+	Run(t, "counting failed run periods", func(tc *testContext) {
+		tc.Run("failures are not tracked unless enabled", func(tc *testContext) {
+			tc.GivenAControlledJob(
+				WithControlledJobName("suspend-test"),
+				WithDefaultJobTemplate(),
+				WithScheduledEventAtTimeEveryDay(v1.EventTypeStart, "09:00"),
+				WithScheduledEventAtTimeEveryDay(v1.EventTypeStop, "17:00"),
+			)
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			assert.Nil(tc, tc.currentReconcileRun.status.FailedRunPeriods)
+			tc.ShouldHaveCondition(v1.ConditionTypeAutoSuspended, "False")
+		})
+
+		tc.Run("a failed job counts as a failed run period", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc)
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			tracker := tc.currentReconcileRun.status.FailedRunPeriods
+			if assert.NotNil(tc, tracker) {
+				assert.Equal(tc, startTime, tracker.RunPeriodStartTime.Time.UTC())
+				assert.True(tc, tracker.RunPeriodFailed)
+				assert.Equal(tc, int32(1), tracker.ConsecutiveFailures)
+			}
+			assert.Nil(tc, tc.currentReconcileRun.status.AutoSuspended)
+		})
+
+		tc.Run("a run period is only counted once", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc, WithFailedRunPeriods(startTime, true, 1))
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			assert.Equal(tc, int32(1), tc.currentReconcileRun.status.FailedRunPeriods.ConsecutiveFailures)
+		})
+
+		tc.Run("a failed job waiting to be replaced does not count", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc,
+				WithFailurePolicy(v1.AlwaysRestartFailurePolicy),
+				WithFailureBackoff(60, 600))
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			assert.False(tc, tc.currentReconcileRun.status.FailedRunPeriods.RunPeriodFailed)
+			assert.Equal(tc, int32(0), tc.currentReconcileRun.status.FailedRunPeriods.ConsecutiveFailures)
+		})
+
+		tc.Run("count carries over from a failed run period, and resets after a good one", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc, WithFailedRunPeriods(yesterdayStartTime, true, 2))
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), WithActiveCount(1)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			// Today hasn't failed (yet), but yesterday's failures still count
+			assert.False(tc, tc.currentReconcileRun.status.FailedRunPeriods.RunPeriodFailed)
+			assert.Equal(tc, int32(2), tc.currentReconcileRun.status.FailedRunPeriods.ConsecutiveFailures)
+
+			// Today ended without a failure, so tomorrow starts from scratch
+			tc.existingJobs = []kbatch.Job{}
+			tc.WhenReconcileIsRunAt(tomorrowStartTime)
+
+			assert.Equal(tc, tomorrowStartTime, tc.currentReconcileRun.status.FailedRunPeriods.RunPeriodStartTime.Time.UTC())
+			assert.Equal(tc, int32(0), tc.currentReconcileRun.status.FailedRunPeriods.ConsecutiveFailures)
+		})
+	})
+
+	Run(t, "suspending", func(tc *testContext) {
+		tc.Run("suspends after too many consecutive failed run periods", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc, WithFailedRunPeriods(yesterdayStartTime, true, 2))
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			autoSuspended := tc.currentReconcileRun.status.AutoSuspended
+			if assert.NotNil(tc, autoSuspended) {
+				assert.Equal(tc, "RepeatedFailures", autoSuspended.Reason)
+				assert.Equal(tc, failedAt, autoSuspended.SuspendedAt.Time)
+			}
+			assert.True(tc, *tc.currentReconcileRun.status.IsSuspended)
+			// Resuming gives the ControlledJob a fresh set of attempts
+			assert.Equal(tc, int32(0), tc.currentReconcileRun.status.FailedRunPeriods.ConsecutiveFailures)
+			tc.ShouldHaveCondition(v1.ConditionTypeAutoSuspended, "True")
+			tc.ShouldHaveCondition(v1.ConditionTypeSuspended, "True")
+			tc.ShouldHaveRecordedEvent(string(events.FailedRepeatedly))
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("suspend-test-0"))
+			tc.ShouldNotHaveCreatedAJob()
+		})
+
+		tc.Run("stays suspended until the user clears it", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc, WithAutoSuspended("RepeatedFailures", yesterdayStartTime))
+
+			tc.WhenReconcileIsRunAt(tomorrowStartTime)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeAutoSuspended, "True")
+			tc.ShouldHaveCondition(v1.ConditionTypeSuspended, "True")
+			assert.True(tc, *tc.currentReconcileRun.status.IsSuspended)
+
+			// The user clears the auto-suspended state
+			tc.controlledJob.Status.AutoSuspended = nil
+			tc.WhenReconcileIsRunAt(tomorrowStartTime)
+
+			tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(tomorrowStartTime))
+			tc.ShouldHaveCondition(v1.ConditionTypeAutoSuspended, "False")
+			assert.False(tc, *tc.currentReconcileRun.status.IsSuspended)
+		})
+
+		tc.Run("spec.suspend is left alone", func(tc *testContext) {
+			givenControlledJobWithAutoSuspend(tc, WithFailedRunPeriods(yesterdayStartTime, true, 2))
+			tc.GivenExistingJobs(NewJob("suspend-test-0", jobScheduledAt("suspend-test", startTime, 0), HasFailedAt(failedAt)))
+
+			tc.WhenReconcileIsRunAt(failedAt)
+
+			assert.Nil(tc, tc.controlledJob.Spec.Suspend)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/events"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_Calendar(t *testing.T) {
+	// Run from 09:00 until 17:00 every weekday. 2022-12-23 is a Friday
+	var startTimeBeforeEarlyClose = time.Date(2022, time.December, 23, 9, 0, 0, 0, time.UTC)
+	var duringRunBeforeEarlyClose = time.Date(2022, time.December, 23, 11, 0, 0, 0, time.UTC)
+	var earlyClose = time.Date(2022, time.December, 23, 12, 30, 0, 0, time.UTC)
+	var startTimeOnExtraWorkingDay = time.Date(2022, time.December, 24, 9, 0, 0, 0, time.UTC)
+	var startTimeOnHoliday = time.Date(2022, time.December, 26, 9, 0, 0, 0, time.UTC)
+	var stopTimeOnHoliday = time.Date(2022, time.December, 26, 17, 0, 0, 0, time.UTC)
+
+	var calendar = v1.CalendarSpec{
+		Holidays:         []v1.ExclusionSpec{{Date: "2022-12-26"}},
+		EarlyCloses:      []v1.EarlyCloseSpec{{Date: "2022-12-23", TimeOfDay: "12:30"}},
+		ExtraWorkingDays: []v1.ExtraWorkingDaySpec{{Date: "2022-12-24", ScheduleAs: "FRI"}},
+	}
+
+	var givenControlledJobWithCalendar = func(tc *testContext, kind string) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("calendar-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+			WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+			WithCalendarRef(kind, "trading-days"),
+		)
+	}
+
+	Run(t, "a run is stopped at an early close", func(tc *testContext) {
+		tc.GivenACalendar("", "trading-days", calendar)
+		givenControlledJobWithCalendar(tc, "")
+		tc.GivenExistingJobs(NewJob("calendar-test-0", jobScheduledAt("calendar-test", startTimeBeforeEarlyClose, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(duringRunBeforeEarlyClose)
+
+		tc.ShouldNotHaveDeletedAJob()
+		tc.ShouldHaveBeenRequeuedAt(earlyClose)
+
+		tc.WhenReconcileIsRunAt(earlyClose)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("calendar-test-0"))
+		tc.ShouldNotHaveCreatedAJob()
+	})
+
+	Run(t, "a job is started on an extra working day", func(tc *testContext) {
+		tc.GivenACalendar("", "trading-days", calendar)
+		givenControlledJobWithCalendar(tc, "")
+
+		tc.WhenReconcileIsRunAt(startTimeOnExtraWorkingDay)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTimeOnExtraWorkingDay))
+	})
+
+	Run(t, "no job is started on a holiday", func(tc *testContext) {
+		tc.GivenACalendar("", "trading-days", calendar)
+		givenControlledJobWithCalendar(tc, "")
+
+		tc.WhenReconcileIsRunAt(startTimeOnHoliday)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+		tc.ShouldHaveBeenRequeuedAt(stopTimeOnHoliday)
+	})
+
+	Run(t, "a cluster calendar can be used", func(tc *testContext) {
+		tc.GivenACalendar(v1.ClusterCalendarKind, "trading-days", calendar)
+		givenControlledJobWithCalendar(tc, v1.ClusterCalendarKind)
+
+		tc.WhenReconcileIsRunAt(startTimeOnHoliday)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+	})
+
+	Run(t, "a missing calendar is reported as an error", func(tc *testContext) {
+		givenControlledJobWithCalendar(tc, "")
+
+		tc.WhenReconcileIsRunAt(startTimeOnExtraWorkingDay)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeError, "True")
+		tc.ShouldHaveRecordedEvent(string(events.FailedToGetCalendar))
+	})
+}
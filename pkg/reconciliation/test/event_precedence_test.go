@@ -0,0 +1,65 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_EventPrecedence(t *testing.T) {
+	// Start at 09:00 every day and run for 24 hours, so each implied stop happens at the same instant as the next start
+	var firstStartTime = time.Date(2022, time.December, 12, 9, 0, 0, 0, time.UTC)
+	var secondStartTime = time.Date(2022, time.December, 13, 9, 0, 0, 0, time.UTC)
+	var thirdStartTime = time.Date(2022, time.December, 14, 9, 0, 0, 0, time.UTC)
+
+	var givenAControlledJobWithPrecedence = func(tc *testContext, precedence v1.EventPrecedence) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("precedence-test"),
+			WithDefaultJobTemplate(),
+			WithCronStartEventRunningFor("0 9 * * *", 24*time.Hour),
+			func(controlledJob *v1.ControlledJob) {
+				controlledJob.Spec.EventPrecedence = precedence
+			},
+		)
+	}
+
+	Run(t, "without a precedence the schedule has a warning", func(tc *testContext) {
+		givenAControlledJobWithPrecedence(tc, "")
+
+		tc.WhenReconcileIsRunAt(secondStartTime.Add(time.Hour))
+
+		tc.ShouldHaveCondition(v1.ConditionTypeScheduleWarnings, "True")
+	})
+
+	Run(t, "when start events take precedence each run period replaces the last", func(tc *testContext) {
+		givenAControlledJobWithPrecedence(tc, v1.StartEventPrecedence)
+		tc.GivenExistingJobs(NewJob("precedence-test-0", jobScheduledAt("precedence-test", firstStartTime, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(secondStartTime)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("precedence-test-0"))
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+		tc.ShouldHaveCondition(v1.ConditionTypeScheduleWarnings, "False")
+		tc.ShouldHaveBeenRequeuedAt(thirdStartTime)
+	})
+
+	Run(t, "when start events take precedence a new job is started once the old one is gone", func(tc *testContext) {
+		givenAControlledJobWithPrecedence(tc, v1.StartEventPrecedence)
+
+		tc.WhenReconcileIsRunAt(secondStartTime.Add(time.Minute))
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(secondStartTime))
+	})
+
+	Run(t, "when stop events take precedence the job never runs", func(tc *testContext) {
+		givenAControlledJobWithPrecedence(tc, v1.StopEventPrecedence)
+
+		tc.WhenReconcileIsRunAt(secondStartTime.Add(time.Hour))
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+		tc.ShouldHaveCondition(v1.ConditionTypeScheduleWarnings, "True")
+	})
+}
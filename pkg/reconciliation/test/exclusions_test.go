@@ -0,0 +1,88 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_Exclusions(t *testing.T) {
+	// Run overnight, from 22:00 until 06:00, except for runs starting on the 13th December
+	var startTimeBeforeExcludedDate = time.Date(2022, time.December, 12, 22, 0, 0, 0, time.UTC)
+	var duringRunIntoExcludedDate = time.Date(2022, time.December, 13, 2, 0, 0, 0, time.UTC)
+	var stopTimeOnExcludedDate = time.Date(2022, time.December, 13, 6, 0, 0, 0, time.UTC)
+	var excludedStartTime = time.Date(2022, time.December, 13, 22, 0, 0, 0, time.UTC)
+	var afterExcludedStartTime = time.Date(2022, time.December, 13, 22, 30, 0, 0, time.UTC)
+	var stopTimeAfterExcludedDate = time.Date(2022, time.December, 14, 6, 0, 0, 0, time.UTC)
+	var startTimeAfterExcludedDate = time.Date(2022, time.December, 14, 22, 0, 0, 0, time.UTC)
+
+	var givenControlledJobWithExclusion = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("exclusions-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStart, "22:00"),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStop, "06:00"),
+			WithExclusion("2022-12-13", ""),
+		)
+	}
+
+	Run(t, "a run started before an excluded date keeps running into it", func(tc *testContext) {
+		givenControlledJobWithExclusion(tc)
+		tc.GivenExistingJobs(NewJob("exclusions-test-0", jobScheduledAt("exclusions-test", startTimeBeforeExcludedDate, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(duringRunIntoExcludedDate)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldNotHaveDeletedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+		tc.ShouldHaveBeenRequeuedAt(stopTimeOnExcludedDate)
+	})
+
+	Run(t, "a run is stopped as usual on an excluded date", func(tc *testContext) {
+		givenControlledJobWithExclusion(tc)
+		tc.GivenExistingJobs(NewJob("exclusions-test-0", jobScheduledAt("exclusions-test", startTimeBeforeExcludedDate, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(stopTimeOnExcludedDate)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("exclusions-test-0"))
+		tc.ShouldNotHaveCreatedAJob()
+	})
+
+	Run(t, "no job is started on an excluded date", func(tc *testContext) {
+		givenControlledJobWithExclusion(tc)
+
+		tc.WhenReconcileIsRunAt(excludedStartTime)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+		tc.ShouldHaveBeenRequeuedAt(stopTimeAfterExcludedDate)
+
+		tc.WhenReconcileIsRunAt(afterExcludedStartTime)
+
+		tc.ShouldNotHaveCreatedAJob()
+	})
+
+	Run(t, "jobs are started again after the excluded date", func(tc *testContext) {
+		givenControlledJobWithExclusion(tc)
+
+		tc.WhenReconcileIsRunAt(startTimeAfterExcludedDate)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTimeAfterExcludedDate))
+	})
+
+	Run(t, "invalid exclusions are reported as an error", func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithDefaultJobTemplate(),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStart, "22:00"),
+			WithScheduledEventAtTimeEveryDay(v1.EventTypeStop, "06:00"),
+			WithExclusion("13/12/2022", ""),
+		)
+
+		tc.WhenReconcileIsRunAt(startTimeAfterExcludedDate)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeError, "True")
+	})
+}
@@ -0,0 +1,181 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/events"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_FailurePolicy(t *testing.T) {
+	// Monday 12th December 2022
+	var startTime = time.Date(2022, time.December, 12, 9, 0, 0, 0, time.UTC)
+	var failedAt = time.Date(2022, time.December, 12, 10, 0, 0, 0, time.UTC)
+	var stopTime = time.Date(2022, time.December, 12, 17, 0, 0, 0, time.UTC)
+
+	var givenControlledJobWithFailurePolicy = func(tc *testContext, opts ...ControlledJobOption) {
+		tc.GivenAControlledJob(append([]ControlledJobOption{
+			WithControlledJobName("failure-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+			WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+			WithFailurePolicy(v1.AlwaysRestartFailurePolicy),
+			WithFailureBackoff(60, 600),
+		}, opts...)...)
+	}
+
+	Run(t, "NeverRestart", func(tc *testContext) {
+		tc.Run("failed job is left alone by default", func(tc *testContext) {
+			tc.GivenAControlledJob(
+				WithControlledJobName("failure-test"),
+				WithDefaultJobTemplate(),
+				WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+				WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+			)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0), HasFailedAt(failedAt)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+			tc.ShouldHaveBeenRequeuedAt(stopTime)
+		})
+	})
+
+	Run(t, "AlwaysRestart", func(tc *testContext) {
+		tc.Run("failed job is not replaced before the backoff has passed", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0), HasFailedAt(failedAt)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(30 * time.Second))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+			// Wake up again once the backoff has passed
+			tc.ShouldHaveBeenRequeuedAt(failedAt.Add(60 * time.Second))
+		})
+
+		tc.Run("failed job is replaced once the backoff has passed", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0), HasFailedAt(failedAt)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(60 * time.Second))
+
+			// The failed job has completed, so the new job can be started straight away. The failed job is
+			// kept so the user can inspect it
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedJobName(metadata.JobName("failure-test", startTime, 1)),
+				WithExpectedScheduledTime(startTime),
+				WithExpectedJobIndex(1),
+				WithExpectedFailureRestartCount(1),
+				WithExpectedSuspendedFlag(nil),
+			)
+			tc.ShouldNotHaveDeletedAJob()
+			tc.ShouldHaveRecordedEvent(string(events.EventJobRestarted))
+			tc.ShouldHaveCondition(v1.ConditionTypeRestartBudgetExhausted, "False")
+			tc.ShouldHaveBeenRequeuedAt(stopTime)
+		})
+
+		tc.Run("backoff doubles for each restart in the run period", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0), HasFailedAt(failedAt.Add(-time.Hour))),
+				NewJob("failure-test-1", jobScheduledAt("failure-test", startTime, 1), HasFailedAt(failedAt.Add(-time.Hour)), metadata.WithFailureRestartCount(1)),
+				NewJob("failure-test-2", jobScheduledAt("failure-test", startTime, 2), HasFailedAt(failedAt), metadata.WithFailureRestartCount(2)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(60 * time.Second))
+
+			// Two restarts so far, so we wait 60s * 2 * 2
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveBeenRequeuedAt(failedAt.Add(240 * time.Second))
+		})
+
+		tc.Run("backoff is capped at the maximum", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-5", jobScheduledAt("failure-test", startTime, 5), HasFailedAt(failedAt), metadata.WithFailureRestartCount(5)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(60 * time.Second))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveBeenRequeuedAt(failedAt.Add(600 * time.Second))
+		})
+
+		tc.Run("newest job is chosen even with many restarts", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-9", jobScheduledAt("failure-test", startTime, 9), HasFailedAt(failedAt), metadata.WithFailureRestartCount(9)),
+				NewJob("failure-test-10", jobScheduledAt("failure-test", startTime, 10), WithActiveCount(1), metadata.WithFailureRestartCount(10)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+		})
+
+		tc.Run("failed job is not replaced once the restart budget is exhausted", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc, WithMaxRestartsPerRunPeriod(2))
+			tc.GivenExistingJobs(
+				NewJob("failure-test-2", jobScheduledAt("failure-test", startTime, 2), HasFailedAt(failedAt), metadata.WithFailureRestartCount(2)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeRestartBudgetExhausted, "True")
+			tc.ShouldHaveBeenRequeuedAt(stopTime)
+		})
+
+		tc.Run("failed job is not replaced once the largest job run id has been used", func(tc *testContext) {
+			// Without a restart budget, a Job which keeps failing would otherwise be replaced until its name was too long
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-999", jobScheduledAt("failure-test", startTime, metadata.MaxJobRunId), HasFailedAt(failedAt), metadata.WithFailureRestartCount(metadata.MaxJobRunId)),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeRestartBudgetExhausted, "True")
+			tc.ShouldHaveBeenRequeuedAt(stopTime)
+		})
+
+		tc.Run("failed job stopped by the user is not replaced", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0),
+					HasFailedAt(failedAt),
+					IsSuspended(true),
+					WithJobAnnotation(metadata.SuspendReason, "user-stop")),
+			)
+
+			tc.WhenReconcileIsRunAt(failedAt.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+		})
+
+		tc.Run("failed job is not replaced outside of the run period", func(tc *testContext) {
+			givenControlledJobWithFailurePolicy(tc)
+			tc.GivenExistingJobs(
+				NewJob("failure-test-0", jobScheduledAt("failure-test", startTime, 0), HasFailedAt(stopTime.Add(-time.Minute))),
+			)
+
+			tc.WhenReconcileIsRunAt(stopTime.Add(time.Hour))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("failure-test-0"))
+		})
+	})
+}
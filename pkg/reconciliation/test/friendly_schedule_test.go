@@ -0,0 +1,55 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_FriendlySchedules(t *testing.T) {
+	// A month-end batch window from 18:00 on the last business day of the month until 06:00 on the 1st of the next month.
+	// 2024-05-31 is a Friday, and 2024-06-30 is a Sunday
+	var mayStart = time.Date(2024, time.May, 31, 18, 0, 0, 0, time.UTC)
+	var duringMayWindow = time.Date(2024, time.May, 31, 23, 0, 0, 0, time.UTC)
+	var mayStop = time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC)
+	var juneStart = time.Date(2024, time.June, 28, 18, 0, 0, 0, time.UTC)
+
+	var givenMonthEndControlledJob = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("month-end-test"),
+			WithDefaultJobTemplate(),
+			WithFriendlyScheduledEvent(v1.EventTypeStart, v1.FriendlyScheduleSpec{TimeOfDay: "18:00", LastBusinessDayOfMonth: true}),
+			WithFriendlyScheduledEvent(v1.EventTypeStop, v1.FriendlyScheduleSpec{TimeOfDay: "06:00", DaysOfMonth: []int32{1}}),
+		)
+	}
+
+	Run(t, "a job is started during the window", func(tc *testContext) {
+		givenMonthEndControlledJob(tc)
+
+		tc.WhenReconcileIsRunAt(duringMayWindow)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(mayStart))
+		tc.ShouldHaveBeenRequeuedAt(mayStop)
+	})
+
+	Run(t, "the job is stopped at the end of the window", func(tc *testContext) {
+		givenMonthEndControlledJob(tc)
+		tc.GivenExistingJobs(NewJob("month-end-test-0", jobScheduledAt("month-end-test", mayStart, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(mayStop)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("month-end-test-0"))
+		tc.ShouldHaveBeenRequeuedAt(juneStart)
+	})
+
+	Run(t, "nothing happens between windows", func(tc *testContext) {
+		givenMonthEndControlledJob(tc)
+
+		tc.WhenReconcileIsRunAt(juneStart.Add(-time.Hour))
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveBeenRequeuedAt(juneStart)
+	})
+}
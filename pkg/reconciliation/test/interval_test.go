@@ -0,0 +1,45 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_IntervalEvents(t *testing.T) {
+	// Start every 90 minutes from 2024-03-01T00:00, and stop 20 minutes after each start
+	var startTime = time.Date(2024, time.March, 4, 6, 0, 0, 0, time.UTC)
+	var duringRun = time.Date(2024, time.March, 4, 6, 10, 0, 0, time.UTC)
+	var stopTime = time.Date(2024, time.March, 4, 6, 20, 0, 0, time.UTC)
+	var nextStartTime = time.Date(2024, time.March, 4, 7, 30, 0, 0, time.UTC)
+
+	var givenIntervalControlledJob = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("interval-test"),
+			WithDefaultJobTemplate(),
+			WithIntervalEvent(v1.EventTypeStart, "90m", "2024-03-01T00:00"),
+			WithIntervalEvent(v1.EventTypeStop, "90m", "2024-03-01T00:20"),
+		)
+	}
+
+	Run(t, "a job is started during a run", func(tc *testContext) {
+		givenIntervalControlledJob(tc)
+
+		tc.WhenReconcileIsRunAt(duringRun)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTime))
+		tc.ShouldHaveBeenRequeuedAt(stopTime)
+	})
+
+	Run(t, "the job is stopped at the end of the run", func(tc *testContext) {
+		givenIntervalControlledJob(tc)
+		tc.GivenExistingJobs(NewJob("interval-test-0", jobScheduledAt("interval-test", startTime, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(stopTime)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("interval-test-0"))
+		tc.ShouldHaveBeenRequeuedAt(nextStartTime)
+	})
+}
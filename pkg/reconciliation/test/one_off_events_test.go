@@ -0,0 +1,65 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_OneOffEvents(t *testing.T) {
+	// Run from 09:00 until 17:00 every weekday, plus an extra session from 06:00 until 10:30 on Saturday 2022-12-24
+	var fridayStop = time.Date(2022, time.December, 23, 17, 0, 0, 0, time.UTC)
+	var oneOffStart = time.Date(2022, time.December, 24, 6, 0, 0, 0, time.UTC)
+	var duringOneOffSession = time.Date(2022, time.December, 24, 8, 0, 0, 0, time.UTC)
+	var oneOffStop = time.Date(2022, time.December, 24, 10, 30, 0, 0, time.UTC)
+	var mondayStart = time.Date(2022, time.December, 26, 9, 0, 0, 0, time.UTC)
+
+	var givenControlledJobWithExtraSession = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("one-off-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+			WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+			WithOneOffEvent(v1.EventTypeStart, "2022-12-24T06:00"),
+			WithOneOffEvent(v1.EventTypeStop, "2022-12-24T10:30"),
+		)
+	}
+
+	Run(t, "wakes up for the one-off start", func(tc *testContext) {
+		givenControlledJobWithExtraSession(tc)
+
+		tc.WhenReconcileIsRunAt(fridayStop)
+
+		tc.ShouldHaveBeenRequeuedAt(oneOffStart)
+	})
+
+	Run(t, "a job is started at the one-off start", func(tc *testContext) {
+		givenControlledJobWithExtraSession(tc)
+
+		tc.WhenReconcileIsRunAt(duringOneOffSession)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(oneOffStart))
+		tc.ShouldHaveBeenRequeuedAt(oneOffStop)
+	})
+
+	Run(t, "the job is stopped at the one-off stop", func(tc *testContext) {
+		givenControlledJobWithExtraSession(tc)
+		tc.GivenExistingJobs(NewJob("one-off-test-0", jobScheduledAt("one-off-test", oneOffStart, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(oneOffStop)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("one-off-test-0"))
+		tc.ShouldHaveBeenRequeuedAt(mondayStart)
+	})
+
+	Run(t, "expired one-off events are ignored", func(tc *testContext) {
+		givenControlledJobWithExtraSession(tc)
+
+		tc.WhenReconcileIsRunAt(mondayStart)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(mondayStart))
+		tc.ShouldHaveCondition(v1.ConditionTypeError, "False")
+	})
+}
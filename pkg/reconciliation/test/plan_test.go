@@ -0,0 +1,87 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_Plan(t *testing.T) {
+	monday9am := time.Date(2022, time.July, 25, 9, 0, 0, 0, time.UTC)
+	monday10am := time.Date(2022, time.July, 25, 10, 0, 0, 0, time.UTC)
+	monday6pm := time.Date(2022, time.July, 25, 18, 0, 0, 0, time.UTC)
+	friday9am := time.Date(2022, time.July, 22, 9, 0, 0, 0, time.UTC)
+
+	givenAWeekdaySchedule := func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithDefaultJobTemplate(),
+			WithScheduledEvent(v1.EventTypeStart, "Mon-Fri", "09:00"),
+			WithScheduledEvent(v1.EventTypeStop, "Mon-Fri", "17:00"),
+		)
+	}
+
+	Run(t, "explains expired and current jobs", func(tc *testContext) {
+		givenAWeekdaySchedule(tc)
+		tc.GivenAnExistingJob(WithJobName("fridays-job"), metadata.WithControlledJobAnnotations(friday9am, 0, false, DefaultJobTemplate()))
+		tc.GivenAnExistingJob(WithJobName("mondays-job"), metadata.WithControlledJobAnnotations(monday9am, 0, false, DefaultJobTemplate()), WithActiveCount(1))
+
+		decision := tc.WhenPlannedAt(monday10am)
+
+		if assert.Len(tc, decision.JobsToDelete, 1) {
+			assert.Equal(tc, "fridays-job", decision.JobsToDelete[0].Name)
+		}
+		assert.Contains(tc, decision.Reasons["fridays-job"], "it has expired")
+		assert.Equal(tc, "it's the current job", decision.Reasons["mondays-job"])
+	})
+
+	Run(t, "explains jobs which aren't chosen", func(tc *testContext) {
+		givenAWeekdaySchedule(tc)
+		tc.GivenAnExistingJob(WithJobName("first"), metadata.WithControlledJobAnnotations(monday9am, 0, false, DefaultJobTemplate()))
+		tc.GivenAnExistingJob(WithJobName("second"), metadata.WithControlledJobAnnotations(monday9am, 1, false, DefaultJobTemplate()), IsSuspended(true))
+
+		decision := tc.WhenPlannedAt(monday10am)
+
+		assert.Equal(tc, "second is the current job, and only one job may run at a time", decision.Reasons["first"])
+		assert.Equal(tc, "it's the current job, but it waits to start until the other jobs have stopped", decision.Reasons["second"])
+	})
+
+	Run(t, "explains manually scheduled jobs outside the run period", func(tc *testContext) {
+		givenAWeekdaySchedule(tc)
+		tc.GivenAnExistingJob(WithJobName("manual"), metadata.WithControlledJobAnnotations(monday6pm.Add(-30*time.Minute), 0, true, DefaultJobTemplate()))
+
+		decision := tc.WhenPlannedAt(monday6pm)
+
+		assert.Empty(tc, decision.JobsToDelete)
+		assert.Equal(tc, "it was manually scheduled, so it's kept although we're outside a run period", decision.Reasons["manual"])
+	})
+
+	Run(t, "explains new jobs", func(tc *testContext) {
+		givenAWeekdaySchedule(tc)
+
+		decision := tc.WhenPlannedAt(monday10am)
+
+		if assert.Len(tc, decision.JobsToCreate, 1) {
+			assert.Equal(tc, "no job exists for the run period which started at 2022-07-25T09:00:00Z", decision.Reasons[decision.JobsToCreate[0].Name])
+		}
+	})
+
+	Run(t, "explains the effect of suspending the ControlledJob", func(tc *testContext) {
+		givenAWeekdaySchedule(tc)
+		tc.GivenAControlledJob(func(controlledJob *v1.ControlledJob) {
+			suspend := true
+			controlledJob.Spec.Suspend = &suspend
+		})
+		tc.GivenAnExistingJob(WithJobName("mondays-job"), metadata.WithControlledJobAnnotations(monday9am, 0, false, DefaultJobTemplate()))
+
+		decision := tc.WhenPlannedAt(monday10am)
+
+		assert.Len(tc, decision.JobsToDelete, 1)
+		assert.Equal(tc, "the ControlledJob is suspended", decision.Reasons["mondays-job"])
+	})
+}
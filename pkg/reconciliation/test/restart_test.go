@@ -0,0 +1,199 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/events"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+func Test_ScheduledRestarts(t *testing.T) {
+	// Monday 12th December 2022
+	var startTime = time.Date(2022, time.December, 12, 9, 0, 0, 0, time.UTC)
+	var beforeRestart = time.Date(2022, time.December, 12, 11, 0, 0, 0, time.UTC)
+	var restartTime = time.Date(2022, time.December, 12, 12, 0, 0, 0, time.UTC)
+	var afterRestart = time.Date(2022, time.December, 12, 12, 5, 0, 0, time.UTC)
+	var stopTime = time.Date(2022, time.December, 12, 17, 0, 0, 0, time.UTC)
+	var afterStop = time.Date(2022, time.December, 12, 18, 0, 0, 0, time.UTC)
+
+	var givenControlledJobWithRestart = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("restart-test"),
+			WithDefaultJobTemplate(),
+			WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+			WithScheduledEvent(v1.EventTypeRestart, "MON-FRI", "12:00"),
+			WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+		)
+	}
+
+	Run(t, "before the restart time", func(tc *testContext) {
+		tc.Run("running job is left alone", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0), WithActiveCount(1)),
+			)
+
+			tc.WhenReconcileIsRunAt(beforeRestart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+			// Wake up again at the restart time
+			tc.ShouldHaveBeenRequeuedAt(restartTime)
+		})
+	})
+
+	Run(t, "at the restart time", func(tc *testContext) {
+		tc.Run("running job is replaced by a new suspended job in the same run period", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0), WithActiveCount(1)),
+			)
+
+			tc.WhenReconcileIsRunAt(restartTime)
+
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedJobName(metadata.JobName("restart-test", startTime, 1)),
+				WithExpectedScheduledTime(startTime),
+				WithExpectedJobIndex(1),
+				ThatShouldBeSuspended(),
+			)
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("restart-test-0"))
+			tc.ShouldNotHaveUnsuspendedAJob()
+			tc.ShouldHaveRecordedEvent(string(events.EventJobRestarted))
+			tc.ShouldHaveBeenRequeuedAt(stopTime)
+		})
+
+		tc.Run("completed job is also replaced", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0), HasSucceeded()),
+			)
+
+			tc.WhenReconcileIsRunAt(restartTime)
+
+			// As the old job has already finished, the new job can be started straight away
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedJobIndex(1),
+				WithExpectedSuspendedFlag(nil),
+			)
+			tc.ShouldNotHaveDeletedAJob()
+		})
+
+		tc.Run("job stopped by the user is not restarted", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0),
+					IsSuspended(true),
+					WithJobAnnotation(metadata.SuspendReason, "user-stop")),
+			)
+
+			tc.WhenReconcileIsRunAt(restartTime)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+		})
+
+		tc.Run("job being deleted is not restarted", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0), IsBeingDeleted()),
+			)
+
+			tc.WhenReconcileIsRunAt(restartTime)
+
+			tc.ShouldNotHaveCreatedAJob()
+		})
+	})
+
+	Run(t, "after the restart time", func(tc *testContext) {
+		tc.Run("new job is unsuspended once the old job has gone", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-1", jobScheduledAt("restart-test", startTime, 1),
+					IsSuspended(true),
+					metadata.WithRestartedAtAnnotation(restartTime)),
+			)
+
+			tc.WhenReconcileIsRunAt(afterRestart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+			tc.ShouldHaveUnsuspendedAJob(WithExpectedJobName("restart-test-1"))
+		})
+
+		tc.Run("new job is not unsuspended while the old job is still terminating", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0), IsBeingDeleted()),
+				NewJob("restart-test-1", jobScheduledAt("restart-test", startTime, 1),
+					IsSuspended(true),
+					metadata.WithRestartedAtAnnotation(restartTime)),
+			)
+
+			tc.WhenReconcileIsRunAt(afterRestart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveUnsuspendedAJob()
+		})
+
+		tc.Run("first reconcile happens after the restart event", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+
+			tc.WhenReconcileIsRunAt(afterRestart)
+
+			// The new job starts after the restart event, so records it to avoid being restarted for it
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedJobName(metadata.JobName("restart-test", startTime, 0)),
+				WithExpectedScheduledTime(startTime),
+				WithExpectedRestartedAt(restartTime),
+			)
+			tc.ShouldNotHaveDeletedAJob()
+		})
+
+		tc.Run("job created after the restart event is not restarted", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-0", jobScheduledAt("restart-test", startTime, 0),
+					WithActiveCount(1),
+					metadata.WithRestartedAtAnnotation(restartTime)),
+			)
+
+			tc.WhenReconcileIsRunAt(afterRestart.Add(time.Minute))
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+		})
+
+		tc.Run("manually created job started after the restart is left alone", func(tc *testContext) {
+			givenControlledJobWithRestart(tc)
+			tc.GivenExistingJobs(
+				NewJob("restart-test-manual", metadata.WithControlledJobAnnotations(afterRestart, 0, true, DefaultJobTemplate())),
+			)
+
+			tc.WhenReconcileIsRunAt(afterRestart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+		})
+	})
+
+	Run(t, "outside of the run period", func(tc *testContext) {
+		tc.Run("a restart event does not start a job", func(tc *testContext) {
+			tc.GivenAControlledJob(
+				WithControlledJobName("restart-test"),
+				WithDefaultJobTemplate(),
+				WithScheduledEvent(v1.EventTypeStart, "MON-FRI", "09:00"),
+				WithScheduledEvent(v1.EventTypeStop, "MON-FRI", "17:00"),
+				WithScheduledEvent(v1.EventTypeRestart, "MON-FRI", "17:30"),
+			)
+
+			tc.WhenReconcileIsRunAt(afterStop)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+		})
+	})
+}
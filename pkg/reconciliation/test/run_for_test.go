@@ -0,0 +1,89 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_RunFor(t *testing.T) {
+	// Run overnight from 22:00 on each weekday for 10 hours. 2022-12-12 is a Monday
+	var startTime = time.Date(2022, time.December, 12, 22, 0, 0, 0, time.UTC)
+	var overnight = time.Date(2022, time.December, 13, 3, 0, 0, 0, time.UTC)
+	var impliedStopTime = time.Date(2022, time.December, 13, 8, 0, 0, 0, time.UTC)
+	var nextStartTime = time.Date(2022, time.December, 13, 22, 0, 0, 0, time.UTC)
+	var fridayStartTime = time.Date(2022, time.December, 16, 22, 0, 0, 0, time.UTC)
+	var saturdayMorning = time.Date(2022, time.December, 17, 7, 0, 0, 0, time.UTC)
+	var saturdayImpliedStopTime = time.Date(2022, time.December, 17, 8, 0, 0, 0, time.UTC)
+
+	var givenOvernightControlledJob = func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("run-for-test"),
+			WithDefaultJobTemplate(),
+			WithCronStartEventRunningFor("0 22 * * MON-FRI", 10*time.Hour),
+		)
+	}
+
+	Run(t, "a job is started at the start event", func(tc *testContext) {
+		givenOvernightControlledJob(tc)
+
+		tc.WhenReconcileIsRunAt(startTime)
+
+		tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTime))
+		tc.ShouldHaveBeenRequeuedAt(impliedStopTime)
+	})
+
+	Run(t, "the job keeps running across midnight", func(tc *testContext) {
+		givenOvernightControlledJob(tc)
+		tc.GivenExistingJobs(NewJob("run-for-test-0", jobScheduledAt("run-for-test", startTime, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(overnight)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldNotHaveDeletedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+		tc.ShouldHaveBeenRequeuedAt(impliedStopTime)
+	})
+
+	Run(t, "the job is stopped at the implied stop", func(tc *testContext) {
+		givenOvernightControlledJob(tc)
+		tc.GivenExistingJobs(NewJob("run-for-test-0", jobScheduledAt("run-for-test", startTime, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(impliedStopTime)
+
+		tc.ShouldHaveDeletedAJob(WithExpectedJobName("run-for-test-0"))
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+		tc.ShouldHaveBeenRequeuedAt(nextStartTime)
+	})
+
+	Run(t, "the run started on a Friday carries on into the Saturday", func(tc *testContext) {
+		givenOvernightControlledJob(tc)
+		tc.GivenExistingJobs(NewJob("run-for-test-0", jobScheduledAt("run-for-test", fridayStartTime, 0), WithActiveCount(1)))
+
+		tc.WhenReconcileIsRunAt(saturdayMorning)
+
+		tc.ShouldNotHaveDeletedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+		tc.ShouldHaveBeenRequeuedAt(saturdayImpliedStopTime)
+	})
+
+	Run(t, "runFor is rejected on a stop event", func(tc *testContext) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("run-for-test"),
+			WithDefaultJobTemplate(),
+			WithCronEvent(v1.EventTypeStart, "0 22 * * MON-FRI"),
+			WithCronEvent(v1.EventTypeStop, "0 8 * * MON-FRI"),
+			func(controlledJob *v1.ControlledJob) {
+				controlledJob.Spec.Events[1].RunFor = &metav1.Duration{Duration: time.Hour}
+			},
+		)
+
+		tc.WhenReconcileIsRunAt(startTime)
+
+		tc.ShouldNotHaveCreatedAJob()
+		tc.ShouldHaveCondition(v1.ConditionTypeError, "True")
+	})
+}
@@ -0,0 +1,163 @@
+package reconciletests
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/G-Research/controlled-job/api/v1"
+	. "github.com/G-Research/controlled-job/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StartOnlySchedules(t *testing.T) {
+	// Start a new job every hour, on the hour
+	var previousStartTime = time.Date(2022, time.December, 12, 9, 0, 0, 0, time.UTC)
+	var startTime = time.Date(2022, time.December, 12, 10, 0, 0, 0, time.UTC)
+	var afterStart = time.Date(2022, time.December, 12, 10, 15, 0, 0, time.UTC)
+	var nextStartTime = time.Date(2022, time.December, 12, 11, 0, 0, 0, time.UTC)
+
+	var givenStartOnlyControlledJob = func(tc *testContext, policy v1.ConcurrencyPolicy, opts ...ControlledJobOption) {
+		tc.GivenAControlledJob(append([]ControlledJobOption{
+			WithControlledJobName("start-only-test"),
+			WithDefaultJobTemplate(),
+			WithCronEvent(v1.EventTypeStart, "0 * * * *"),
+			WithConcurrencyPolicy(policy),
+		}, opts...)...)
+	}
+
+	Run(t, "without a concurrency policy", func(tc *testContext) {
+		tc.Run("start-only schedules are rejected", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, "")
+
+			tc.WhenReconcileIsRunAt(afterStart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeError, "True")
+		})
+	})
+
+	Run(t, "within a run period", func(tc *testContext) {
+		tc.Run("creates a job at the start event", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent)
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedScheduledTime(startTime),
+				WithExpectedJobIndex(0),
+				WithExpectedSuspendedFlag(nil),
+			)
+			tc.ShouldHaveBeenRequeuedAt(nextStartTime)
+		})
+
+		tc.Run("job runs to completion and is not recreated", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", startTime, 0), HasSucceeded()))
+
+			tc.WhenReconcileIsRunAt(afterStart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveDeletedAJob()
+		})
+	})
+
+	Run(t, "at the next start event", func(tc *testContext) {
+		tc.Run("completed jobs from earlier run periods are cleaned up", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), HasSucceeded()))
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("start-only-test-0"))
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedScheduledTime(startTime),
+				WithExpectedSuspendedFlag(nil),
+			)
+		})
+
+		tc.Run("Allow: new job runs alongside a job which is still running", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.AllowConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), WithActiveCount(1)))
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldNotHaveDeletedAJob()
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedScheduledTime(startTime),
+				WithExpectedSuspendedFlag(nil),
+			)
+		})
+
+		tc.Run("Forbid: run period is skipped while a job is still running", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), WithActiveCount(1)))
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldNotHaveDeletedAJob()
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeRunPeriodSkipped, "True")
+			tc.ShouldHaveCondition(v1.ConditionTypeStartingDeadlineExceeded, "Unknown")
+			if assert.NotNil(tc, tc.currentReconcileRun.status.SkippedRunPeriodStartTime) {
+				assert.Equal(tc, startTime, tc.currentReconcileRun.status.SkippedRunPeriodStartTime.Time.UTC())
+			}
+			tc.ShouldHaveBeenRequeuedAt(nextStartTime)
+		})
+
+		tc.Run("Forbid: skipped run period stays skipped once the earlier job finishes", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent, WithSkippedRunPeriod(startTime))
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), HasSucceeded()))
+
+			tc.WhenReconcileIsRunAt(afterStart)
+
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("start-only-test-0"))
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldHaveCondition(v1.ConditionTypeRunPeriodSkipped, "True")
+		})
+
+		tc.Run("Forbid: new job is started if the earlier job finished before the run period was checked", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), HasSucceeded()))
+
+			tc.WhenReconcileIsRunAt(afterStart)
+
+			tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTime))
+			tc.ShouldHaveCondition(v1.ConditionTypeRunPeriodSkipped, "False")
+		})
+
+		tc.Run("Forbid: run period after a skipped one starts a job", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ForbidConcurrent, WithSkippedRunPeriod(previousStartTime))
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(startTime))
+			tc.ShouldHaveCondition(v1.ConditionTypeRunPeriodSkipped, "False")
+		})
+
+		tc.Run("Replace: job which is still running is stopped before the new one starts", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ReplaceConcurrent)
+			tc.GivenExistingJobs(NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), WithActiveCount(1)))
+
+			tc.WhenReconcileIsRunAt(startTime)
+
+			tc.ShouldHaveDeletedAJob(WithExpectedJobName("start-only-test-0"))
+			tc.ShouldHaveCreatedAJob(
+				WithExpectedScheduledTime(startTime),
+				ThatShouldBeSuspended(),
+			)
+		})
+
+		tc.Run("Replace: new job is not unsuspended while the earlier job is terminating", func(tc *testContext) {
+			givenStartOnlyControlledJob(tc, v1.ReplaceConcurrent)
+			tc.GivenExistingJobs(
+				NewJob("start-only-test-0", jobScheduledAt("start-only-test", previousStartTime, 0), IsBeingDeleted()),
+				NewJob("start-only-test-1", jobScheduledAt("start-only-test", startTime, 0), IsSuspended(true)),
+			)
+
+			tc.WhenReconcileIsRunAt(afterStart)
+
+			tc.ShouldNotHaveCreatedAJob()
+			tc.ShouldNotHaveUnsuspendedAJob()
+		})
+	})
+}
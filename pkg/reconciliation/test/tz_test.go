@@ -49,4 +49,124 @@ func Test_TimezoneSupport(t *testing.T) {
 
 		tc.ShouldNotHaveCreatedAJob()
 	})
+
+	Run(t, "Events with their own timezones", func(tc *testContext) {
+
+		// Start at 08:00 in London, and stop at 16:30 in New York
+		tc.GivenAControlledJob(
+			WithTimezone("UTC", 0),
+			WithCronEventInTimezone(v1.EventTypeStart, "0 8 * * MON-FRI", "Europe/London"),
+			WithCronEventInTimezone(v1.EventTypeStop, "30 16 * * MON-FRI", "America/New_York"),
+			WithDefaultJobTemplate(),
+		)
+		// 2022-04-22 12:00 (UTC) = 2022-04-22 13:00 (Europe/London) = 2022-04-22 08:00 (America/New_York)
+		tc.WhenReconcileIsRunAt(time.Date(2022, time.April, 22, 12, 0, 0, 0, time.UTC))
+
+		// Wake again at 16:30 in New York == 20:30 UTC
+		tc.ShouldHaveBeenRequeuedAt(time.Date(2022, time.April, 22, 20, 30, 0, 0, time.UTC))
+
+		// 08:00 in London == 07:00 UTC, and the job records the timezone of the start event
+		tc.ShouldHaveCreatedAJob(
+			WithExpectedScheduledTime(time.Date(2022, time.April, 22, 7, 0, 0, 0, time.UTC)),
+			WithExpectedTimeZone("Europe/London"))
+	})
+}
+
+func Test_DSTPolicy(t *testing.T) {
+	// Run from 01:30 until 04:00 every day in London. The clocks go forward from 01:00 to 02:00 on 2022-03-27, so 01:30
+	// doesn't happen that day, and go back from 02:00 to 01:00 on 2022-10-30, so 01:30 happens twice that day
+	var givenA0130LondonControlledJob = func(tc *testContext, skippedTimes v1.DSTSkippedTimePolicy, repeatedTimes v1.DSTRepeatedTimePolicy) {
+		tc.GivenAControlledJob(
+			WithControlledJobName("dst-test"),
+			WithTimezone("Europe/London", 0),
+			WithDSTPolicy(skippedTimes, repeatedTimes),
+			WithCronEvent(v1.EventTypeStart, "30 1 * * *"),
+			WithCronEvent(v1.EventTypeStop, "0 4 * * *"),
+			WithDefaultJobTemplate(),
+		)
+	}
+
+	// 02:30 BST, the first instant after where 01:30 would have been
+	var springShiftedStart = time.Date(2022, time.March, 27, 1, 30, 0, 0, time.UTC)
+	// 04:00 BST
+	var springStop = time.Date(2022, time.March, 27, 3, 0, 0, 0, time.UTC)
+	// 01:30 BST
+	var autumnFirstStart = time.Date(2022, time.October, 30, 0, 30, 0, 0, time.UTC)
+	// 01:30 GMT
+	var autumnLastStart = time.Date(2022, time.October, 30, 1, 30, 0, 0, time.UTC)
+	// 04:00 GMT
+	var autumnStop = time.Date(2022, time.October, 30, 4, 0, 0, 0, time.UTC)
+
+	springCases := map[v1.DSTSkippedTimePolicy]bool{
+		"":                 false,
+		v1.DSTSkip:         false,
+		v1.DSTShiftForward: true,
+	}
+	for policy, shouldStart := range springCases {
+		policy, shouldStart := policy, shouldStart
+		Run(t, "clocks go forward with skippedTimes="+string(policy), func(tc *testContext) {
+			givenA0130LondonControlledJob(tc, policy, "")
+
+			tc.WhenReconcileIsRunAt(springShiftedStart)
+
+			if shouldStart {
+				tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(springShiftedStart))
+				tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+			} else {
+				tc.ShouldNotHaveCreatedAJob()
+				tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "False")
+			}
+			tc.ShouldHaveBeenRequeuedAt(springStop)
+		})
+	}
+
+	autumnCases := map[v1.DSTRepeatedTimePolicy]struct {
+		startsAtFirst bool
+		startsAtLast  bool
+	}{
+		"":                    {startsAtFirst: true, startsAtLast: true},
+		v1.DSTBothOccurrences: {startsAtFirst: true, startsAtLast: true},
+		v1.DSTFirstOccurrence: {startsAtFirst: true},
+		v1.DSTLastOccurrence:  {startsAtLast: true},
+	}
+	for policy, expected := range autumnCases {
+		policy, expected := policy, expected
+		Run(t, "clocks go back with repeatedTimes="+string(policy), func(tc *testContext) {
+			tc.Run("the first 01:30", func(tc *testContext) {
+				givenA0130LondonControlledJob(tc, "", policy)
+
+				tc.WhenReconcileIsRunAt(autumnFirstStart)
+
+				if expected.startsAtFirst {
+					tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(autumnFirstStart))
+				} else {
+					tc.ShouldNotHaveCreatedAJob()
+				}
+				if expected.startsAtLast {
+					tc.ShouldHaveBeenRequeuedAt(autumnLastStart)
+				} else {
+					tc.ShouldHaveBeenRequeuedAt(autumnStop)
+				}
+			})
+
+			tc.Run("the second 01:30", func(tc *testContext) {
+				givenA0130LondonControlledJob(tc, "", policy)
+				if expected.startsAtFirst {
+					tc.GivenExistingJobs(NewJob("dst-test-0", jobScheduledAt("dst-test", autumnFirstStart, 0), WithActiveCount(1)))
+				}
+
+				tc.WhenReconcileIsRunAt(autumnLastStart)
+
+				if expected.startsAtFirst {
+					// Whether or not the second 01:30 happens, we're still in the run period which began at the first
+					tc.ShouldNotHaveCreatedAJob()
+					tc.ShouldNotHaveDeletedAJob()
+				} else {
+					tc.ShouldHaveCreatedAJob(WithExpectedScheduledTime(autumnLastStart))
+				}
+				tc.ShouldHaveCondition(v1.ConditionTypeShouldBeRunning, "True")
+				tc.ShouldHaveBeenRequeuedAt(autumnStop)
+			})
+		})
+	}
 }
@@ -63,6 +63,7 @@ type testContext struct {
 	// What's the current state of the cluster?
 	controlledJob *batch.ControlledJob
 	existingJobs  []kbatch.Job
+	calendars     map[batch.CalendarReference]*batch.CalendarSpec
 
 	// Mocks of the K8s interaction
 	client        *clientadapter.ControlledJobClientMock
@@ -135,6 +136,7 @@ func newTest(t *testing.T) *testContext {
 		T:                   t,
 		controlledJob:       testhelpers.NewControlledJob("my-controlled-job"),
 		existingJobs:        []kbatch.Job{},
+		calendars:           map[batch.CalendarReference]*batch.CalendarSpec{},
 		client:              client,
 		currentReconcileRun: nil,
 		reconcileRuns:       []*reconcileRun{},
@@ -154,6 +156,12 @@ func newTest(t *testing.T) *testContext {
 		}
 		return kbatch.JobList{}, nil
 	}
+	client.GetCalendarFunc = func(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+		if calendar, ok := tc.calendars[ref]; ok {
+			return calendar, nil
+		}
+		return nil, fmt.Errorf("calendar %s not found", ref.Name)
+	}
 	client.CreateJobFunc = func(ctx context.Context, job *kbatch.Job) error {
 		tc.currentReconcileRun.jobsCreated = append(tc.currentReconcileRun.jobsCreated, job)
 		return nil
@@ -199,6 +207,11 @@ func (tc *testContext) GivenAControlledJob(opts ...testhelpers.ControlledJobOpti
 	}
 }
 
+// GivenACalendar adds a calendar to the cluster, which ControlledJobs can reference using the given kind and name
+func (tc *testContext) GivenACalendar(kind, name string, calendar batch.CalendarSpec) {
+	tc.calendars[batch.CalendarReference{Kind: kind, Name: name}] = &calendar
+}
+
 func (tc *testContext) GivenExistingJobs(jobs ...*kbatch.Job) {
 	for _, job := range jobs {
 		tc.existingJobs = append(tc.existingJobs, *job)
@@ -210,6 +223,12 @@ func (tc *testContext) GivenAnExistingJob(opts ...testhelpers.JobOption) {
 	tc.existingJobs = append(tc.existingJobs, *newJob)
 }
 
+// jobScheduledAt gives a Job the metadata the reconciler would have given it when creating it for the named
+// ControlledJob's run period starting at scheduledTime, using the default job template
+func jobScheduledAt(controlledJobName string, scheduledTime time.Time, jobIdx int) testhelpers.JobOption {
+	return metadata.WithControlledJobMetadata(controlledJobName, "1234", scheduledTime, jobIdx, testhelpers.DefaultJobTemplate())
+}
+
 // WhenReconcileIsRunAt initiates a run of the reconcile logic at the given time. It will record the result, any error, and
 // any jobs that were created, updated or deleted
 func (tc *testContext) WhenReconcileIsRunAt(now time.Time) *reconcileRun {
@@ -240,6 +259,15 @@ func (tc *testContext) WhenReconcileIsRunAt(now time.Time) *reconcileRun {
 	return tc.currentReconcileRun
 }
 
+// WhenPlannedAt works out what the reconciler would do at the given time with reconciliation.Plan, which doesn't touch
+// the cluster
+func (tc *testContext) WhenPlannedAt(now time.Time) reconciliation.Decision {
+	jobList := kbatch.JobList{Items: tc.existingJobs}
+	decision, err := reconciliation.Plan(context.Background(), tc.controlledJob.DeepCopy(), nil, jobList.DeepCopy(), now, reconciliation.ReconcileOptions{})
+	assert.Nil(tc, err)
+	return decision
+}
+
 func (tc *testContext) WithTestMutator(image string, err error) *testMutator {
 	tc.mutator = &testMutator{
 		image: image,
@@ -343,6 +371,13 @@ func WithExpectedScheduledTime(expectedScheduledTime time.Time) JobExpectation {
 	}
 }
 
+func WithExpectedTimeZone(expectedTimeZone string) JobExpectation {
+	return func(t assert.TestingT, job kbatch.Job) {
+		annotationValue := job.Annotations[metadata.TimeZoneAnnotation]
+		assert.Equal(t, expectedTimeZone, annotationValue, "timezone annotation should match")
+	}
+}
+
 func WithExpectedJobIndex(expectedIndex int) JobExpectation {
 	return func(t assert.TestingT, job kbatch.Job) {
 		annotationValue := job.Annotations[metadata.JobRunIdAnnotation]
@@ -350,6 +385,21 @@ func WithExpectedJobIndex(expectedIndex int) JobExpectation {
 	}
 }
 
+func WithExpectedFailureRestartCount(expectedCount int) JobExpectation {
+	return func(t assert.TestingT, job kbatch.Job) {
+		annotationValue := job.Annotations[metadata.FailureRestartCountAnnotation]
+		assert.Equal(t, fmt.Sprintf("%d", expectedCount), annotationValue, "failure restart count annotation should match")
+	}
+}
+
+func WithExpectedRestartedAt(expectedRestartedAt time.Time) JobExpectation {
+	expectedAnnotationValue := expectedRestartedAt.Format(time.RFC3339)
+	return func(t assert.TestingT, job kbatch.Job) {
+		annotationValue := job.Annotations[metadata.RestartedAtAnnotation]
+		assert.Equal(t, expectedAnnotationValue, annotationValue, "restarted at annotation should match")
+	}
+}
+
 func WithExpectedControlledJobOwner(expectedControlledJobName string) JobExpectation {
 	return func(t assert.TestingT, job kbatch.Job) {
 		assert.Equal(t, 1, len(job.OwnerReferences), "should have a single owner reference")
@@ -433,6 +483,15 @@ func (tc *testContext) ShouldHaveCondition(conditionType batch.ControlledJobCond
 	}
 }
 
+func (tc *testContext) ShouldHaveRecordedEvent(reason string) {
+	for _, event := range tc.currentReconcileRun.events {
+		if event.Reason == reason {
+			return
+		}
+	}
+	assert.Fail(tc, "expected event to have been recorded", "reason %s, recorded events: %v", reason, tc.currentReconcileRun.events)
+}
+
 func timeBetweenNowAndThen(now, requeue time.Time) time.Duration {
 	return requeue.Sub(now)
 }
@@ -0,0 +1,300 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// AnalysisPeriod is how far ahead Analyze looks for problems. Five weeks is long enough for every day of the week,
+// and almost every day of the month, to come round at least once
+const AnalysisPeriod = 5 * 7 * 24 * time.Hour
+
+// maxAnalysedInstants stops the analysis of a schedule with very frequent events from taking too long
+const maxAnalysedInstants = 10000
+
+// WarningType is the kind of problem described by a ScheduleWarning
+type WarningType string
+
+const (
+	// WarningSimultaneousStartAndStop is a start and a stop event at the same instant while the ControlledJob is
+	// running, without an eventPrecedence to say whether it carries on running. The stop event wins, so it stops
+	WarningSimultaneousStartAndStop WarningType = "SimultaneousStartAndStop"
+	// WarningZeroLengthWindow is a start and a stop event at the same instant while the ControlledJob isn't running,
+	// without an eventPrecedence to say whether it starts. The stop event wins, so it doesn't
+	WarningZeroLengthWindow WarningType = "ZeroLengthWindow"
+	// WarningRedundantStart is a start event which only ever happens while the ControlledJob is already running
+	WarningRedundantStart WarningType = "RedundantStart"
+	// WarningUnmatchedStop is a stop event which only ever happens while the ControlledJob isn't running
+	WarningUnmatchedStop WarningType = "UnmatchedStop"
+	// WarningCalendarExpiring is a session event whose exchange's trading calendar runs out before the end of the
+	// AnalysisPeriod. After that, the ControlledJob can't be scheduled at all
+	WarningCalendarExpiring WarningType = "CalendarExpiring"
+)
+
+// ScheduleWarning describes a problem with a schedule which doesn't stop it from working, but probably means it
+// doesn't do what was intended
+type ScheduleWarning struct {
+	Type WarningType
+	// Time is the first time the problem happens
+	Time time.Time
+	// Occurrences is how many times the problem happens in the AnalysisPeriod
+	Occurrences int
+	// Events are the events involved in the problem
+	Events []Boundary
+	// Message describes the problem
+	Message string
+}
+
+// AnalyzeSchedule builds the schedule of the given ControlledJob, and looks for problems in it (see Analyze).
+// calendarSpec is the spec of the Calendar or ClusterCalendar referenced by the ControlledJob, or nil if it doesn't reference one
+func AnalyzeSchedule(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, from time.Time) ([]ScheduleWarning, error) {
+	compiled, err := Compile(controlledJob, calendarSpec)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Analyze(from), nil
+}
+
+// Analyze looks for problems in the schedule in the AnalysisPeriod after the given time, by stepping through each
+// instant at which it has start or stop events:
+//
+// - a start and a stop event at the same instant, without an eventPrecedence. The stop event wins, which may not be
+// what was intended
+//
+// - start events which never start a run period, because the ControlledJob is always already running
+//
+// - stop events which never stop a run period, because the ControlledJob is never running
+//
+// - session events whose exchange's trading calendar runs out, so that the ControlledJob will soon stop being scheduled
+//
+// Start-only schedules have no stop events, and each start event begins a new run period, so they never have any
+// of the problems other than the calendar running out. The warnings are in order of when they first happen
+func (c *CompiledSchedule) Analyze(from time.Time) []ScheduleWarning {
+	to := from.Add(AnalysisPeriod)
+	warnings := c.calendarExpiryWarnings(to)
+	if c.isStartOnly {
+		return c.describeWarnings(warnings, to)
+	}
+
+	running := false
+	if window, err := c.WindowAt(from.Add(-time.Nanosecond)); err == nil && window != nil {
+		running = true
+	}
+
+	nextTimes := make([]time.Time, len(c.compiled))
+	for i, event := range c.compiled {
+		if event.action != batch.EventTypeRestart {
+			nextTimes[i] = event.schedule.next(from.Add(-time.Nanosecond))
+		}
+	}
+
+	// Occurrences of events involved in a tie which isn't settled by the precedence aren't counted here, so that the
+	// same problem isn't also reported as a redundant start or unmatched stop
+	occurrences := make([]int, len(c.compiled))
+	effective := make([]int, len(c.compiled))
+	firstOccurrences := make([]time.Time, len(c.compiled))
+
+	tieWarnings := map[string]*ScheduleWarning{}
+
+	for instants := 0; instants < maxAnalysedInstants; instants++ {
+		var now time.Time
+		for _, next := range nextTimes {
+			if !next.IsZero() && next.Before(to) && (now.IsZero() || next.Before(now)) {
+				now = next
+			}
+		}
+		if now.IsZero() {
+			break
+		}
+
+		var starts, stops []int
+		for i, next := range nextTimes {
+			if !next.Equal(now) {
+				continue
+			}
+			if c.compiled[i].action == batch.EventTypeStart {
+				starts = append(starts, i)
+			} else {
+				stops = append(stops, i)
+			}
+			nextTimes[i] = c.compiled[i].schedule.next(now)
+		}
+
+		if len(starts) > 0 && len(stops) > 0 && c.precedence == "" {
+			warningType := WarningZeroLengthWindow
+			if running {
+				warningType = WarningSimultaneousStartAndStop
+			}
+			key := fmt.Sprint(warningType, starts, stops)
+			if warning, ok := tieWarnings[key]; ok {
+				warning.Occurrences++
+			} else {
+				warning := c.newWarning(warningType, now, append(starts, stops...))
+				tieWarnings[key] = warning
+				warnings = append(warnings, warning)
+			}
+			// Run windows treat the start event as if it didn't happen, so carry on as if the stop won
+			running = false
+			continue
+		}
+
+		for _, i := range append(starts, stops...) {
+			if occurrences[i] == 0 {
+				firstOccurrences[i] = now
+			}
+			occurrences[i]++
+		}
+		if running && len(stops) > 0 {
+			for _, i := range stops {
+				effective[i]++
+			}
+			running = false
+			if len(starts) > 0 && c.precedence == batch.StartEventPrecedence {
+				// A new run period starts straight away
+				for _, i := range starts {
+					effective[i]++
+				}
+				running = true
+			}
+			continue
+		}
+		if !running && len(starts) > 0 && !(len(stops) > 0 && c.precedence == batch.StopEventPrecedence) {
+			for _, i := range starts {
+				effective[i]++
+			}
+			running = true
+		}
+	}
+
+	for i, event := range c.compiled {
+		if event.source != EventSourceEvent || occurrences[i] == 0 || effective[i] > 0 {
+			continue
+		}
+		warningType := WarningRedundantStart
+		if event.action == batch.EventTypeStop {
+			warningType = WarningUnmatchedStop
+		}
+		warning := c.newWarning(warningType, firstOccurrences[i], []int{i})
+		warning.Occurrences = occurrences[i]
+		warnings = append(warnings, warning)
+	}
+
+	return c.describeWarnings(warnings, to)
+}
+
+// calendarExpiryWarnings returns a warning for each exchange whose trading calendar is used by session events, but
+// runs out before the given time
+func (c *CompiledSchedule) calendarExpiryWarnings(to time.Time) []*ScheduleWarning {
+	var warnings []*ScheduleWarning
+	warningsByExchange := map[*exchange]*ScheduleWarning{}
+	for i, event := range c.events {
+		if event.Session == nil {
+			continue
+		}
+		exchange, err := exchangeCalendars.get(event.Session.Exchange)
+		if err != nil || !exchange.endOfCoverage().Before(to) {
+			continue
+		}
+		warning, ok := warningsByExchange[exchange]
+		if !ok {
+			warning = &ScheduleWarning{Type: WarningCalendarExpiring, Time: exchange.endOfCoverage(), Occurrences: 1}
+			warningsByExchange[exchange] = warning
+			warnings = append(warnings, warning)
+		}
+		warning.Events = append(warning.Events, Boundary{Time: warning.Time, Source: EventSourceEvent, EventIndex: i})
+	}
+	return warnings
+}
+
+// describeWarnings sorts the warnings by when they first happen, and fills in their messages
+func (c *CompiledSchedule) describeWarnings(warnings []*ScheduleWarning, to time.Time) []ScheduleWarning {
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Time.Before(warnings[j].Time)
+	})
+	var result []ScheduleWarning
+	for _, warning := range warnings {
+		warning.Message = c.describeWarning(*warning, to)
+		result = append(result, *warning)
+	}
+	return result
+}
+
+func (c *CompiledSchedule) newWarning(warningType WarningType, t time.Time, compiledIndexes []int) *ScheduleWarning {
+	result := &ScheduleWarning{Type: warningType, Time: t, Occurrences: 1}
+	for _, i := range compiledIndexes {
+		result.Events = append(result.Events, Boundary{Time: t, Source: c.compiled[i].source, EventIndex: c.compiled[i].eventIndex})
+	}
+	return result
+}
+
+func (c *CompiledSchedule) describeWarning(warning ScheduleWarning, to time.Time) string {
+	events := make([]string, len(warning.Events))
+	for i, event := range warning.Events {
+		events[i] = c.describeEvent(event)
+	}
+	if warning.Type == WarningCalendarExpiring {
+		return c.describeCalendarExpiry(warning, events)
+	}
+	var occurrences string
+	switch warning.Occurrences {
+	case 1:
+		occurrences = "once"
+	case 2:
+		occurrences = "twice"
+	default:
+		occurrences = fmt.Sprintf("%d times", warning.Occurrences)
+	}
+	period := fmt.Sprintf("%s between %s and %s", occurrences, formatTime(warning.Time), formatTime(to))
+
+	switch warning.Type {
+	case WarningSimultaneousStartAndStop:
+		return fmt.Sprintf("%s happen at the same instant while the ControlledJob is running, %s. The stop event wins, so it stops: set eventPrecedence to say which should win.",
+			joinWithAnd(events), period)
+	case WarningZeroLengthWindow:
+		return fmt.Sprintf("%s happen at the same instant while the ControlledJob isn't running, %s, making a run period of zero length. The stop event wins, so it doesn't start: set eventPrecedence to say which should win.",
+			joinWithAnd(events), period)
+	case WarningRedundantStart:
+		return fmt.Sprintf("%s happens %s, but the ControlledJob is always already running, so it has no effect.", events[0], period)
+	default:
+		return fmt.Sprintf("%s happens %s, but the ControlledJob is never running, so it has no effect.", events[0], period)
+	}
+}
+
+func (c *CompiledSchedule) describeCalendarExpiry(warning ScheduleWarning, events []string) string {
+	verb := "use"
+	if len(events) == 1 {
+		verb = "uses"
+	}
+	exchange, err := exchangeCalendars.get(c.events[warning.Events[0].EventIndex].Session.Exchange)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s %s the trading calendar for %s (version %s), which only covers dates up to %s, so the ControlledJob can't be scheduled after %s. Upgrade the operator to get a newer calendar, or extend it with an override file.",
+		joinWithAnd(events), verb, exchange.mic, exchange.version, exchange.validTo.Format(batch.ExclusionDateFormat), formatTime(warning.Time))
+}
+
+func (c *CompiledSchedule) describeEvent(event Boundary) string {
+	switch event.Source {
+	case EventSourceRunFor:
+		return fmt.Sprintf("the stop implied by the runFor of events[%d]", event.EventIndex)
+	case EventSourceEarlyClose:
+		return "an early close in the calendar"
+	default:
+		return fmt.Sprintf("events[%d] (%s)", event.EventIndex, c.events[event.EventIndex].Action)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func joinWithAnd(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
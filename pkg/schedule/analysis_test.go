@@ -0,0 +1,181 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+func Test_CompiledSchedule_Analyze(t *testing.T) {
+	start := func(cronSchedule string) batch.EventSpec {
+		return batch.EventSpec{Action: batch.EventTypeStart, CronSchedule: cronSchedule}
+	}
+	stop := func(cronSchedule string) batch.EventSpec {
+		return batch.EventSpec{Action: batch.EventTypeStop, CronSchedule: cronSchedule}
+	}
+	event := func(source EventSource, index int, t time.Time) Boundary {
+		return Boundary{Time: t, Source: source, EventIndex: index}
+	}
+	weekdays := batch.ControlledJobSpec{Events: []batch.EventSpec{start("0 9 * * MON-FRI"), stop("0 17 * * MON-FRI")}}
+	dailyFor24Hours := batch.EventSpec{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *", RunFor: &metav1.Duration{Duration: 24 * time.Hour}}
+
+	testCases := map[string]struct {
+		spec     batch.ControlledJobSpec
+		calendar *batch.CalendarSpec
+		// expected lists the type, first time, number of occurrences and events of each warning
+		expected []ScheduleWarning
+	}{
+		"no problems": {
+			spec: weekdays,
+		},
+		"a start and a stop at the same instant while running": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{start("0 9 * * MON-FRI"), stop("0 9 * * FRI")}},
+			expected: []ScheduleWarning{{
+				Type: WarningSimultaneousStartAndStop, Time: june(7, 9, 0), Occurrences: 5,
+				Events: []Boundary{event(EventSourceEvent, 0, june(7, 9, 0)), event(EventSourceEvent, 1, june(7, 9, 0))},
+			}},
+		},
+		"a runFor which ends at the next start": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{dailyFor24Hours}},
+			expected: []ScheduleWarning{{
+				Type: WarningZeroLengthWindow, Time: june(3, 9, 0), Occurrences: 35,
+				Events: []Boundary{event(EventSourceEvent, 0, june(3, 9, 0)), event(EventSourceRunFor, 0, june(3, 9, 0))},
+			}},
+		},
+		"a runFor which ends at the next start, when start events take precedence": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{dailyFor24Hours}, EventPrecedence: batch.StartEventPrecedence},
+		},
+		"a runFor which ends at the next start, when stop events take precedence": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{dailyFor24Hours}, EventPrecedence: batch.StopEventPrecedence},
+			expected: []ScheduleWarning{{
+				Type: WarningRedundantStart, Time: june(3, 9, 0), Occurrences: 35,
+				Events: []Boundary{event(EventSourceEvent, 0, june(3, 9, 0))},
+			}},
+		},
+		"a start which always happens while running": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{start("0 9 * * MON-FRI"), start("0 10 * * MON-FRI"), stop("0 17 * * MON-FRI")}},
+			expected: []ScheduleWarning{{
+				Type: WarningRedundantStart, Time: june(3, 10, 0), Occurrences: 25,
+				Events: []Boundary{event(EventSourceEvent, 1, june(3, 10, 0))},
+			}},
+		},
+		"a stop which always happens while stopped": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{start("0 9 * * MON-FRI"), stop("0 17 * * MON-FRI"), stop("0 18 * * *")}},
+			expected: []ScheduleWarning{{
+				Type: WarningUnmatchedStop, Time: june(3, 18, 0), Occurrences: 35,
+				Events: []Boundary{event(EventSourceEvent, 2, june(3, 18, 0))},
+			}},
+		},
+		"a stop which only sometimes happens while stopped": {
+			spec:     weekdays,
+			calendar: &batch.CalendarSpec{EarlyCloses: []batch.EarlyCloseSpec{{Date: "2024-06-04", TimeOfDay: "12:30"}}},
+		},
+		"a start-only schedule": {
+			spec: batch.ControlledJobSpec{Events: []batch.EventSpec{start("0 9 * * *"), start("0 9 * * *")}, ConcurrencyPolicy: batch.AllowConcurrent},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sut := compileForTest(t, tc.spec, tc.calendar)
+
+			actual := sut.Analyze(june(3, 0, 0))
+
+			assert.Len(t, actual, len(tc.expected))
+			for i := range actual {
+				assert.NotEmpty(t, actual[i].Message)
+				actual[i].Message = ""
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_CompiledSchedule_Analyze_Message(t *testing.T) {
+	sut := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+			{Action: batch.EventTypeStop, CronSchedule: "0 9 * * FRI"},
+		},
+	}, nil)
+
+	actual := sut.Analyze(june(3, 0, 0))
+
+	assert.Equal(t, "events[0] (start) and events[1] (stop) happen at the same instant while the ControlledJob is running, 5 times between 2024-06-07T09:00:00Z and 2024-07-08T00:00:00Z. The stop event wins, so it stops: set eventPrecedence to say which should win.",
+		actual[0].Message)
+}
+
+func Test_CompiledSchedule_Analyze_CalendarExpiring(t *testing.T) {
+	lse, err := exchangeCalendars.get("XLON")
+	assert.Nil(t, err, "should find the bundled calendar")
+	endOfCoverage := lse.endOfCoverage()
+	session := func(action batch.EventType, anchor batch.SessionAnchor) batch.EventSpec {
+		return batch.EventSpec{Action: action, Session: &batch.SessionEventSpec{Exchange: "LSE", Anchor: anchor}}
+	}
+	startAndStop := batch.ControlledJobSpec{Events: []batch.EventSpec{
+		session(batch.EventTypeStart, batch.SessionAnchorOpen),
+		session(batch.EventTypeStop, batch.SessionAnchorClose),
+	}}
+	startOnly := batch.ControlledJobSpec{
+		Events:            []batch.EventSpec{session(batch.EventTypeStart, batch.SessionAnchorOpen)},
+		ConcurrencyPolicy: batch.ForbidConcurrent,
+	}
+
+	testCases := map[string]struct {
+		spec     batch.ControlledJobSpec
+		from     time.Time
+		expected []Boundary
+	}{
+		"calendar covers the whole analysis period": {
+			spec: startAndStop,
+			from: endOfCoverage.Add(-AnalysisPeriod),
+		},
+		"calendar runs out during the analysis period": {
+			spec: startAndStop,
+			from: endOfCoverage.Add(-AnalysisPeriod).Add(time.Hour),
+			expected: []Boundary{
+				{Time: endOfCoverage, Source: EventSourceEvent, EventIndex: 0},
+				{Time: endOfCoverage, Source: EventSourceEvent, EventIndex: 1},
+			},
+		},
+		"start-only schedule": {
+			spec: startOnly,
+			from: endOfCoverage.AddDate(0, 0, -7),
+			expected: []Boundary{
+				{Time: endOfCoverage, Source: EventSourceEvent, EventIndex: 0},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sut := compileForTest(t, tc.spec, nil)
+
+			actual := sut.Analyze(tc.from)
+
+			if tc.expected == nil {
+				assert.Empty(t, actual)
+				return
+			}
+			if assert.Len(t, actual, 1) {
+				assert.Equal(t, WarningCalendarExpiring, actual[0].Type)
+				assert.Equal(t, endOfCoverage, actual[0].Time)
+				assert.Equal(t, tc.expected, actual[0].Events)
+			}
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		sut := compileForTest(t, startOnly, nil)
+
+		actual := sut.Analyze(endOfCoverage.AddDate(0, 0, -7))
+
+		assert.Equal(t, fmt.Sprintf("events[0] (start) uses the trading calendar for XLON (version %s), which only covers dates up to %s, so the ControlledJob can't be scheduled after %s. Upgrade the operator to get a newer calendar, or extend it with an override file.",
+			lse.version, lse.validTo.Format(batch.ExclusionDateFormat), formatTime(endOfCoverage)), actual[0].Message)
+	})
+}
@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// Cache keeps the CompiledSchedule of each ControlledJob, so that it's only built again when the ControlledJob's
+// spec or its calendar change. It's safe for concurrent use
+type Cache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]cacheEntry
+}
+
+type cacheEntry struct {
+	// uid and generation identify the version of the ControlledJob's spec the schedule was compiled from
+	uid        types.UID
+	generation int64
+	// calendarSpec is the calendar the schedule was compiled with. Calendars are separate objects, so they can
+	// change without the ControlledJob's generation changing
+	calendarSpec *batch.CalendarSpec
+	compiled     *CompiledSchedule
+	// analysis is the result of the most recent Analyze of compiled, if there has been one
+	analysis *analysis
+}
+
+type analysis struct {
+	from     time.Time
+	warnings []ScheduleWarning
+}
+
+// reanalyzeAfter is how long the warnings from analyzing a schedule are reused for. The analysis looks much further
+// ahead than this, so the warnings don't go out of date in the meantime
+const reanalyzeAfter = 24 * time.Hour
+
+func NewCache() *Cache {
+	return &Cache{entries: map[types.NamespacedName]cacheEntry{}}
+}
+
+// StateFor does the same as the package level StateFor, but reuses the ControlledJob's CompiledSchedule as long as
+// neither its UID, its generation nor its calendar have changed since the schedule was compiled.
+//
+// ControlledJobs without a UID haven't come from the API server, so can't be told apart from a different version
+// of themselves. Their schedules are never cached
+func (c *Cache) StateFor(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, now time.Time) (State, error) {
+	compiled, err := c.compiledScheduleFor(controlledJob, calendarSpec)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.StateAt(now)
+}
+
+// WarningsFor does the same as AnalyzeSchedule, but reuses the ControlledJob's CompiledSchedule in the same way as
+// StateFor. The warnings are also kept, and only worked out again once they're a day old
+func (c *Cache) WarningsFor(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, now time.Time) ([]ScheduleWarning, error) {
+	compiled, err := c.compiledScheduleFor(controlledJob, calendarSpec)
+	if err != nil {
+		return nil, err
+	}
+	if controlledJob.UID == "" {
+		return compiled.Analyze(now), nil
+	}
+
+	name := types.NamespacedName{Namespace: controlledJob.Namespace, Name: controlledJob.Name}
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && entry.compiled == compiled && entry.analysis != nil &&
+		!now.Before(entry.analysis.from) && now.Sub(entry.analysis.from) < reanalyzeAfter {
+		return entry.analysis.warnings, nil
+	}
+
+	result := &analysis{from: now, warnings: compiled.Analyze(now)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Only keep the analysis if the schedule it's for is still the one in the cache
+	if entry, ok := c.entries[name]; ok && entry.compiled == compiled {
+		entry.analysis = result
+		c.entries[name] = entry
+	}
+	return result.warnings, nil
+}
+
+// Evict forgets the schedule of the ControlledJob with the given name, which should be called once it's deleted
+func (c *Cache) Evict(name types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, name)
+}
+
+func (c *Cache) compiledScheduleFor(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec) (*CompiledSchedule, error) {
+	if controlledJob.UID == "" {
+		return Compile(controlledJob, calendarSpec)
+	}
+
+	name := types.NamespacedName{Namespace: controlledJob.Namespace, Name: controlledJob.Name}
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && entry.uid == controlledJob.UID && entry.generation == controlledJob.Generation &&
+		equality.Semantic.DeepEqual(entry.calendarSpec, calendarSpec) {
+		return entry.compiled, nil
+	}
+
+	compiled, err := Compile(controlledJob, calendarSpec)
+	if err != nil {
+		// Don't keep the schedule of an earlier, valid, version of the ControlledJob
+		c.Evict(name)
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = cacheEntry{
+		uid:          controlledJob.UID,
+		generation:   controlledJob.Generation,
+		calendarSpec: calendarSpec.DeepCopy(),
+		compiled:     compiled,
+	}
+	return compiled, nil
+}
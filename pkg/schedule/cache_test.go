@@ -0,0 +1,165 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// benchmarkControlledJob has a typical schedule: start and stop on weekdays in London, with a restart at lunchtime
+// and a calendar of holidays
+func benchmarkControlledJob() (*batch.ControlledJob, *batch.CalendarSpec) {
+	controlledJob := &batch.ControlledJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "my-job", UID: "1234", Generation: 1},
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "Europe/London"},
+			Events: []batch.EventSpec{
+				{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+				{Action: batch.EventTypeRestart, CronSchedule: "30 12 * * MON-FRI"},
+				{Action: batch.EventTypeStop, Schedule: &batch.FriendlyScheduleSpec{TimeOfDay: "17:00", DaysOfWeek: "MON-FRI"}},
+			},
+			Exclusions: []batch.ExclusionSpec{{Date: "2024-12-25", EndDate: "2024-12-26"}},
+		},
+	}
+	calendar := &batch.CalendarSpec{
+		Holidays:    []batch.ExclusionSpec{{Date: "2024-05-06"}, {Date: "2024-05-27"}, {Date: "2024-08-26"}},
+		EarlyCloses: []batch.EarlyCloseSpec{{Date: "2024-12-24", TimeOfDay: "12:30"}},
+	}
+	return controlledJob, calendar
+}
+
+func Test_Cache_StateFor(t *testing.T) {
+	controlledJob, calendar := benchmarkControlledJob()
+	now := time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC)
+	name := types.NamespacedName{Namespace: "team-a", Name: "my-job"}
+	sut := NewCache()
+
+	state, err := sut.StateFor(controlledJob, calendar, now)
+
+	assert.Nil(t, err, "should not return an error")
+	expected, _ := StateFor(controlledJob, calendar, now)
+	assert.Equal(t, expected, state, "should give the same answer as StateFor")
+	compiled := sut.entries[name].compiled
+	assert.NotNil(t, compiled, "should have cached the schedule")
+
+	_, _ = sut.StateFor(controlledJob, calendar.DeepCopy(), now.Add(time.Hour))
+	assert.Same(t, compiled, sut.entries[name].compiled, "should reuse the schedule while nothing has changed")
+
+	controlledJob.Spec.Events[0].CronSchedule = "0 8 * * MON-FRI"
+	controlledJob.Generation = 2
+	state, err = sut.StateFor(controlledJob, calendar, now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 6, 4, 7, 0, 0, 0, time.UTC), *state.StartOfCurrentRunPeriod(), "should recompile when the generation changes")
+
+	calendar.Holidays = append(calendar.Holidays, batch.ExclusionSpec{Date: "2024-06-04"})
+	state, err = sut.StateFor(controlledJob, calendar, now)
+	assert.Nil(t, err)
+	assert.False(t, state.ShouldBeRunning(), "should recompile when the calendar changes")
+
+	controlledJob.UID = "5678"
+	controlledJob.Spec.Events[0].CronSchedule = "0 9 * * MON-FRI"
+	_, err = sut.StateFor(controlledJob, nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, types.UID("5678"), sut.entries[name].uid, "should recompile for a new ControlledJob with the same name")
+
+	controlledJob.Generation = 3
+	controlledJob.Spec.Timezone.Name = "Europe/Nowhere"
+	_, err = sut.StateFor(controlledJob, nil, now)
+	assert.NotNil(t, err, "should return an error for an invalid schedule")
+	assert.NotContains(t, sut.entries, name, "should forget the schedule of the previous generation")
+
+	sut.entries[name] = cacheEntry{uid: "5678", generation: 3}
+	sut.Evict(name)
+	assert.Empty(t, sut.entries)
+
+	controlledJob.UID = ""
+	controlledJob.Spec.Timezone.Name = "UTC"
+	_, err = sut.StateFor(controlledJob, nil, now)
+	assert.Nil(t, err)
+	assert.Empty(t, sut.entries, "should not cache a ControlledJob without a UID")
+}
+
+func Test_Cache_WarningsFor(t *testing.T) {
+	controlledJob, calendar := benchmarkControlledJob()
+	controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{Action: batch.EventTypeStart, CronSchedule: "0 10 * * MON-FRI"})
+	now := time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC)
+	name := types.NamespacedName{Namespace: "team-a", Name: "my-job"}
+	sut := NewCache()
+
+	warnings, err := sut.WarningsFor(controlledJob, calendar, now)
+
+	assert.Nil(t, err, "should not return an error")
+	expected, _ := AnalyzeSchedule(controlledJob, calendar, now)
+	assert.Equal(t, expected, warnings, "should give the same answer as AnalyzeSchedule")
+	assert.Len(t, warnings, 1)
+	analysis := sut.entries[name].analysis
+	assert.NotNil(t, analysis, "should have kept the analysis")
+
+	_, _ = sut.WarningsFor(controlledJob, calendar, now.Add(time.Hour))
+	assert.Same(t, analysis, sut.entries[name].analysis, "should reuse the analysis while it's less than a day old")
+
+	_, _ = sut.WarningsFor(controlledJob, calendar, now.Add(reanalyzeAfter))
+	assert.NotSame(t, analysis, sut.entries[name].analysis, "should analyze the schedule again once a day has passed")
+
+	controlledJob.Spec.Events = controlledJob.Spec.Events[:3]
+	controlledJob.Generation = 2
+	warnings, err = sut.WarningsFor(controlledJob, calendar, now)
+	assert.Nil(t, err)
+	assert.Empty(t, warnings, "should analyze the schedule again when it changes")
+}
+
+// BenchmarkCompile is the cost of building the schedule, which the Cache avoids repeating
+func BenchmarkCompile(b *testing.B) {
+	controlledJob, calendar := benchmarkControlledJob()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile(controlledJob, calendar); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCompiledSchedule_StateAt is the cost of finding the State once the schedule has been built
+func BenchmarkCompiledSchedule_StateAt(b *testing.B) {
+	controlledJob, calendar := benchmarkControlledJob()
+	now := time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC)
+	compiled, err := Compile(controlledJob, calendar)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := compiled.StateAt(now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStateFor(b *testing.B) {
+	controlledJob, calendar := benchmarkControlledJob()
+	now := time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := StateFor(controlledJob, calendar, now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCache_StateFor(b *testing.B) {
+	controlledJob, calendar := benchmarkControlledJob()
+	now := time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC)
+	sut := NewCache()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := sut.StateFor(controlledJob, calendar, now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
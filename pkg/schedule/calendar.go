@@ -0,0 +1,256 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// calendar holds everything, other than the events themselves, which affects when a ControlledJob's events happen
+type calendar struct {
+	location locationWithOffset
+	// excluded dates on which start events are skipped. Includes both the ControlledJob's own exclusions and
+	// any holidays from its Calendar
+	excluded exclusions
+	// earlyCloses are extra stop events
+	earlyCloses fixedEventSchedule
+	// extraWorkingDays are dates on which events happen as if it was a different day of the week
+	extraWorkingDays []extraWorkingDay
+	// namedEvents are the ControlledJob's events which have a name, which other events can be scheduled relative to
+	namedEvents map[string]batch.EventSpec
+}
+
+type extraWorkingDay struct {
+	// date at midnight UTC, representing a local date in the calendar's location
+	date       time.Time
+	scheduleAs time.Weekday
+}
+
+func calendarFor(location locationWithOffset, exclusionSpecs []batch.ExclusionSpec, calendarSpec *batch.CalendarSpec) (calendar, error) {
+	result := calendar{location: location}
+	var err error
+	result.excluded, err = exclusionsFor(exclusionSpecs)
+	if err != nil {
+		return calendar{}, err
+	}
+	if calendarSpec == nil {
+		return result, nil
+	}
+
+	holidays, err := exclusionsFor(calendarSpec.Holidays)
+	if err != nil {
+		return calendar{}, errors.Wrap(err, "invalid calendar")
+	}
+	result.excluded = append(result.excluded, holidays...)
+
+	earlyCloses := make([]time.Time, 0, len(calendarSpec.EarlyCloses))
+	for _, spec := range calendarSpec.EarlyCloses {
+		wallTime, err := spec.AsTime(location.Location)
+		if err != nil {
+			return calendar{}, errors.Wrapf(err, "invalid calendar: invalid early close %s", spec.Date)
+		}
+		earlyCloses = append(earlyCloses, location.fromWallTime(wallTime))
+	}
+	result.earlyCloses = newFixedEventSchedule(earlyCloses)
+
+	for _, spec := range calendarSpec.ExtraWorkingDays {
+		date, scheduleAs, err := spec.AsDate()
+		if err != nil {
+			return calendar{}, errors.Wrapf(err, "invalid calendar: invalid extra working day %s", spec.Date)
+		}
+		result.extraWorkingDays = append(result.extraWorkingDays, extraWorkingDay{date: date, scheduleAs: scheduleAs})
+	}
+	sort.Slice(result.extraWorkingDays, func(i, j int) bool {
+		return result.extraWorkingDays[i].date.Before(result.extraWorkingDays[j].date)
+	})
+	return result, nil
+}
+
+// eventScheduleFor builds the schedule of a single event, taking the calendar into account
+func (c calendar) eventScheduleFor(event batch.EventSpec) (eventSchedule, error) {
+	result, err := c.unexcludedEventScheduleFor(event)
+	if err != nil {
+		return nil, err
+	}
+	// One-off events are only ever added deliberately, so happen even on excluded dates
+	if event.Action == batch.EventTypeStart && event.At == "" && len(c.excluded) > 0 {
+		result = excludingEventSchedule{result, c.excluded, c.location}
+	}
+	return result, nil
+}
+
+// impliedStopScheduleFor builds the schedule of the stop events implied by a start event's RunFor. Exclusions only
+// skip start events, so the implied stops happen whether or not the start event they follow was excluded
+func (c calendar) impliedStopScheduleFor(event batch.EventSpec) (eventSchedule, error) {
+	startSchedule, err := c.unexcludedEventScheduleFor(event)
+	if err != nil {
+		return nil, err
+	}
+	return delayedEventSchedule{startSchedule, event.RunFor.Duration}, nil
+}
+
+// unexcludedEventScheduleFor builds the schedule of a single event, taking everything in the calendar apart from
+// exclusions into account
+func (c calendar) unexcludedEventScheduleFor(event batch.EventSpec) (eventSchedule, error) {
+	if event.RunFor != nil {
+		if event.Action != batch.EventTypeStart {
+			return nil, errors.Errorf("runFor can only be set on start events, not %s events", event.Action)
+		}
+		if event.RunFor.Duration <= 0 {
+			return nil, errors.Errorf("runFor must be positive, not %s", event.RunFor.Duration)
+		}
+	}
+	if event.RelativeTo != nil && (event.CronSchedule != "" || event.Schedule != nil || event.Session != nil || event.At != "") {
+		return nil, errors.New("an event relative to another event can't also have its own cronSchedule, schedule, session or at")
+	}
+	if event.Session != nil {
+		if event.At != "" {
+			return nil, errors.New("a one-off event can't also be a session event")
+		}
+		// Session events follow the exchange's own trading days, so extra working days don't apply to them
+		return sessionEventScheduleFor(event.Session)
+	}
+
+	// The event's own timezone, if it has one, only affects when the event happens. Exclusions are still
+	// applied to dates in the ControlledJob's timezone
+	location := c.location
+	if event.Timezone != nil {
+		var err error
+		location, err = locationFor(*event.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if event.RelativeTo != nil {
+		return c.relativeEventScheduleFor(*event.RelativeTo, location)
+	}
+
+	if event.At != "" {
+		oneOffTime, hasOffset, err := event.AsOneOffTime()
+		if err != nil {
+			return nil, err
+		}
+		if hasOffset {
+			return newFixedEventSchedule([]time.Time{oneOffTime}), nil
+		}
+		// DST changes could mean the time happens twice, or not at all
+		return newFixedEventSchedule(location.instantsAt(oneOffTime)), nil
+	}
+
+	if isIntervalSchedule(event.CronSchedule) {
+		return intervalEventScheduleFor(event, location)
+	}
+	if event.Epoch != "" {
+		return nil, errors.New("epoch can only be set on events with an @every cronSchedule")
+	}
+
+	if event.CronSchedule == "" && event.Schedule != nil {
+		return c.friendlyEventScheduleFor(event.Schedule, location)
+	}
+
+	specSchedule, err := mapEventToSpecSchedule(event, location.Location)
+	if err != nil {
+		return nil, err
+	}
+	return c.cronEventScheduleFor(specSchedule, location), nil
+}
+
+// cronEventScheduleFor builds the schedule of a cron schedule in the given location, including any extra working
+// days in the calendar
+func (c calendar) cronEventScheduleFor(specSchedule *cron.SpecSchedule, location locationWithOffset) eventSchedule {
+	var result eventSchedule = cronEventSchedule{specSchedule, location.OffsetSeconds, location.DSTPolicy}
+	if len(c.extraWorkingDays) > 0 {
+		result = unionEventSchedule{result, extraWorkingDaysEventSchedule{specSchedule, c.extraWorkingDays, location}}
+	}
+	return result
+}
+
+// localTime returns t as a wall clock time in this location, including the additional offset
+func (l locationWithOffset) localTime(t time.Time) time.Time {
+	return t.Add(time.Second * time.Duration(l.OffsetSeconds)).In(l.Location)
+}
+
+// fromWallTime is the inverse of localTime: it returns the instant at which the clock in this location (including
+// the additional offset) shows the same wall clock time as the given time
+func (l locationWithOffset) fromWallTime(wallTime time.Time) time.Time {
+	wallTime = time.Date(wallTime.Year(), wallTime.Month(), wallTime.Day(), wallTime.Hour(), wallTime.Minute(), wallTime.Second(), 0, l.Location)
+	return wallTime.Add(-time.Second * time.Duration(l.OffsetSeconds))
+}
+
+// startOfDay returns the instant at which the given date (in any location) starts in this location
+func (l locationWithOffset) startOfDay(date time.Time) time.Time {
+	return l.fromWallTime(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// extraWorkingDaysEventSchedule happens on each extra working day, at the times the cron schedule would happen on
+// the day of the week the extra working day is scheduled as
+type extraWorkingDaysEventSchedule struct {
+	spec     *cron.SpecSchedule
+	days     []extraWorkingDay
+	location locationWithOffset
+}
+
+func (e extraWorkingDaysEventSchedule) next(t time.Time) time.Time {
+	for _, day := range e.days {
+		for _, occurrence := range e.occurrencesOn(day) {
+			if occurrence.After(t) {
+				return occurrence
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (e extraWorkingDaysEventSchedule) prev(t time.Time) time.Time {
+	for i := len(e.days) - 1; i >= 0; i-- {
+		occurrences := e.occurrencesOn(e.days[i])
+		for j := len(occurrences) - 1; j >= 0; j-- {
+			if !occurrences[j].After(t) {
+				return occurrences[j]
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// occurrencesOn lists, in order, the times the cron schedule happens on the given day
+func (e extraWorkingDaysEventSchedule) occurrencesOn(day extraWorkingDay) []time.Time {
+	if 1<<uint(day.date.Month())&e.spec.Month == 0 {
+		return nil
+	}
+	domMatch := 1<<uint(day.date.Day())&e.spec.Dom > 0
+	dowMatch := 1<<uint(day.scheduleAs)&e.spec.Dow > 0
+	if e.spec.Dom&starBit > 0 || e.spec.Dow&starBit > 0 {
+		if !(domMatch && dowMatch) {
+			return nil
+		}
+	} else if !(domMatch || dowMatch) {
+		return nil
+	}
+
+	var result []time.Time
+	for hour := 0; hour < 24; hour++ {
+		if 1<<uint(hour)&e.spec.Hour == 0 {
+			continue
+		}
+		for minute := 0; minute < 60; minute++ {
+			if 1<<uint(minute)&e.spec.Minute == 0 {
+				continue
+			}
+			for second := 0; second < 60; second++ {
+				if 1<<uint(second)&e.spec.Second == 0 {
+					continue
+				}
+				wallTime := time.Date(day.date.Year(), day.date.Month(), day.date.Day(), hour, minute, second, 0, time.UTC)
+				result = append(result, e.location.instantsAt(wallTime)...)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
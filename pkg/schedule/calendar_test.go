@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StateFor_Calendar(t *testing.T) {
+	// start at 9am, stop at 5pm every weekday. 2022-12-23 is a Friday
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 9 * * MON-FRI",
+		},
+		{
+			Action:       batch.EventTypeStop,
+			CronSchedule: "0 17 * * MON-FRI",
+		},
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, time.December, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := map[string]struct {
+		calendar                        *batch.CalendarSpec
+		now                             time.Time
+		expectedShouldBeRunning         bool
+		expectedStartOfCurrentRunPeriod time.Time
+		expectedLastStopTime            time.Time
+		expectedNextEventTime           time.Time
+	}{
+		"no calendar": {
+			now:                             at(23, 13, 0),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(23, 9, 0),
+			expectedLastStopTime:            at(22, 17, 0),
+			expectedNextEventTime:           at(23, 17, 0),
+		},
+		"start event on a holiday is skipped": {
+			calendar:                        &batch.CalendarSpec{Holidays: []batch.ExclusionSpec{{Date: "2022-12-26"}}},
+			now:                             at(26, 10, 0),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(27, 9, 0),
+			expectedLastStopTime:            at(23, 17, 0),
+			expectedNextEventTime:           at(26, 17, 0),
+		},
+		"before an early close": {
+			calendar:                        &batch.CalendarSpec{EarlyCloses: []batch.EarlyCloseSpec{{Date: "2022-12-23", TimeOfDay: "12:30"}}},
+			now:                             at(23, 12, 0),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(23, 9, 0),
+			expectedLastStopTime:            at(22, 17, 0),
+			expectedNextEventTime:           at(23, 12, 30),
+		},
+		"after an early close": {
+			calendar:                        &batch.CalendarSpec{EarlyCloses: []batch.EarlyCloseSpec{{Date: "2022-12-23", TimeOfDay: "12:30"}}},
+			now:                             at(23, 13, 0),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(26, 9, 0),
+			expectedLastStopTime:            at(23, 12, 30),
+			expectedNextEventTime:           at(23, 17, 0),
+		},
+		"extra working day gets the events of the day it is scheduled as": {
+			calendar:                        &batch.CalendarSpec{ExtraWorkingDays: []batch.ExtraWorkingDaySpec{{Date: "2022-12-24", ScheduleAs: "FRI"}}},
+			now:                             at(24, 10, 0),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(24, 9, 0),
+			expectedLastStopTime:            at(23, 17, 0),
+			expectedNextEventTime:           at(24, 17, 0),
+		},
+		"holiday on an extra working day still skips start events": {
+			calendar: &batch.CalendarSpec{
+				Holidays:         []batch.ExclusionSpec{{Date: "2022-12-24"}},
+				ExtraWorkingDays: []batch.ExtraWorkingDaySpec{{Date: "2022-12-24", ScheduleAs: "FRI"}},
+			},
+			now:                             at(24, 10, 0),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(26, 9, 0),
+			expectedLastStopTime:            at(23, 17, 0),
+			expectedNextEventTime:           at(24, 17, 0),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   events,
+				},
+			}
+
+			sut, err := StateFor(controlledJob, tc.calendar, tc.now)
+
+			assert.Nil(t, err, "Should not return an error")
+			assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning())
+			assert.True(t, tc.expectedStartOfCurrentRunPeriod.Equal(*sut.StartOfCurrentRunPeriod()), "%s (expected) != %s (actual)", tc.expectedStartOfCurrentRunPeriod, *sut.StartOfCurrentRunPeriod())
+			assert.True(t, tc.expectedLastStopTime.Equal(*sut.LastStopTime()), "%s (expected) != %s (actual)", tc.expectedLastStopTime, *sut.LastStopTime())
+			assert.True(t, tc.expectedNextEventTime.Equal(*sut.NextEventTime()), "%s (expected) != %s (actual)", tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_CalendarEarlyCloseIgnoredInStartOnlySchedule(t *testing.T) {
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone:          batch.TimezoneSpec{Name: "UTC"},
+			Events:            []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"}},
+			ConcurrencyPolicy: batch.AllowConcurrent,
+		},
+	}
+	calendar := &batch.CalendarSpec{EarlyCloses: []batch.EarlyCloseSpec{{Date: "2022-12-23", TimeOfDay: "12:30"}}}
+
+	sut, err := StateFor(controlledJob, calendar, time.Date(2022, time.December, 23, 10, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.True(t, sut.IsStartOnly())
+	assert.Nil(t, sut.LastStopTime())
+	assert.Equal(t, time.Date(2022, time.December, 24, 9, 0, 0, 0, time.UTC), *sut.NextEventTime())
+}
+
+func Test_StateFor_InvalidCalendar(t *testing.T) {
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "UTC"},
+			Events:   []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"}},
+		},
+	}
+	now := time.Date(2022, time.December, 23, 10, 0, 0, 0, time.UTC)
+
+	for name, calendar := range map[string]*batch.CalendarSpec{
+		"invalid holiday":           {Holidays: []batch.ExclusionSpec{{Date: "26/12/2022"}}},
+		"invalid early close":       {EarlyCloses: []batch.EarlyCloseSpec{{Date: "2022-12-23", TimeOfDay: "25:00"}}},
+		"invalid extra working day": {ExtraWorkingDays: []batch.ExtraWorkingDaySpec{{Date: "2022-12-24", ScheduleAs: "FOO"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := StateFor(controlledJob, calendar, now)
+			assert.NotNil(t, err, "should return an error")
+		})
+	}
+}
+
+func Test_extraWorkingDaysEventSchedule(t *testing.T) {
+	// 9am and 5pm every Monday, in New York with an additional offset of one minute
+	mondays := newCronSchedule("0 9,17 * * MON")
+	mondays.Location = nyLoc
+	location := locationWithOffset{Location: nyLoc, OffsetSeconds: 60}
+
+	// 2022-02-05 is a Saturday
+	saturday := time.Date(2022, 2, 5, 0, 0, 0, 0, time.UTC)
+	sut := extraWorkingDaysEventSchedule{
+		spec: mondays,
+		days: []extraWorkingDay{
+			{date: saturday, scheduleAs: time.Monday},
+			{date: saturday.AddDate(0, 0, 1), scheduleAs: time.Tuesday},
+		},
+		location: location,
+	}
+
+	// 9am in New York (UTC-5) is 14:00 UTC, and the extra minute of offset brings it forward to 13:59
+	morning := time.Date(2022, 2, 5, 13, 59, 0, 0, time.UTC)
+	evening := time.Date(2022, 2, 5, 21, 59, 0, 0, time.UTC)
+
+	assert.True(t, morning.Equal(sut.next(saturday)), "next from midnight should be the morning, but got %s", sut.next(saturday))
+	assert.True(t, evening.Equal(sut.next(morning)), "next from the morning should be the evening, but got %s", sut.next(morning))
+	assert.True(t, sut.next(evening).IsZero(), "the Sunday is scheduled as a Tuesday, so has no events")
+	assert.True(t, evening.Equal(sut.prev(saturday.AddDate(0, 0, 2))), "prev from Monday should be the Saturday evening, but got %s", sut.prev(saturday.AddDate(0, 0, 2)))
+	assert.True(t, morning.Equal(sut.prev(morning)), "prev should include the given time, but got %s", sut.prev(morning))
+	assert.True(t, sut.prev(morning.Add(-time.Second)).IsZero(), "there are no events before the Saturday morning")
+}
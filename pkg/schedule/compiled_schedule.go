@@ -0,0 +1,238 @@
+package schedule
+
+import (
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/pkg/errors"
+)
+
+// CompiledSchedule is a ControlledJob's schedule with its timezones resolved, its cron specs parsed and its
+// calendar applied. Building it is the expensive part of working out a ControlledJob's State, so a
+// CompiledSchedule can be kept (see Cache) and asked for the State at any number of times
+type CompiledSchedule struct {
+	// events are the ControlledJob's own event specs, needed to check the coverage of any trading calendars
+	events      []batch.EventSpec
+	compiled    []compiledEvent
+	isStartOnly bool
+	// hasStartEvents is true if the ControlledJob has any start events, even if none of them ever happen
+	hasStartEvents bool
+	// precedence settles ties between start and stop events at the same instant. If it's not set, the stop
+	// event wins
+	precedence batch.EventPrecedence
+}
+
+// compiledEvent is the schedule of a single event, along with the action to take when it happens and where it
+// came from
+type compiledEvent struct {
+	action     batch.EventType
+	schedule   eventSchedule
+	source     EventSource
+	eventIndex int
+}
+
+// Compile builds the schedule of the given ControlledJob.
+// calendarSpec is the spec of the Calendar or ClusterCalendar referenced by the ControlledJob, or nil if it doesn't reference one
+func Compile(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec) (*CompiledSchedule, error) {
+	location, err := locationFor(controlledJob.Spec.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := calendarFor(location, controlledJob.Spec.Exclusions, calendarSpec)
+	if err != nil {
+		return nil, err
+	}
+	isStartOnly := controlledJob.Spec.ConcurrencyPolicy != "" && hasNoStopEvents(controlledJob.Spec.Events)
+	result, err := compileSchedule(controlledJob.Spec.Events, calendar, isStartOnly)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the schedule")
+	}
+	result.precedence = controlledJob.Spec.EventPrecedence
+	return result, nil
+}
+
+func compileSchedule(events []batch.EventSpec, calendar calendar, isStartOnly bool) (*CompiledSchedule, error) {
+	compiled, err := compileEvents(events, calendar)
+	if err != nil {
+		return nil, err
+	}
+	result := &CompiledSchedule{events: events, compiled: compiled, isStartOnly: isStartOnly}
+	for _, event := range events {
+		if event.Action == batch.EventTypeStart {
+			result.hasStartEvents = true
+			break
+		}
+	}
+	return result, nil
+}
+
+// compileEvents builds the schedule of each of the events.
+//
+// The calendar determines the timezone of the events, and adjusts them for any exclusions, early closes
+// and extra working days. Early closes are treated as additional stop events, but only if the schedule has
+// stop events of its own. The stops implied by start events with a RunFor are also treated as stop events
+func compileEvents(events []batch.EventSpec, calendar calendar) ([]compiledEvent, error) {
+	var err error
+	calendar.namedEvents, err = namedEventsFor(events)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]compiledEvent, 0, len(events))
+	for i, event := range events {
+		eventSchedule, err := calendar.eventScheduleFor(event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, compiledEvent{event.Action, eventSchedule, EventSourceEvent, i})
+	}
+
+	// Start events with a RunFor imply a stop event some time after each start
+	for i, event := range events {
+		if event.Action != batch.EventTypeStart || event.RunFor == nil {
+			continue
+		}
+		impliedStopSchedule, err := calendar.impliedStopScheduleFor(event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, compiledEvent{batch.EventTypeStop, impliedStopSchedule, EventSourceRunFor, i})
+	}
+	if len(calendar.earlyCloses) > 0 && !hasNoStopEvents(events) {
+		result = append(result, compiledEvent{batch.EventTypeStop, calendar.earlyCloses, EventSourceEarlyClose, -1})
+	}
+	return result, nil
+}
+
+// StateAt works out where the given time falls in the schedule. Each event's schedule is searched once in each
+// direction, and the results are shared between all the parts of the State
+func (c *CompiledSchedule) StateAt(now time.Time) (State, error) {
+	if err := checkSessionCoverage(c.events, now); err != nil {
+		return nil, err
+	}
+
+	// Restart events don't change whether we should be running or not, so only consider start and stop
+	// events when looking backwards
+	var previousEvent, nextEvent, lastStopEvent, lastStartEvent, lastRestartEvent *ScheduledEvent
+	for _, event := range c.compiled {
+		if prev := event.schedule.prev(now); !prev.IsZero() {
+			switch event.action {
+			case batch.EventTypeStart:
+				lastStartEvent = nearerEvent(lastStartEvent, event.action, prev, directionPrevious)
+				previousEvent = c.nearerPreviousEvent(previousEvent, event.action, prev)
+			case batch.EventTypeStop:
+				lastStopEvent = nearerEvent(lastStopEvent, event.action, prev, directionPrevious)
+				previousEvent = c.nearerPreviousEvent(previousEvent, event.action, prev)
+			case batch.EventTypeRestart:
+				lastRestartEvent = nearerEvent(lastRestartEvent, event.action, prev, directionPrevious)
+			}
+		}
+		if next := event.schedule.next(now); !next.IsZero() {
+			nextEvent = nearerEvent(nextEvent, event.action, next, directionNext)
+		}
+	}
+
+	var lastStopTime *time.Time
+	if lastStopEvent != nil {
+		lastStopTime = &lastStopEvent.ScheduledTimeUTC
+	}
+
+	var startOfCurrentRunPeriod *RunPeriodStartTime
+	if c.isStartOnly {
+		// Each start event begins a new run period
+		if lastStartEvent != nil {
+			startOfCurrentRunPeriod = &lastStartEvent.ScheduledTimeUTC
+		}
+	} else {
+		var err error
+		startOfCurrentRunPeriod, err = c.startOfRunPeriodAfter(lastStopTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find start of current run period")
+		}
+	}
+
+	// The most recent restart event only counts if it happened strictly after the start of the current run period.
+	// A restart at the same instant as the start is a no-op, as the Job will be brand new anyway
+	var lastRestartTime *time.Time
+	if previousEvent != nil && previousEvent.Type == batch.EventTypeStart && startOfCurrentRunPeriod != nil &&
+		lastRestartEvent != nil && lastRestartEvent.ScheduledTimeUTC.After(*startOfCurrentRunPeriod) {
+		lastRestartTime = &lastRestartEvent.ScheduledTimeUTC
+	}
+
+	return &state{
+		lastStopTime:            lastStopTime,
+		startOfCurrentRunPeriod: startOfCurrentRunPeriod,
+		lastRestartTime:         lastRestartTime,
+		previousEvent:           previousEvent,
+		nextEvent:               nextEvent,
+		isStartOnly:             c.isStartOnly,
+	}, nil
+}
+
+// startOfRunPeriodAfter finds the start of the run period which follows the given stop time: the first start event
+// after it, or at the same instant if start events take precedence
+func (c *CompiledSchedule) startOfRunPeriodAfter(lastStopTime *time.Time) (*RunPeriodStartTime, error) {
+	if lastStopTime == nil {
+		// No recent stop event.
+		if !c.hasStartEvents {
+			// No start events, so just return nil
+			return nil, nil
+		}
+
+		// If there _are_ some start events though, return an error because we can't be sure what to do here for the best.
+		// Why?
+		// Because a schedule of just start events could be the user trying to:
+		// - start a new job at each start event, even if previous runs are still going (like CronJob semantics)
+		// - make sure the job is still running
+		// - explicitly restart the job each start event
+		// - a mistake - they forgot to add stop events
+		// Because we can't tell the difference, let's fail-fast and fail-safe by not running anything unless the
+		// user has told us what they want by setting a ConcurrencyPolicy (in which case we don't get here)
+		return nil, errors.New("No previous stop events found, only start events. Start-only schedules must set a concurrencyPolicy")
+	}
+
+	var nextStartEvent *ScheduledEvent
+	for _, event := range c.compiled {
+		if event.action != batch.EventTypeStart {
+			continue
+		}
+		if c.precedence == batch.StartEventPrecedence && event.schedule.prev(*lastStopTime).Equal(*lastStopTime) {
+			nextStartEvent = &ScheduledEvent{Type: event.action, ScheduledTimeUTC: *lastStopTime}
+			break
+		}
+		if next := event.schedule.next(*lastStopTime); !next.IsZero() {
+			nextStartEvent = nearerEvent(nextStartEvent, event.action, next, directionNext)
+		}
+	}
+	if nextStartEvent == nil {
+		return nil, nil
+	}
+	return &nextStartEvent.ScheduledTimeUTC, nil
+}
+
+// nearerEvent returns whichever of the current nearest event and a new event at the given time is nearer in the
+// given direction. As with unionEventSchedule, the current nearest event wins a tie
+func nearerEvent(nearest *ScheduledEvent, action batch.EventType, t time.Time, direction eventDirection) *ScheduledEvent {
+	if nearest != nil && !eventIsNearer(t, nearest.ScheduledTimeUTC, direction) {
+		return nearest
+	}
+	return &ScheduledEvent{Type: action, ScheduledTimeUTC: t}
+}
+
+// nearerPreviousEvent is nearerEvent for the most recent start or stop event, where a tie between a start and a stop
+// is settled by the schedule's precedence, as it is for the start of the run period and for RunWindows
+func (c *CompiledSchedule) nearerPreviousEvent(nearest *ScheduledEvent, action batch.EventType, t time.Time) *ScheduledEvent {
+	if nearest != nil && nearest.Type != action && nearest.ScheduledTimeUTC.Equal(t) && c.takesPrecedence(action) {
+		return &ScheduledEvent{Type: action, ScheduledTimeUTC: t}
+	}
+	return nearerEvent(nearest, action, t, directionPrevious)
+}
+
+// takesPrecedence returns true if the given action wins a tie with the other kind of event. Stop events win unless
+// start events have been given precedence
+func (c *CompiledSchedule) takesPrecedence(action batch.EventType) bool {
+	if c.precedence == batch.StartEventPrecedence {
+		return action == batch.EventTypeStart
+	}
+	return action == batch.EventTypeStop
+}
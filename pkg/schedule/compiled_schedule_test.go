@@ -11,7 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_FindNearestEvent(t *testing.T) {
+func Test_CompiledSchedule_StateAt_AdjacentEvents(t *testing.T) {
 	morningOnWednesdayUTC := time.Date(2022, 1, 19, 9, 0, 0, 0, time.UTC)
 	lunchtimeOnWednesdayUTC := time.Date(2022, 1, 19, 12, 0, 0, 0, time.UTC)
 	eveningOnWednesdayUTC := time.Date(2022, 1, 19, 17, 0, 0, 0, time.UTC)
@@ -202,17 +202,16 @@ func Test_FindNearestEvent(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			location, _ := time.LoadLocation(tc.timezone.Name)
 			loc := locationWithOffset{
-				location,
-				tc.timezone.OffsetSeconds,
+				Location:      location,
+				OffsetSeconds: tc.timezone.OffsetSeconds,
 			}
 
-			previous, prErr := findNearestEvent(tc.events, tc.now, loc, directionPrevious, func(es batch.EventSpec) bool { return true })
-			next, neErr := findNearestEvent(tc.events, tc.now, loc, directionNext, func(es batch.EventSpec) bool { return true })
+			previous, next, err := adjacentEventsAt(tc.events, tc.now, calendar{location: loc})
 
 			testhelpers.AssertDeepEqualJson(t, tc.expectedPreviousEvent, previous, "expected nearest previous events to match")
-			testhelpers.AssertSameError(t, tc.expectedErrorForPrevious, prErr, "expected error for getting previous event to match")
+			testhelpers.AssertSameError(t, tc.expectedErrorForPrevious, err, "expected error for getting previous event to match")
 			testhelpers.AssertDeepEqualJson(t, tc.expectedNextEvent, next, "expected nearest next events to match")
-			testhelpers.AssertSameError(t, tc.expectedErrorForNext, neErr, "expected error for getting next event to match")
+			testhelpers.AssertSameError(t, tc.expectedErrorForNext, err, "expected error for getting next event to match")
 
 			if previous != nil {
 				assert.Equal(t, time.UTC.String(), previous.ScheduledTimeUTC.Location().String())
@@ -245,7 +244,7 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			utcMinus1Loc, _ := time.LoadLocation("Etc/GMT+1")
 
 			// Set up UTC-1 with 60s offset == 'UTC-59m'
-			locationWithOffset := locationWithOffset{utcMinus1Loc, 60}
+			locationWithOffset := locationWithOffset{Location: utcMinus1Loc, OffsetSeconds: 60}
 
 			events := []batch.EventSpec{
 				event(batch.EventTypeStart, "09:00", "SUN-SAT"),
@@ -261,11 +260,9 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			nowIs_9_58_UTC := time.Date(2022, time.January, 1, 9, 58, 0, 0, time.UTC)
 			nowIs_9_59_UTC := time.Date(2022, time.January, 1, 9, 59, 0, 0, time.UTC)
 
-			previous_9_58, _ := findNearestEvent(events, nowIs_9_58_UTC, locationWithOffset, directionPrevious, func(es batch.EventSpec) bool { return true })
-			next_9_58, _ := findNearestEvent(events, nowIs_9_58_UTC, locationWithOffset, directionNext, func(es batch.EventSpec) bool { return true })
+			previous_9_58, next_9_58, _ := adjacentEventsAt(events, nowIs_9_58_UTC, calendar{location: locationWithOffset})
 
-			previous_9_59, _ := findNearestEvent(events, nowIs_9_59_UTC, locationWithOffset, directionPrevious, func(es batch.EventSpec) bool { return true })
-			next_9_59, _ := findNearestEvent(events, nowIs_9_59_UTC, locationWithOffset, directionNext, func(es batch.EventSpec) bool { return true })
+			previous_9_59, next_9_59, _ := adjacentEventsAt(events, nowIs_9_59_UTC, calendar{location: locationWithOffset})
 
 			datesShouldMatch(t, startEvent_yesterday, previous_9_58.ScheduledTimeUTC)
 			datesShouldMatch(t, startEvent_today, next_9_58.ScheduledTimeUTC)
@@ -290,7 +287,7 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			utcMinus1Loc, _ := time.LoadLocation("Etc/GMT+1")
 
 			// Set up UTC-1 with 60s offset == 'UTC-61m'
-			locationWithOffset := locationWithOffset{utcMinus1Loc, -60}
+			locationWithOffset := locationWithOffset{Location: utcMinus1Loc, OffsetSeconds: -60}
 
 			events := []batch.EventSpec{
 				event(batch.EventTypeStart, "09:00", "SUN-SAT"),
@@ -306,11 +303,9 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			nowIs_10_00_UTC := time.Date(2022, time.January, 1, 10, 0, 0, 0, time.UTC)
 			nowIs_10_01_UTC := time.Date(2022, time.January, 1, 10, 1, 0, 0, time.UTC)
 
-			previous_10_00, _ := findNearestEvent(events, nowIs_10_00_UTC, locationWithOffset, directionPrevious, func(es batch.EventSpec) bool { return true })
-			next_10_00, _ := findNearestEvent(events, nowIs_10_00_UTC, locationWithOffset, directionNext, func(es batch.EventSpec) bool { return true })
+			previous_10_00, next_10_00, _ := adjacentEventsAt(events, nowIs_10_00_UTC, calendar{location: locationWithOffset})
 
-			previous_10_01, _ := findNearestEvent(events, nowIs_10_01_UTC, locationWithOffset, directionPrevious, func(es batch.EventSpec) bool { return true })
-			next_10_01, _ := findNearestEvent(events, nowIs_10_01_UTC, locationWithOffset, directionNext, func(es batch.EventSpec) bool { return true })
+			previous_10_01, next_10_01, _ := adjacentEventsAt(events, nowIs_10_01_UTC, calendar{location: locationWithOffset})
 
 			datesShouldMatch(t, startEvent_yesterday, previous_10_00.ScheduledTimeUTC)
 			datesShouldMatch(t, startEvent_today, next_10_00.ScheduledTimeUTC)
@@ -347,7 +342,7 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			t.Log(now)
 
 			// At 4 am the most recent event should be the stop event from yesterday
-			previousEvent, err := findNearestEvent(events, now, locationWithOffset{nyLocation, 0}, directionPrevious, func(es batch.EventSpec) bool { return true })
+			previousEvent, _, err := adjacentEventsAt(events, now, calendar{location: locationWithOffset{Location: nyLocation}})
 
 			assert.Nil(t, err)
 			assert.Equal(t, batch.EventTypeStop, previousEvent.Type)
@@ -376,7 +371,7 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			t.Log(nowAfterChange)
 
 			// At 4 am the most recent event should be 1:30am in the new (non DST) timezone, which is UTC-5
-			previousEvent, err := findNearestEvent(events, nowAfterChange, locationWithOffset{nyLocation, 0}, directionPrevious, func(es batch.EventSpec) bool { return true })
+			previousEvent, _, err := adjacentEventsAt(events, nowAfterChange, calendar{location: locationWithOffset{Location: nyLocation}})
 
 			assert.Nil(t, err)
 			assert.Equal(t, batch.EventTypeStart, previousEvent.Type)
@@ -386,7 +381,7 @@ func Test_ParanoidTimezoneOffsetTests(t *testing.T) {
 			t.Log(nowBeforeChange)
 
 			// At 1 am the next event should be 1:30am in the old (DST) timezone, which is UTC-4
-			nextEvent, err := findNearestEvent(events, nowBeforeChange, locationWithOffset{nyLocation, 0}, directionNext, func(es batch.EventSpec) bool { return true })
+			_, nextEvent, err := adjacentEventsAt(events, nowBeforeChange, calendar{location: locationWithOffset{Location: nyLocation}})
 
 			assert.Nil(t, err)
 			assert.Equal(t, batch.EventTypeStart, nextEvent.Type)
@@ -399,7 +394,22 @@ func datesShouldMatch(t *testing.T, a, b time.Time) {
 	assert.Equal(t, a, b, "%s != %s", a, b)
 }
 
-// testCase (and the following functions) is a helper to build a test case for testing the FindNearestEvent function
+// adjacentEventsAt compiles the events, and returns the most recent start or stop event at or before now, and the
+// next event of any kind after it. A schedule with no stop events is compiled as a start-only schedule
+func adjacentEventsAt(events []batch.EventSpec, now time.Time, calendar calendar) (previous, next *ScheduledEvent, err error) {
+	compiled, err := compileSchedule(events, calendar, hasNoStopEvents(events))
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := compiled.StateAt(now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.(*state).previousEvent, result.(*state).nextEvent, nil
+}
+
+// testCase (and the following functions) is a helper to build a test case for testing the events either side of a
+// time in a CompiledSchedule
 type testCase struct {
 	timezone                 batch.TimezoneSpec
 	events                   []batch.EventSpec
@@ -458,7 +468,7 @@ func schedEv(action batch.EventType, time time.Time) *ScheduledEvent {
 	}
 }
 
-func Test_findNearestScheduleTime(t *testing.T) {
+func Test_unionEventSchedule(t *testing.T) {
 	// In this method we don't need to do test any complex cron schedules
 	// We trust the cron library (and our tests of cronPrev) are sufficiently
 	// reliable
@@ -539,7 +549,11 @@ func Test_findNearestScheduleTime(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			actualTime, actualIdx := findNearestScheduleTime(tc.schedules, tc.now, tc.direction, tc.additionalOffsetSeconds)
+			schedules := make(unionEventSchedule, 0, len(tc.schedules))
+			for _, specSchedule := range tc.schedules {
+				schedules = append(schedules, cronEventSchedule{specSchedule, tc.additionalOffsetSeconds, batch.DSTPolicySpec{}})
+			}
+			actualTime, actualIdx := schedules.nearest(tc.now, tc.direction)
 
 			assert.Equal(t, tc.expectedNearestEventTime, actualTime, "%v (expected) != %v (actual)", tc.expectedNearestEventTime, actualTime)
 			assert.Equal(t, tc.expectedIdx, actualIdx)
@@ -552,7 +566,7 @@ func newCronSchedule(spec string) *cron.SpecSchedule {
 	return s.(*cron.SpecSchedule)
 }
 
-func Test_findNearestEvent_filtering(t *testing.T) {
+func Test_CompiledSchedule_nearest_filtering(t *testing.T) {
 	schedules := []batch.EventSpec{
 		{
 			Action:       batch.EventTypeStart,
@@ -567,12 +581,11 @@ func Test_findNearestEvent_filtering(t *testing.T) {
 	// Now is midday on Wednesday
 	now := time.Date(2022, 02, 02, 12, 0, 0, 0, time.UTC)
 
-	actualPrevious, _ := findNearestEvent(schedules, now, locationWithOffset{time.UTC, 0}, directionPrevious,
-		func(es batch.EventSpec) bool { return es.Action == batch.EventTypeStart },
-	)
-	actualNext, _ := findNearestEvent(schedules, now, locationWithOffset{time.UTC, 0}, directionNext,
-		func(es batch.EventSpec) bool { return es.Action == batch.EventTypeStart },
-	)
+	compiled, _ := compileSchedule(schedules, calendar{location: locationWithOffset{Location: time.UTC}}, false)
+	previousStart := compiled.nearest(now, directionPrevious, batch.EventTypeStart)
+	nextStart := compiled.nearest(now, directionNext, batch.EventTypeStart)
+	actualPrevious := &ScheduledEvent{ScheduledTimeUTC: previousStart.Time, Type: batch.EventTypeStart}
+	actualNext := &ScheduledEvent{ScheduledTimeUTC: nextStart.Time, Type: batch.EventTypeStart}
 
 	// expect the nearest previous event to be midnight on Monday 31st January
 	// i.e. we skip over the stop event on Tuesday
@@ -592,3 +605,127 @@ func Test_findNearestEvent_filtering(t *testing.T) {
 	testhelpers.AssertDeepEqualJson(t, expectedNext, actualNext, "Next event should match")
 
 }
+
+func Test_CompiledSchedule_StateAt_RestartEvents(t *testing.T) {
+	events := []batch.EventSpec{
+		cronEvent(batch.EventTypeStart, "0 0 * * Mon"),
+		cronEvent(batch.EventTypeRestart, "0 0 * * Tue"),
+		cronEvent(batch.EventTypeStop, "0 0 * * Fri"),
+	}
+
+	// Now is midday on Wednesday
+	now := time.Date(2022, 02, 02, 12, 0, 0, 0, time.UTC)
+
+	actualPrevious, actualNext, err := adjacentEventsAt(events, now, calendar{location: locationWithOffset{Location: time.UTC}})
+
+	// expect the nearest previous event to be midnight on Monday 31st January
+	// i.e. we skip over the restart event on Tuesday, as it doesn't change whether we should be running
+	expectedPrevious := &ScheduledEvent{
+		ScheduledTimeUTC: time.Date(2022, 01, 31, 0, 0, 0, 0, time.UTC),
+		Type:             batch.EventTypeStart,
+	}
+
+	// expect the next event to be midnight on Friday 4th February
+	expectedNext := &ScheduledEvent{
+		ScheduledTimeUTC: time.Date(2022, 02, 4, 0, 0, 0, 0, time.UTC),
+		Type:             batch.EventTypeStop,
+	}
+
+	assert.Nil(t, err, "should not return an error")
+	testhelpers.AssertDeepEqualJson(t, expectedPrevious, actualPrevious, "Previous event should match")
+	testhelpers.AssertDeepEqualJson(t, expectedNext, actualNext, "Next event should match")
+
+	// The restart event is still used for the time of the last restart
+	compiled, _ := compileSchedule(events, calendar{location: locationWithOffset{Location: time.UTC}}, false)
+	state, _ := compiled.StateAt(now)
+	assert.Equal(t, time.Date(2022, 02, 1, 0, 0, 0, 0, time.UTC), *state.LastRestartTime())
+}
+
+func Test_CompiledSchedule_StateAt_PerEventTimezone(t *testing.T) {
+	// Start at 08:00 in London and stop at 16:30 in New York, on weekdays. The ControlledJob itself is in UTC, so
+	// its exclusions are UTC dates
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 8 * * MON-FRI",
+			Timezone:     &batch.TimezoneSpec{Name: "Europe/London"},
+		},
+		{
+			Action:       batch.EventTypeStop,
+			CronSchedule: "30 16 * * MON-FRI",
+			Timezone:     &batch.TimezoneSpec{Name: "America/New_York", OffsetSeconds: 60},
+		},
+	}
+	utc := calendar{location: locationWithOffset{Location: time.UTC}}
+	tokyoLoc, _ := time.LoadLocation("Asia/Tokyo")
+
+	testCases := map[string]struct {
+		now             time.Time
+		calendar        calendar
+		direction       eventDirection
+		expectedType    batch.EventType
+		expectedTimeUTC time.Time
+	}{
+		"[Next] start in London": {
+			// 2022-03-11 is a Friday. London is on GMT
+			now:             time.Date(2022, 3, 11, 0, 0, 0, 0, time.UTC),
+			calendar:        utc,
+			direction:       directionNext,
+			expectedType:    batch.EventTypeStart,
+			expectedTimeUTC: time.Date(2022, 3, 11, 8, 0, 0, 0, time.UTC),
+		},
+		"[Next] stop in New York, with its own offset": {
+			// New York is on EST (UTC-5). 16:30 is 21:30 UTC, less the extra minute of offset
+			now:             time.Date(2022, 3, 11, 12, 0, 0, 0, time.UTC),
+			calendar:        utc,
+			direction:       directionNext,
+			expectedType:    batch.EventTypeStop,
+			expectedTimeUTC: time.Date(2022, 3, 11, 21, 29, 0, 0, time.UTC),
+		},
+		"[Previous] stop in New York after it has changed to daylight saving time but London hasn't": {
+			// 2022-03-14 is a Monday. New York is on EDT (UTC-4), London is still on GMT
+			now:             time.Date(2022, 3, 14, 23, 0, 0, 0, time.UTC),
+			calendar:        utc,
+			direction:       directionPrevious,
+			expectedType:    batch.EventTypeStop,
+			expectedTimeUTC: time.Date(2022, 3, 14, 20, 29, 0, 0, time.UTC),
+		},
+		"[Previous] start in London after it has changed to daylight saving time": {
+			// 2022-03-28 is a Monday. London is on BST (UTC+1)
+			now:             time.Date(2022, 3, 28, 12, 0, 0, 0, time.UTC),
+			calendar:        utc,
+			direction:       directionPrevious,
+			expectedType:    batch.EventTypeStart,
+			expectedTimeUTC: time.Date(2022, 3, 28, 7, 0, 0, 0, time.UTC),
+		},
+		"[Next] exclusions apply to dates in the ControlledJob's timezone": {
+			// Excluding 2022-03-14 in Tokyo excludes the 08:00 London start on that date, which is 17:00 in Tokyo
+			now: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
+			calendar: calendar{
+				location: locationWithOffset{Location: tokyoLoc},
+				excluded: exclusions{{first: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC), last: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)}},
+			},
+			direction:       directionNext,
+			expectedType:    batch.EventTypeStop,
+			expectedTimeUTC: time.Date(2022, 3, 14, 20, 29, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			previous, next, err := adjacentEventsAt(events, tc.now, tc.calendar)
+			actual := next
+			if tc.direction == directionPrevious {
+				actual = previous
+			}
+
+			assert.Nil(t, err, "should not return an error")
+			assert.Equal(t, tc.expectedType, actual.Type)
+			assert.True(t, tc.expectedTimeUTC.Equal(actual.ScheduledTimeUTC), "%s (expected) != %s (actual)", tc.expectedTimeUTC, actual.ScheduledTimeUTC)
+		})
+	}
+
+	events[0].Timezone = &batch.TimezoneSpec{Name: "Not/AZone"}
+	_, _, err := adjacentEventsAt(events, time.Date(2022, 3, 11, 0, 0, 0, 0, time.UTC), utc)
+	assert.NotNil(t, err, "should return an error for an unknown timezone")
+}
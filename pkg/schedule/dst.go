@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// wallClockEventSchedule is an eventSchedule in a location, backed by a schedule of wall clock times in UTC. Wall
+// clock times in UTC are never skipped or repeated, so the location's DST policy decides when, if at all, each of
+// them happens in the location
+type wallClockEventSchedule struct {
+	wallClock eventSchedule
+	location  locationWithOffset
+}
+
+// maxClockChange is more than the furthest the clocks go forward or back by at a DST change in any timezone. A
+// wall clock time always happens within this long of the same wall clock time in UTC, so that's how far either
+// side of the nearest wall clock time we need to look to be sure of finding the nearest time
+const maxClockChange = 3 * time.Hour
+
+func (w wallClockEventSchedule) next(t time.Time) time.Time {
+	var result, resultWallTime time.Time
+	for wallTime := w.wallClock.next(w.location.wallClock(t).Add(-maxClockChange)); !wallTime.IsZero(); wallTime = w.wallClock.next(wallTime) {
+		if !result.IsZero() && wallTime.After(resultWallTime.Add(maxClockChange)) {
+			break
+		}
+		for _, instant := range w.location.instantsAt(wallTime) {
+			if instant.After(t) && (result.IsZero() || instant.Before(result)) {
+				result, resultWallTime = instant, wallTime
+			}
+		}
+	}
+	return result
+}
+
+func (w wallClockEventSchedule) prev(t time.Time) time.Time {
+	var result, resultWallTime time.Time
+	for wallTime := w.wallClock.prev(w.location.wallClock(t).Add(maxClockChange)); !wallTime.IsZero(); wallTime = w.wallClock.prev(wallTime.Add(-time.Second)) {
+		if !result.IsZero() && wallTime.Before(resultWallTime.Add(-maxClockChange)) {
+			break
+		}
+		for _, instant := range w.location.instantsAt(wallTime) {
+			if !instant.After(t) && instant.After(result) {
+				result, resultWallTime = instant, wallTime
+			}
+		}
+	}
+	return result
+}
+
+// wallClock returns the wall clock time the clock in this location (including the additional offset) shows at t,
+// as a time in UTC. Wall clock times in UTC are never skipped or repeated, so it's safe to do arithmetic on them
+func (l locationWithOffset) wallClock(t time.Time) time.Time {
+	local := l.localTime(t)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// instantsAt returns, in order, the instants at which the clock in this location (including the additional offset)
+// shows the given wall clock time, according to the location's DST policy. This is usually exactly one instant,
+// but DST changes mean some wall clock times are skipped, and others repeated
+func (l locationWithOffset) instantsAt(wallTime time.Time) []time.Time {
+	asUTC := time.Date(wallTime.Year(), wallTime.Month(), wallTime.Day(), wallTime.Hour(), wallTime.Minute(), wallTime.Second(), wallTime.Nanosecond(), time.UTC)
+	additionalOffset := time.Second * time.Duration(l.OffsetSeconds)
+
+	// DST changes never happen more than once a day, so the offsets either side of the wall clock time are the
+	// offsets a day before and a day after it
+	offsetBefore := l.utcOffsetAt(asUTC.AddDate(0, 0, -1))
+	offsetAfter := l.utcOffsetAt(asUTC.AddDate(0, 0, 1))
+
+	var instants []time.Time
+	for _, offset := range []time.Duration{offsetBefore, offsetAfter} {
+		instant := asUTC.Add(-offset)
+		if l.utcOffsetAt(instant) == offset && (len(instants) == 0 || !instants[0].Equal(instant)) {
+			instants = append(instants, instant)
+		}
+	}
+	if len(instants) > 1 && instants[1].Before(instants[0]) {
+		instants[0], instants[1] = instants[1], instants[0]
+	}
+
+	switch {
+	case len(instants) == 0:
+		// The clocks went forward over the wall clock time
+		if l.DSTPolicy.SkippedTimes != batch.DSTShiftForward {
+			return nil
+		}
+		// Using the offset from before the clocks went forward moves the time forward by the size of the change
+		instants = []time.Time{asUTC.Add(-offsetBefore)}
+	case len(instants) == 2:
+		// The clocks went back over the wall clock time
+		switch l.DSTPolicy.RepeatedTimes {
+		case batch.DSTFirstOccurrence:
+			instants = instants[:1]
+		case batch.DSTLastOccurrence:
+			instants = instants[1:]
+		}
+	}
+
+	for i := range instants {
+		instants[i] = instants[i].Add(-additionalOffset)
+	}
+	return instants
+}
+
+// utcOffsetAt is the offset from UTC of this location's timezone at the given instant, not including the additional
+// offset
+func (l locationWithOffset) utcOffsetAt(t time.Time) time.Duration {
+	_, offsetSeconds := t.In(l.Location).Zone()
+	return time.Second * time.Duration(offsetSeconds)
+}
@@ -0,0 +1,120 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cronEventSchedule_DSTPolicy(t *testing.T) {
+	// The clocks go forward from 01:00 to 02:00 in London on 2022-03-27, and back from 02:00 to 01:00 on 2022-10-30
+	londonLoc, _ := time.LoadLocation("Europe/London")
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2022, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := map[string]struct {
+		cronSpec string
+		policy   batch.DSTPolicySpec
+		// The times the schedule happens at between the given times, in order
+		from, to time.Time
+		expected []time.Time
+	}{
+		"skipped time is skipped by default": {
+			cronSpec: "30 1 * * *",
+			from:     utc(time.March, 26, 0, 0),
+			to:       utc(time.March, 29, 0, 0),
+			expected: []time.Time{utc(time.March, 26, 1, 30), utc(time.March, 28, 0, 30)},
+		},
+		"skipped time is skipped": {
+			cronSpec: "30 1 * * *",
+			policy:   batch.DSTPolicySpec{SkippedTimes: batch.DSTSkip},
+			from:     utc(time.March, 26, 0, 0),
+			to:       utc(time.March, 29, 0, 0),
+			expected: []time.Time{utc(time.March, 26, 1, 30), utc(time.March, 28, 0, 30)},
+		},
+		"skipped time is shifted forward by the size of the change": {
+			cronSpec: "30 1 * * *",
+			policy:   batch.DSTPolicySpec{SkippedTimes: batch.DSTShiftForward},
+			from:     utc(time.March, 26, 0, 0),
+			to:       utc(time.March, 29, 0, 0),
+			expected: []time.Time{utc(time.March, 26, 1, 30), utc(time.March, 27, 1, 30), utc(time.March, 28, 0, 30)},
+		},
+		"shifting forward can move a skipped time past a time which wasn't skipped": {
+			// 01:15 and 01:30 are shifted to 02:15 and 02:30 BST, which are also in the schedule in their own right
+			cronSpec: "15,30 1-2 * * *",
+			policy:   batch.DSTPolicySpec{SkippedTimes: batch.DSTShiftForward},
+			from:     utc(time.March, 27, 0, 0),
+			to:       utc(time.March, 27, 12, 0),
+			expected: []time.Time{utc(time.March, 27, 1, 15), utc(time.March, 27, 1, 30)},
+		},
+		"repeated time happens twice by default": {
+			cronSpec: "30 1 * * *",
+			from:     utc(time.October, 29, 0, 0),
+			to:       utc(time.October, 31, 0, 0),
+			expected: []time.Time{utc(time.October, 29, 0, 30), utc(time.October, 30, 0, 30), utc(time.October, 30, 1, 30)},
+		},
+		"repeated time happens twice": {
+			cronSpec: "30 1 * * *",
+			policy:   batch.DSTPolicySpec{RepeatedTimes: batch.DSTBothOccurrences},
+			from:     utc(time.October, 29, 0, 0),
+			to:       utc(time.October, 31, 0, 0),
+			expected: []time.Time{utc(time.October, 29, 0, 30), utc(time.October, 30, 0, 30), utc(time.October, 30, 1, 30)},
+		},
+		"repeated time happens at the first occurrence": {
+			cronSpec: "30 1 * * *",
+			policy:   batch.DSTPolicySpec{RepeatedTimes: batch.DSTFirstOccurrence},
+			from:     utc(time.October, 29, 0, 0),
+			to:       utc(time.October, 31, 0, 0),
+			expected: []time.Time{utc(time.October, 29, 0, 30), utc(time.October, 30, 0, 30)},
+		},
+		"repeated time happens at the last occurrence": {
+			cronSpec: "30 1 * * *",
+			policy:   batch.DSTPolicySpec{RepeatedTimes: batch.DSTLastOccurrence},
+			from:     utc(time.October, 29, 0, 0),
+			to:       utc(time.October, 31, 0, 0),
+			expected: []time.Time{utc(time.October, 29, 0, 30), utc(time.October, 30, 1, 30)},
+		},
+		"times just outside the repeated hour happen once": {
+			// 00:59 and 02:00 are BST and GMT respectively
+			cronSpec: "0,59 0,2 * * *",
+			from:     utc(time.October, 29, 22, 30),
+			to:       utc(time.October, 30, 12, 0),
+			expected: []time.Time{utc(time.October, 29, 23, 0), utc(time.October, 29, 23, 59), utc(time.October, 30, 2, 0), utc(time.October, 30, 2, 59)},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spec := newCronSchedule(tc.cronSpec)
+			spec.Location = londonLoc
+			sut := cronEventSchedule{spec, 0, tc.policy}
+
+			var forwards []time.Time
+			for next := sut.next(tc.from); next.Before(tc.to); next = sut.next(next) {
+				forwards = append(forwards, next)
+			}
+			var backwards []time.Time
+			for prev := sut.prev(tc.to); prev.After(tc.from); prev = sut.prev(prev.Add(-time.Second)) {
+				backwards = append([]time.Time{prev}, backwards...)
+			}
+
+			assert.Equal(t, tc.expected, forwards, "searching forwards")
+			assert.Equal(t, tc.expected, backwards, "searching backwards should find the same times")
+		})
+	}
+}
+
+func Test_locationWithOffset_instantsAt(t *testing.T) {
+	nyWithOffset := locationWithOffset{Location: nyLoc, OffsetSeconds: 60, DSTPolicy: batch.DSTPolicySpec{SkippedTimes: batch.DSTShiftForward}}
+
+	// 2022-03-13 02:30 doesn't exist in New York, and is shifted forward to 03:30 EDT (07:30 UTC). The additional
+	// offset then brings that forward a minute
+	assert.Equal(t, []time.Time{time.Date(2022, 3, 13, 7, 29, 0, 0, time.UTC)}, nyWithOffset.instantsAt(time.Date(2022, 3, 13, 2, 30, 0, 0, time.UTC)))
+	// 2022-11-06 01:30 happens at 05:30 UTC (EDT) and 06:30 UTC (EST)
+	assert.Equal(t, []time.Time{time.Date(2022, 11, 6, 5, 29, 0, 0, time.UTC), time.Date(2022, 11, 6, 6, 29, 0, 0, time.UTC)}, nyWithOffset.instantsAt(time.Date(2022, 11, 6, 1, 30, 0, 0, time.UTC)))
+	// An ordinary time happens once
+	assert.Equal(t, []time.Time{time.Date(2022, 6, 1, 12, 59, 0, 0, time.UTC)}, nyWithOffset.instantsAt(time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)))
+}
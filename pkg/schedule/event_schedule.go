@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// eventSchedule generates the times at which a single event in a ControlledJob's schedule happens.
+// This is usually a cron schedule, but a calendar can add extra times (e.g. early closes) or
+// remove some (e.g. holidays)
+type eventSchedule interface {
+	// next returns the earliest time strictly after t, or the zero time if there is none
+	next(t time.Time) time.Time
+	// prev returns the latest time at or before t, or the zero time if there is none
+	prev(t time.Time) time.Time
+}
+
+type eventDirection int
+
+const (
+	directionNext eventDirection = iota
+	directionPrevious
+)
+
+func adjacentTime(s eventSchedule, t time.Time, direction eventDirection) time.Time {
+	if direction == directionNext {
+		return s.next(t)
+	}
+	return s.prev(t)
+}
+
+// cronEventSchedule is an eventSchedule backed by a cron schedule, in a timezone with an optional additional offset
+//
+// The cron schedule describes wall clock times in the timezone. Rather than have the cron library work directly in
+// the timezone, where the answer for times skipped or repeated by DST changes depends on its implementation (and
+// on cronPrev's), we search for matching wall clock times as if the timezone was UTC, which has no DST changes.
+// The DST policy then decides when, if at all, each of those wall clock times happens
+type cronEventSchedule struct {
+	spec                    *cron.SpecSchedule
+	additionalOffsetSeconds int32
+	dstPolicy               batch.DSTPolicySpec
+}
+
+func (c cronEventSchedule) next(t time.Time) time.Time {
+	return c.inLocation().next(t)
+}
+
+func (c cronEventSchedule) prev(t time.Time) time.Time {
+	return c.inLocation().prev(t)
+}
+
+// inLocation finds the wall clock times the cron schedule happens at, and when they happen in the timezone of
+// the cron schedule, along with any additional offset requested by the user (e.g. if the schedule says 9am in
+// UTC-1 with an extra offset of +60s then it happens at 09:59 UTC) and the DST policy
+func (c cronEventSchedule) inLocation() wallClockEventSchedule {
+	wallClockSpec := *c.spec
+	wallClockSpec.Location = time.UTC
+	return wallClockEventSchedule{
+		wallClock: cronSpecSchedule{&wallClockSpec},
+		location:  locationWithOffset{Location: c.spec.Location, OffsetSeconds: c.additionalOffsetSeconds, DSTPolicy: c.dstPolicy},
+	}
+}
+
+// cronSpecSchedule is a cron schedule on its own, with none of the adjustments made by cronEventSchedule
+type cronSpecSchedule struct {
+	spec *cron.SpecSchedule
+}
+
+func (c cronSpecSchedule) next(t time.Time) time.Time {
+	return c.spec.Next(t)
+}
+
+func (c cronSpecSchedule) prev(t time.Time) time.Time {
+	return cronPrev(c.spec, t)
+}
+
+// excludingEventSchedule skips over any times in the wrapped schedule which fall on an excluded date
+type excludingEventSchedule struct {
+	eventSchedule
+	excluded exclusions
+	location locationWithOffset
+}
+
+func (e excludingEventSchedule) next(t time.Time) time.Time {
+	adjacent := e.eventSchedule.next(t)
+	for !adjacent.IsZero() {
+		excludedRange := e.excluded.rangeContaining(e.location.localTime(adjacent))
+		if excludedRange == nil {
+			return adjacent
+		}
+		// Jump straight over the whole excluded range, rather than stepping through each time inside it
+		dayAfter := excludedRange.last.AddDate(0, 0, 1)
+		adjacent = e.eventSchedule.next(e.location.startOfDay(dayAfter).Add(-time.Second))
+	}
+	return adjacent
+}
+
+func (e excludingEventSchedule) prev(t time.Time) time.Time {
+	adjacent := e.eventSchedule.prev(t)
+	for !adjacent.IsZero() {
+		excludedRange := e.excluded.rangeContaining(e.location.localTime(adjacent))
+		if excludedRange == nil {
+			return adjacent
+		}
+		adjacent = e.eventSchedule.prev(e.location.startOfDay(excludedRange.first).Add(-time.Second))
+	}
+	return adjacent
+}
+
+// unionEventSchedule happens at every time any of its schedules happen
+type unionEventSchedule []eventSchedule
+
+func (u unionEventSchedule) next(t time.Time) time.Time {
+	nearest, _ := u.nearest(t, directionNext)
+	return nearest
+}
+
+func (u unionEventSchedule) prev(t time.Time) time.Time {
+	nearest, _ := u.nearest(t, directionPrevious)
+	return nearest
+}
+
+// nearest returns the nearest time to t, in the given direction, of any of the schedules, and the index of the
+// schedule it came from. If none of them has a time in that direction, it returns the zero time and -1
+func (u unionEventSchedule) nearest(t time.Time, direction eventDirection) (time.Time, int) {
+	var nearest time.Time
+	idx := -1
+	for i, schedule := range u {
+		adjacent := adjacentTime(schedule, t, direction)
+		if !adjacent.IsZero() && eventIsNearer(adjacent, nearest, direction) {
+			nearest = adjacent
+			idx = i
+		}
+	}
+	return nearest, idx
+}
+
+// fixedEventSchedule happens at a fixed list of times
+type fixedEventSchedule []time.Time
+
+func newFixedEventSchedule(times []time.Time) fixedEventSchedule {
+	sorted := make(fixedEventSchedule, len(times))
+	copy(sorted, times)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	return sorted
+}
+
+func (f fixedEventSchedule) next(t time.Time) time.Time {
+	idx := sort.Search(len(f), func(i int) bool { return f[i].After(t) })
+	if idx == len(f) {
+		return time.Time{}
+	}
+	return f[idx]
+}
+
+func (f fixedEventSchedule) prev(t time.Time) time.Time {
+	idx := sort.Search(len(f), func(i int) bool { return f[i].After(t) })
+	if idx == 0 {
+		return time.Time{}
+	}
+	return f[idx-1]
+}
+
+// delayedEventSchedule happens a fixed duration after each time the wrapped schedule happens
+type delayedEventSchedule struct {
+	eventSchedule
+	delay time.Duration
+}
+
+func (d delayedEventSchedule) next(t time.Time) time.Time {
+	return d.delayed(d.eventSchedule.next(t.Add(-d.delay)))
+}
+
+func (d delayedEventSchedule) prev(t time.Time) time.Time {
+	return d.delayed(d.eventSchedule.prev(t.Add(-d.delay)))
+}
+
+func (d delayedEventSchedule) delayed(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(d.delay)
+}
@@ -0,0 +1,372 @@
+package schedule
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// The trading calendars of the exchanges we support out of the box. Each file has a version, which should be
+// bumped whenever its data changes
+//
+//go:embed exchanges/*.yaml
+var bundledExchangeFiles embed.FS
+
+// exchangeFile is the format of both the bundled exchange data files and local override files
+type exchangeFile struct {
+	// MIC is the market identifier code of the exchange, e.g. XLON
+	MIC string `json:"mic"`
+	// Aliases are other names the exchange can be referred to by, e.g. LSE
+	Aliases []string `json:"aliases,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Version string   `json:"version,omitempty"`
+	// Timezone the open and close times are in
+	Timezone string `json:"timezone,omitempty"`
+	// Open and Close are the regular times of the trading session, in the format hh:mm
+	Open  string `json:"open,omitempty"`
+	Close string `json:"close,omitempty"`
+	// ValidFrom and ValidTo are the first and last dates (inclusive) the holidays and half days are known for
+	ValidFrom string `json:"validFrom,omitempty"`
+	ValidTo   string `json:"validTo,omitempty"`
+	// Holidays are weekdays on which the exchange is closed
+	Holidays []exchangeDate `json:"holidays,omitempty"`
+	// HalfDays are days on which the exchange closes early
+	HalfDays []exchangeDate `json:"halfDays,omitempty"`
+	// OpenDays are only used in override files, to remove holidays which are already in the bundled data
+	OpenDays []exchangeDate `json:"openDays,omitempty"`
+}
+
+type exchangeDate struct {
+	Date string `json:"date"`
+	// Close is the early close time of a half day, in the format hh:mm
+	Close       string `json:"close,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// exchange is the parsed trading calendar of an exchange
+type exchange struct {
+	mic       string
+	version   string
+	location  *time.Location
+	open      time.Duration
+	close     time.Duration
+	validFrom time.Time
+	validTo   time.Time
+	// holidays and halfDayCloses are keyed by the date at midnight UTC
+	holidays      map[time.Time]bool
+	halfDayCloses map[time.Time]time.Duration
+}
+
+// ExchangeCalendars is a set of exchange trading calendars, used to schedule session events
+type ExchangeCalendars struct {
+	// exchanges are keyed by upper case MIC, and by each of their aliases
+	exchanges map[string]*exchange
+}
+
+var exchangeCalendars = mustLoadBundledExchangeCalendars()
+
+// UseExchangeCalendars replaces the exchange calendars used to schedule session events. By default, only the
+// bundled calendars are used
+func UseExchangeCalendars(calendars *ExchangeCalendars) {
+	exchangeCalendars = calendars
+}
+
+func mustLoadBundledExchangeCalendars() *ExchangeCalendars {
+	calendars, err := LoadExchangeCalendars("")
+	if err != nil {
+		panic(err)
+	}
+	return calendars
+}
+
+// LoadExchangeCalendars loads the bundled exchange calendars, and applies any override files (*.yaml) found in
+// overridesDir. An override file for a bundled exchange adds to its holidays and half days, and can remove
+// holidays using openDays. Any other fields it sets replace the bundled values. An override file for any other
+// exchange must define it in full
+func LoadExchangeCalendars(overridesDir string) (*ExchangeCalendars, error) {
+	files := map[string]*exchangeFile{}
+
+	bundledPaths, err := bundledExchangeFiles.ReadDir("exchanges")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bundled exchange calendars")
+	}
+	for _, entry := range bundledPaths {
+		data, err := bundledExchangeFiles.ReadFile("exchanges/" + entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read bundled exchange calendar %s", entry.Name())
+		}
+		file, err := parseExchangeFile(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid bundled exchange calendar %s", entry.Name())
+		}
+		files[strings.ToUpper(file.MIC)] = file
+	}
+
+	if overridesDir != "" {
+		overridePaths, err := filepath.Glob(filepath.Join(overridesDir, "*.yaml"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list exchange calendar overrides in %s", overridesDir)
+		}
+		sort.Strings(overridePaths)
+		for _, path := range overridePaths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read exchange calendar override %s", path)
+			}
+			override, err := parseExchangeFile(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid exchange calendar override %s", path)
+			}
+			mic := strings.ToUpper(override.MIC)
+			if base, ok := files[mic]; ok {
+				files[mic] = base.withOverride(override)
+			} else {
+				files[mic] = override
+			}
+		}
+	}
+
+	result := &ExchangeCalendars{exchanges: map[string]*exchange{}}
+	for mic, file := range files {
+		parsed, err := file.toExchange()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid exchange calendar %s", mic)
+		}
+		result.exchanges[mic] = parsed
+		for _, alias := range file.Aliases {
+			result.exchanges[strings.ToUpper(alias)] = parsed
+		}
+	}
+	return result, nil
+}
+
+// Versions returns the version of each exchange calendar, keyed by MIC
+func (c *ExchangeCalendars) Versions() map[string]string {
+	result := map[string]string{}
+	for _, exchange := range c.exchanges {
+		result[exchange.mic] = exchange.version
+	}
+	return result
+}
+
+func (c *ExchangeCalendars) get(name string) (*exchange, error) {
+	exchange, ok := c.exchanges[strings.ToUpper(name)]
+	if !ok {
+		return nil, errors.Errorf("unknown exchange %s", name)
+	}
+	return exchange, nil
+}
+
+func parseExchangeFile(data []byte) (*exchangeFile, error) {
+	file := &exchangeFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, err
+	}
+	if file.MIC == "" {
+		return nil, errors.New("mic must be set")
+	}
+	return file, nil
+}
+
+// withOverride returns a copy of the file with the override applied
+func (f exchangeFile) withOverride(override *exchangeFile) *exchangeFile {
+	result := f
+	overrideVersion := override.Version
+	if overrideVersion == "" {
+		overrideVersion = "local"
+	}
+	result.Version = f.Version + "+" + overrideVersion
+	result.Aliases = append(append([]string{}, f.Aliases...), override.Aliases...)
+	overrideString(&result.Name, override.Name)
+	overrideString(&result.Timezone, override.Timezone)
+	overrideString(&result.Open, override.Open)
+	overrideString(&result.Close, override.Close)
+	overrideString(&result.ValidFrom, override.ValidFrom)
+	overrideString(&result.ValidTo, override.ValidTo)
+
+	openDays := map[string]bool{}
+	for _, openDay := range override.OpenDays {
+		openDays[openDay.Date] = true
+	}
+	result.Holidays = nil
+	for _, holiday := range append(append([]exchangeDate{}, f.Holidays...), override.Holidays...) {
+		if !openDays[holiday.Date] {
+			result.Holidays = append(result.Holidays, holiday)
+		}
+	}
+	// Later half days for the same date replace earlier ones when parsed
+	result.HalfDays = append(append([]exchangeDate{}, f.HalfDays...), override.HalfDays...)
+	return &result
+}
+
+func overrideString(value *string, override string) {
+	if override != "" {
+		*value = override
+	}
+}
+
+func (f *exchangeFile) toExchange() (*exchange, error) {
+	if f.Timezone == "" {
+		return nil, errors.New("timezone must be set")
+	}
+	location, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve timezone named %s", f.Timezone)
+	}
+	result := &exchange{
+		mic:           strings.ToUpper(f.MIC),
+		version:       f.Version,
+		location:      location,
+		holidays:      map[time.Time]bool{},
+		halfDayCloses: map[time.Time]time.Duration{},
+	}
+	if result.open, err = parseTimeOfDay(f.Open); err != nil {
+		return nil, errors.Wrap(err, "invalid open")
+	}
+	if result.close, err = parseTimeOfDay(f.Close); err != nil {
+		return nil, errors.Wrap(err, "invalid close")
+	}
+	if result.validFrom, err = time.Parse(batch.ExclusionDateFormat, f.ValidFrom); err != nil {
+		return nil, errors.Wrap(err, "invalid validFrom")
+	}
+	if result.validTo, err = time.Parse(batch.ExclusionDateFormat, f.ValidTo); err != nil {
+		return nil, errors.Wrap(err, "invalid validTo")
+	}
+	for _, holiday := range f.Holidays {
+		date, err := time.Parse(batch.ExclusionDateFormat, holiday.Date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid holiday %s", holiday.Date)
+		}
+		result.holidays[date] = true
+	}
+	for _, halfDay := range f.HalfDays {
+		date, err := time.Parse(batch.ExclusionDateFormat, halfDay.Date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid half day %s", halfDay.Date)
+		}
+		if result.halfDayCloses[date], err = parseTimeOfDay(halfDay.Close); err != nil {
+			return nil, errors.Wrapf(err, "invalid close for half day %s", halfDay.Date)
+		}
+	}
+	return result, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("must be in the format hh:mm: %w", err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// covers returns true if the exchange's holidays are known for the date of t (in the exchange's timezone)
+func (e *exchange) covers(t time.Time) bool {
+	date := e.dateOf(t)
+	return !date.Before(e.validFrom) && !date.After(e.validTo)
+}
+
+// endOfCoverage returns the first instant which the exchange's holidays aren't known for
+func (e *exchange) endOfCoverage() time.Time {
+	return time.Date(e.validTo.Year(), e.validTo.Month(), e.validTo.Day()+1, 0, 0, 0, 0, e.location)
+}
+
+// dateOf returns the date of t in the exchange's timezone, at midnight UTC
+func (e *exchange) dateOf(t time.Time) time.Time {
+	local := t.In(e.location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (e *exchange) isTradingDay(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !e.holidays[date]
+}
+
+// sessionTime returns the time of the open or close of the exchange on the given date
+func (e *exchange) sessionTime(date time.Time, anchor batch.SessionAnchor) time.Time {
+	timeOfDay := e.open
+	if anchor == batch.SessionAnchorClose {
+		timeOfDay = e.close
+		if halfDayClose, ok := e.halfDayCloses[date]; ok {
+			timeOfDay = halfDayClose
+		}
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, e.location).Add(timeOfDay)
+}
+
+// sessionEventSchedule happens at a fixed offset from the open or close of an exchange, on each of its trading days.
+// There are no events outside the dates the exchange's calendar covers
+type sessionEventSchedule struct {
+	exchange *exchange
+	anchor   batch.SessionAnchor
+	offset   time.Duration
+}
+
+func (s sessionEventSchedule) next(t time.Time) time.Time {
+	// Start the day before, in case the offset moves an event across midnight
+	for date := s.exchange.dateOf(t.Add(-s.offset)).AddDate(0, 0, -1); !date.After(s.exchange.validTo); date = date.AddDate(0, 0, 1) {
+		if occurrence, ok := s.occurrenceOn(date); ok && occurrence.After(t) {
+			return occurrence
+		}
+	}
+	return time.Time{}
+}
+
+func (s sessionEventSchedule) prev(t time.Time) time.Time {
+	for date := s.exchange.dateOf(t.Add(-s.offset)).AddDate(0, 0, 1); !date.Before(s.exchange.validFrom); date = date.AddDate(0, 0, -1) {
+		if occurrence, ok := s.occurrenceOn(date); ok && !occurrence.After(t) {
+			return occurrence
+		}
+	}
+	return time.Time{}
+}
+
+func (s sessionEventSchedule) occurrenceOn(date time.Time) (time.Time, bool) {
+	if date.Before(s.exchange.validFrom) || date.After(s.exchange.validTo) || !s.exchange.isTradingDay(date) {
+		return time.Time{}, false
+	}
+	return s.exchange.sessionTime(date, s.anchor).Add(s.offset), true
+}
+
+func sessionEventScheduleFor(session *batch.SessionEventSpec) (sessionEventSchedule, error) {
+	exchange, err := exchangeCalendars.get(session.Exchange)
+	if err != nil {
+		return sessionEventSchedule{}, err
+	}
+	if session.Anchor != batch.SessionAnchorOpen && session.Anchor != batch.SessionAnchorClose {
+		return sessionEventSchedule{}, errors.Errorf("session anchor must be %s or %s", batch.SessionAnchorOpen, batch.SessionAnchorClose)
+	}
+	return sessionEventSchedule{
+		exchange: exchange,
+		anchor:   session.Anchor,
+		offset:   time.Duration(session.OffsetMinutes) * time.Minute,
+	}, nil
+}
+
+// checkSessionCoverage returns an error if any of the session events refer to an exchange whose calendar doesn't
+// cover the given time. Without this, a ControlledJob would silently stop being scheduled once the bundled data
+// runs out
+func checkSessionCoverage(events []batch.EventSpec, now time.Time) error {
+	for _, event := range events {
+		if event.Session == nil {
+			continue
+		}
+		exchange, err := exchangeCalendars.get(event.Session.Exchange)
+		if err != nil {
+			return err
+		}
+		if !exchange.covers(now) {
+			return errors.Errorf("the trading calendar for %s (version %s) only covers %s to %s",
+				exchange.mic, exchange.version, exchange.validFrom.Format(batch.ExclusionDateFormat), exchange.validTo.Format(batch.ExclusionDateFormat))
+		}
+	}
+	return nil
+}
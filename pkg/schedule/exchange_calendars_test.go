@@ -0,0 +1,181 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadExchangeCalendars_Bundled(t *testing.T) {
+	calendars, err := LoadExchangeCalendars("")
+
+	assert.Nil(t, err, "should not return an error")
+	versions := calendars.Versions()
+	for _, mic := range []string{"XLON", "XNYS", "XETR"} {
+		assert.NotEmpty(t, versions[mic], "should have bundled a calendar for %s", mic)
+	}
+	for alias, mic := range map[string]string{"LSE": "XLON", "nyse": "XNYS", "xetr": "XETR"} {
+		exchange, err := calendars.get(alias)
+		assert.Nil(t, err, "should find %s", alias)
+		assert.Equal(t, mic, exchange.mic)
+	}
+	_, err = calendars.get("XXXX")
+	assert.NotNil(t, err, "should not find an unknown exchange")
+}
+
+func Test_sessionEventSchedule(t *testing.T) {
+	calendars, _ := LoadExchangeCalendars("")
+	nyse, _ := calendars.get("XNYS")
+	lse, _ := calendars.get("XLON")
+
+	testCases := map[string]struct {
+		schedule  sessionEventSchedule
+		now       time.Time
+		direction eventDirection
+		expected  time.Time
+	}{
+		"[Next] skips a holiday": {
+			// 2024-11-28 is Thanksgiving. 09:00 New York is 14:00 UTC
+			schedule:  sessionEventSchedule{nyse, batch.SessionAnchorOpen, -30 * time.Minute},
+			now:       time.Date(2024, 11, 27, 15, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			expected:  time.Date(2024, 11, 29, 14, 0, 0, 0, time.UTC),
+		},
+		"[Next] uses the early close on a half day": {
+			// 13:15 New York is 18:15 UTC
+			schedule:  sessionEventSchedule{nyse, batch.SessionAnchorClose, 15 * time.Minute},
+			now:       time.Date(2024, 11, 29, 12, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			expected:  time.Date(2024, 11, 29, 18, 15, 0, 0, time.UTC),
+		},
+		"[Previous] skips a holiday": {
+			schedule:  sessionEventSchedule{nyse, batch.SessionAnchorOpen, -30 * time.Minute},
+			now:       time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC),
+			direction: directionPrevious,
+			expected:  time.Date(2024, 11, 27, 14, 0, 0, 0, time.UTC),
+		},
+		"[Previous] includes the given time": {
+			schedule:  sessionEventSchedule{nyse, batch.SessionAnchorOpen, -30 * time.Minute},
+			now:       time.Date(2024, 11, 27, 14, 0, 0, 0, time.UTC),
+			direction: directionPrevious,
+			expected:  time.Date(2024, 11, 27, 14, 0, 0, 0, time.UTC),
+		},
+		"[Next] skips a long weekend across a DST change": {
+			// 2024-03-29 and 2024-04-01 are Good Friday and Easter Monday. The clocks go forward on 2024-03-31
+			schedule:  sessionEventSchedule{lse, batch.SessionAnchorOpen, 0},
+			now:       time.Date(2024, 3, 28, 16, 30, 0, 0, time.UTC),
+			direction: directionNext,
+			expected:  time.Date(2024, 4, 2, 7, 0, 0, 0, time.UTC),
+		},
+		"[Next] an offset can move the event onto a different date": {
+			// Open at 08:00 on Monday 2024-04-08, less 10 hours is 22:00 on the Sunday (21:00 UTC)
+			schedule:  sessionEventSchedule{lse, batch.SessionAnchorOpen, -10 * time.Hour},
+			now:       time.Date(2024, 4, 6, 12, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			expected:  time.Date(2024, 4, 7, 21, 0, 0, 0, time.UTC),
+		},
+		"[Next] there are no events after the calendar's data runs out": {
+			schedule:  sessionEventSchedule{lse, batch.SessionAnchorOpen, 0},
+			now:       lse.validTo.AddDate(0, 0, 1),
+			direction: directionNext,
+			expected:  time.Time{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := adjacentTime(tc.schedule, tc.now, tc.direction)
+			assert.True(t, tc.expected.Equal(actual), "%s (expected) != %s (actual)", tc.expected, actual)
+		})
+	}
+}
+
+func Test_LoadExchangeCalendars_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("xlon-closure.yaml", `
+mic: XLON
+version: local-1
+holidays:
+- date: "2024-04-02"
+  description: Ad-hoc closure
+halfDays:
+- date: "2024-04-03"
+  close: "12:00"
+openDays:
+- date: "2024-04-01"
+`)
+	writeFile("xtst.yaml", `
+mic: XTST
+name: Test exchange
+timezone: UTC
+open: "10:00"
+close: "11:00"
+validFrom: "2024-01-01"
+validTo: "2024-12-31"
+`)
+
+	calendars, err := LoadExchangeCalendars(dir)
+
+	assert.Nil(t, err, "should not return an error")
+	lse, _ := calendars.get("LSE")
+	assert.Equal(t, "2027.1+local-1", lse.version)
+	assert.True(t, lse.isTradingDay(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)), "openDays should remove a bundled holiday")
+	assert.False(t, lse.isTradingDay(time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)), "should add the holiday")
+	assert.False(t, lse.isTradingDay(time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)), "should keep other bundled holidays")
+	assert.Equal(t, time.Date(2024, 4, 3, 11, 0, 0, 0, time.UTC), lse.sessionTime(time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), batch.SessionAnchorClose).UTC())
+
+	test, err := calendars.get("XTST")
+	assert.Nil(t, err, "should add a new exchange")
+	assert.Equal(t, time.Date(2024, 4, 3, 10, 0, 0, 0, time.UTC), test.sessionTime(time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), batch.SessionAnchorOpen).UTC())
+
+	writeFile("invalid.yaml", `
+mic: XBAD
+open: "10:00"
+`)
+	_, err = LoadExchangeCalendars(dir)
+	assert.NotNil(t, err, "a new exchange must be defined in full")
+}
+
+func Test_StateFor_SessionEvents(t *testing.T) {
+	// Start 30 minutes before the NYSE open, and stop 15 minutes after the close
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "UTC"},
+			Events: []batch.EventSpec{
+				{
+					Action:  batch.EventTypeStart,
+					Session: &batch.SessionEventSpec{Exchange: "NYSE", Anchor: batch.SessionAnchorOpen, OffsetMinutes: -30},
+				},
+				{
+					Action:  batch.EventTypeStop,
+					Session: &batch.SessionEventSpec{Exchange: "NYSE", Anchor: batch.SessionAnchorClose, OffsetMinutes: 15},
+				},
+			},
+		},
+	}
+
+	// 2024-11-29 is a half day, the day after Thanksgiving
+	sut, err := StateFor(controlledJob, nil, time.Date(2024, 11, 29, 17, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.True(t, sut.ShouldBeRunning())
+	assert.Equal(t, time.Date(2024, 11, 29, 14, 0, 0, 0, time.UTC), sut.StartOfCurrentRunPeriod().UTC())
+	assert.Equal(t, time.Date(2024, 11, 27, 21, 15, 0, 0, time.UTC), sut.LastStopTime().UTC())
+	assert.Equal(t, time.Date(2024, 11, 29, 18, 15, 0, 0, time.UTC), sut.NextEventTime().UTC())
+
+	_, err = StateFor(controlledJob, nil, time.Date(2040, 1, 2, 12, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err, "should return an error once the calendar's data runs out")
+
+	controlledJob.Spec.Events[0].Session.Exchange = "XXXX"
+	_, err = StateFor(controlledJob, nil, time.Date(2024, 11, 29, 17, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err, "should return an error for an unknown exchange")
+}
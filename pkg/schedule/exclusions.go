@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// dateRange is an inclusive range of dates. Both ends are stored at midnight UTC, but
+// represent local dates in whichever timezone they are compared against
+type dateRange struct {
+	first time.Time
+	last  time.Time
+}
+
+// exclusions is a list of dates on which start events are skipped
+type exclusions []dateRange
+
+func exclusionsFor(specs []batch.ExclusionSpec) (exclusions, error) {
+	result := make(exclusions, 0, len(specs))
+	for _, spec := range specs {
+		first, last, err := spec.AsDateRange()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid exclusion %s", spec.Date)
+		}
+		result = append(result, dateRange{first: first, last: last})
+	}
+	return result, nil
+}
+
+// rangeContaining returns the exclusion containing the date of t (in t's own location), or nil if that date
+// is not excluded
+func (e exclusions) rangeContaining(t time.Time) *dateRange {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range e {
+		if !date.Before(e[i].first) && !date.After(e[i].last) {
+			return &e[i]
+		}
+	}
+	return nil
+}
+
+// LocalDate returns the date of t in the given timezone, in the format used by exclusions
+func LocalDate(timezone batch.TimezoneSpec, t time.Time) (string, error) {
+	location, err := locationFor(timezone)
+	if err != nil {
+		return "", err
+	}
+	return location.localTime(t).Format(batch.ExclusionDateFormat), nil
+}
+
+// IsExcludedDate returns true if the given date, in the format used by exclusions, is one of the dates excluded by
+// specs
+func IsExcludedDate(specs []batch.ExclusionSpec, date string) (bool, error) {
+	excluded, err := exclusionsFor(specs)
+	if err != nil {
+		return false, err
+	}
+	t, err := time.Parse(batch.ExclusionDateFormat, date)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid date %s", date)
+	}
+	return excluded.rangeContaining(t) != nil, nil
+}
@@ -0,0 +1,129 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_exclusionsFor(t *testing.T) {
+	testCases := map[string]struct {
+		specs         []batch.ExclusionSpec
+		expected      exclusions
+		expectedError bool
+	}{
+		"no exclusions": {
+			expected: exclusions{},
+		},
+		"single date": {
+			specs: []batch.ExclusionSpec{{Date: "2022-12-25"}},
+			expected: exclusions{
+				{first: time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC), last: time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"date range": {
+			specs: []batch.ExclusionSpec{{Date: "2022-12-25", EndDate: "2022-12-26"}},
+			expected: exclusions{
+				{first: time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC), last: time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"invalid date": {
+			specs:         []batch.ExclusionSpec{{Date: "25/12/2022"}},
+			expectedError: true,
+		},
+		"end date before date": {
+			specs:         []batch.ExclusionSpec{{Date: "2022-12-26", EndDate: "2022-12-25"}},
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := exclusionsFor(tc.specs)
+			if tc.expectedError {
+				assert.NotNil(t, err, "should return an error")
+				return
+			}
+			assert.Nil(t, err, "should not return an error")
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_excludingEventSchedule(t *testing.T) {
+	// 9am every weekday. 2022-02-02 is a Wednesday
+	weekdays := newCronSchedule("0 9 * * MON-FRI")
+	weekdays.Location = time.UTC
+
+	excludeWednesday, _ := exclusionsFor([]batch.ExclusionSpec{{Date: "2022-02-02"}})
+	excludeWednesdayToFriday, _ := exclusionsFor([]batch.ExclusionSpec{{Date: "2022-02-02", EndDate: "2022-02-04"}})
+
+	testCases := map[string]struct {
+		now       time.Time
+		direction eventDirection
+		excluded  exclusions
+		expected  time.Time
+	}{
+		"[Next] no exclusions": {
+			now:       time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			expected:  time.Date(2022, 2, 2, 9, 0, 0, 0, time.UTC),
+		},
+		"[Next] skips an excluded date": {
+			now:       time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			excluded:  excludeWednesday,
+			expected:  time.Date(2022, 2, 3, 9, 0, 0, 0, time.UTC),
+		},
+		"[Next] skips a range of excluded dates": {
+			now:       time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC),
+			direction: directionNext,
+			excluded:  excludeWednesdayToFriday,
+			expected:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		},
+		"[Previous] skips an excluded date": {
+			now:       time.Date(2022, 2, 3, 8, 0, 0, 0, time.UTC),
+			direction: directionPrevious,
+			excluded:  excludeWednesday,
+			expected:  time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+		"[Previous] skips a range of excluded dates": {
+			now:       time.Date(2022, 2, 5, 12, 0, 0, 0, time.UTC),
+			direction: directionPrevious,
+			excluded:  excludeWednesdayToFriday,
+			expected:  time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+		"[Previous] exclusions in the future have no effect": {
+			now:       time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC),
+			direction: directionPrevious,
+			excluded:  excludeWednesday,
+			expected:  time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			schedule := excludingEventSchedule{cronEventSchedule{weekdays, 0, batch.DSTPolicySpec{}}, tc.excluded, locationWithOffset{Location: time.UTC}}
+			actual := adjacentTime(schedule, tc.now, tc.direction)
+			assert.True(t, tc.expected.Equal(actual), "%s (expected) != %s (actual)", tc.expected, actual)
+		})
+	}
+}
+
+func Test_excludingEventSchedule_usesLocalDate(t *testing.T) {
+	// 9pm every day in New York is 2am the following day in UTC. Exclusions should apply to the New York date
+	nineEveryEvening := newCronSchedule("0 21 * * *")
+	nineEveryEvening.Location = nyLoc
+
+	now := time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	excludeTuesday, _ := exclusionsFor([]batch.ExclusionSpec{{Date: "2022-02-01"}})
+	actual := excludingEventSchedule{cronEventSchedule{nineEveryEvening, 0, batch.DSTPolicySpec{}}, excludeTuesday, locationWithOffset{Location: nyLoc}}.next(now)
+	assert.True(t, time.Date(2022, 2, 3, 2, 0, 0, 0, time.UTC).Equal(actual), "excluding Tuesday in New York should skip to Wednesday evening, but got %s", actual)
+
+	excludeWednesday, _ := exclusionsFor([]batch.ExclusionSpec{{Date: "2022-02-02"}})
+	actual = excludingEventSchedule{cronEventSchedule{nineEveryEvening, 0, batch.DSTPolicySpec{}}, excludeWednesday, locationWithOffset{Location: nyLoc}}.next(now)
+	assert.True(t, time.Date(2022, 2, 2, 2, 0, 0, 0, time.UTC).Equal(actual), "excluding Wednesday in New York should not skip Tuesday evening, but got %s", actual)
+}
@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// friendlyEventScheduleFor builds the schedule of an event with a FriendlyScheduleSpec. Its days of the week and days
+// of the month become cron schedules, one for each time of day, so that they work exactly like any other cron
+// schedule (including on extra working days). The ways of choosing days which cron can't express are handled by a
+// monthlyWallClockSchedule
+func (c calendar) friendlyEventScheduleFor(spec *batch.FriendlyScheduleSpec, location locationWithOffset) (eventSchedule, error) {
+	cronSpecs, err := spec.AsCronSpecs()
+	if err != nil {
+		return nil, err
+	}
+	var result unionEventSchedule
+	for _, cronSpec := range cronSpecs {
+		specSchedule, err := parseCronSpec(cronSpec, location.Location)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c.cronEventScheduleFor(specSchedule, location))
+	}
+	if spec.HasMonthlyRules() {
+		monthlySchedule, err := monthlyWallClockScheduleFor(spec)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, wallClockEventSchedule{monthlySchedule, location})
+	}
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	return result, nil
+}
+
+// monthlyWallClockSchedule happens at the given wall clock times of day (in UTC) on the days of each month chosen by
+// the weekdays of the month and the last business day of the month
+type monthlyWallClockSchedule struct {
+	weekdaysOfMonth        []weekdayOfMonth
+	lastBusinessDayOfMonth bool
+	// timesOfDay are offsets from midnight, in order
+	timesOfDay []time.Duration
+}
+
+type weekdayOfMonth struct {
+	weekday time.Weekday
+	// week is 1 for the first such day in the month, 2 for the second, and so on, or -1 for the last
+	week int
+}
+
+// monthlySearchLimit is how many months to search through before giving up. Like cronPrev, we give up if there is
+// nothing in the next (or previous) five years
+const monthlySearchLimit = 5 * 12
+
+func monthlyWallClockScheduleFor(spec *batch.FriendlyScheduleSpec) (monthlyWallClockSchedule, error) {
+	result := monthlyWallClockSchedule{lastBusinessDayOfMonth: spec.LastBusinessDayOfMonth}
+	for _, weekdayOfMonthSpec := range spec.WeekdaysOfMonth {
+		weekday, week, err := weekdayOfMonthSpec.AsWeekday()
+		if err != nil {
+			return monthlyWallClockSchedule{}, err
+		}
+		result.weekdaysOfMonth = append(result.weekdaysOfMonth, weekdayOfMonth{weekday, week})
+	}
+	timesOfDay, err := spec.AllTimesOfDay()
+	if err != nil {
+		return monthlyWallClockSchedule{}, err
+	}
+	sort.Slice(timesOfDay, func(i, j int) bool { return timesOfDay[i] < timesOfDay[j] })
+	result.timesOfDay = timesOfDay
+	return result, nil
+}
+
+func (m monthlyWallClockSchedule) next(t time.Time) time.Time {
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= monthlySearchLimit; i++ {
+		for _, day := range m.daysIn(month) {
+			for _, timeOfDay := range m.timesOfDay {
+				if candidate := day.Add(timeOfDay); candidate.After(t) {
+					return candidate
+				}
+			}
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	return time.Time{}
+}
+
+func (m monthlyWallClockSchedule) prev(t time.Time) time.Time {
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= monthlySearchLimit; i++ {
+		days := m.daysIn(month)
+		for d := len(days) - 1; d >= 0; d-- {
+			for j := len(m.timesOfDay) - 1; j >= 0; j-- {
+				if candidate := days[d].Add(m.timesOfDay[j]); !candidate.After(t) {
+					return candidate
+				}
+			}
+		}
+		month = month.AddDate(0, -1, 0)
+	}
+	return time.Time{}
+}
+
+// daysIn lists, in order, the days in the month starting at the given time that the schedule happens on
+func (m monthlyWallClockSchedule) daysIn(month time.Time) []time.Time {
+	var result []time.Time
+	for _, weekdayOfMonth := range m.weekdaysOfMonth {
+		if day, ok := weekdayOfMonth.in(month); ok {
+			result = append(result, day)
+		}
+	}
+	if m.lastBusinessDayOfMonth {
+		day := month.AddDate(0, 1, -1)
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, -1)
+		}
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// in returns the day in the month starting at the given time, if the month has one
+func (w weekdayOfMonth) in(month time.Time) (time.Time, bool) {
+	if w.week == -1 {
+		lastDay := month.AddDate(0, 1, -1)
+		daysAfterWeekday := (int(lastDay.Weekday()) - int(w.weekday) + 7) % 7
+		return lastDay.AddDate(0, 0, -daysAfterWeekday), true
+	}
+	daysUntilWeekday := (int(w.weekday) - int(month.Weekday()) + 7) % 7
+	day := month.AddDate(0, 0, daysUntilWeekday+7*(w.week-1))
+	return day, day.Month() == month.Month()
+}
@@ -0,0 +1,203 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_monthlyWallClockSchedule(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	thirdFriday := monthlyWallClockSchedule{
+		weekdaysOfMonth: []weekdayOfMonth{{time.Friday, 3}},
+		timesOfDay:      []time.Duration{9 * time.Hour},
+	}
+	lastFriday := monthlyWallClockSchedule{
+		weekdaysOfMonth: []weekdayOfMonth{{time.Friday, -1}},
+		timesOfDay:      []time.Duration{9 * time.Hour},
+	}
+	fifthMonday := monthlyWallClockSchedule{
+		weekdaysOfMonth: []weekdayOfMonth{{time.Monday, 5}},
+		timesOfDay:      []time.Duration{9 * time.Hour},
+	}
+	lastBusinessDay := monthlyWallClockSchedule{
+		lastBusinessDayOfMonth: true,
+		timesOfDay:             []time.Duration{6 * time.Hour, 18*time.Hour + 30*time.Minute},
+	}
+
+	// 2024-03-01 is a Friday and 2024-03-31 is a Sunday
+	testCases := map[string]struct {
+		schedule  monthlyWallClockSchedule
+		now       time.Time
+		direction eventDirection
+		expected  time.Time
+	}{
+		"[Next] nth weekday": {
+			schedule:  thirdFriday,
+			now:       at(time.March, 1, 0, 0),
+			direction: directionNext,
+			expected:  at(time.March, 15, 9, 0),
+		},
+		"[Next] nth weekday in the next month": {
+			schedule:  thirdFriday,
+			now:       at(time.March, 15, 9, 0),
+			direction: directionNext,
+			expected:  at(time.April, 19, 9, 0),
+		},
+		"[Previous] nth weekday includes the given time": {
+			schedule:  thirdFriday,
+			now:       at(time.March, 15, 9, 0),
+			direction: directionPrevious,
+			expected:  at(time.March, 15, 9, 0),
+		},
+		"[Next] last weekday": {
+			schedule:  lastFriday,
+			now:       at(time.March, 1, 0, 0),
+			direction: directionNext,
+			expected:  at(time.March, 29, 9, 0),
+		},
+		"[Previous] last weekday": {
+			schedule:  lastFriday,
+			now:       at(time.March, 29, 8, 59),
+			direction: directionPrevious,
+			expected:  at(time.February, 23, 9, 0),
+		},
+		"[Next] months without a fifth weekday are skipped": {
+			// 2024-04-29 is the fifth Monday in April, and the next is 2024-07-29
+			schedule:  fifthMonday,
+			now:       at(time.April, 29, 9, 0),
+			direction: directionNext,
+			expected:  at(time.July, 29, 9, 0),
+		},
+		"[Previous] months without a fifth weekday are skipped": {
+			schedule:  fifthMonday,
+			now:       at(time.July, 29, 8, 0),
+			direction: directionPrevious,
+			expected:  at(time.April, 29, 9, 0),
+		},
+		"[Next] last business day skips a weekend at the end of the month": {
+			schedule:  lastBusinessDay,
+			now:       at(time.March, 1, 0, 0),
+			direction: directionNext,
+			expected:  at(time.March, 29, 6, 0),
+		},
+		"[Next] last business day at a later time of day": {
+			schedule:  lastBusinessDay,
+			now:       at(time.March, 29, 6, 0),
+			direction: directionNext,
+			expected:  at(time.March, 29, 18, 30),
+		},
+		"[Previous] last business day at an earlier time of day": {
+			schedule:  lastBusinessDay,
+			now:       at(time.March, 29, 18, 0),
+			direction: directionPrevious,
+			expected:  at(time.March, 29, 6, 0),
+		},
+		"[Previous] last business day in the previous month": {
+			// 2024-02-29 is a Thursday
+			schedule:  lastBusinessDay,
+			now:       at(time.March, 29, 5, 0),
+			direction: directionPrevious,
+			expected:  at(time.February, 29, 18, 30),
+		},
+		"[Next] nothing to find": {
+			schedule:  monthlyWallClockSchedule{lastBusinessDayOfMonth: true},
+			now:       at(time.March, 1, 0, 0),
+			direction: directionNext,
+			expected:  time.Time{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := adjacentTime(tc.schedule, tc.now, tc.direction)
+			assert.True(t, tc.expected.Equal(actual), "%s (expected) != %s (actual)", tc.expected, actual)
+		})
+	}
+}
+
+func Test_StateFor_FriendlySchedules(t *testing.T) {
+	// A month-end batch window: start at 18:00 London time on the last business day of the month, and on the
+	// 15th, and stop six hours later
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "Europe/London"},
+			Events: []batch.EventSpec{
+				{
+					Action: batch.EventTypeStart,
+					Schedule: &batch.FriendlyScheduleSpec{
+						TimeOfDay:              "18:00",
+						DaysOfMonth:            []int32{15},
+						LastBusinessDayOfMonth: true,
+					},
+					RunFor: &metav1.Duration{Duration: 6 * time.Hour},
+				},
+			},
+		},
+	}
+
+	// 2024-05-31 is a Friday, and 18:00 London time is 17:00 UTC
+	sut, err := StateFor(controlledJob, nil, time.Date(2024, 5, 31, 20, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.True(t, sut.ShouldBeRunning())
+	assert.Equal(t, time.Date(2024, 5, 31, 17, 0, 0, 0, time.UTC), sut.StartOfCurrentRunPeriod().UTC())
+	assert.Equal(t, time.Date(2024, 5, 15, 23, 0, 0, 0, time.UTC), sut.LastStopTime().UTC())
+	assert.Equal(t, time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC), sut.NextEventTime().UTC())
+}
+
+func Test_StateFor_FriendlyScheduleDaysOfMonth(t *testing.T) {
+	// At 09:00 and 17:00 on the 31st of each month, which skips months with fewer days
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "UTC"},
+			Events: []batch.EventSpec{
+				{
+					Action:   batch.EventTypeStart,
+					Schedule: &batch.FriendlyScheduleSpec{TimesOfDay: []string{"09:00", "17:00"}, DaysOfMonth: []int32{31}},
+				},
+			},
+			ConcurrencyPolicy: batch.AllowConcurrent,
+		},
+	}
+
+	sut, err := StateFor(controlledJob, nil, time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), sut.StartOfCurrentRunPeriod().UTC())
+	assert.Equal(t, time.Date(2024, 3, 31, 17, 0, 0, 0, time.UTC), sut.NextEventTime().UTC())
+
+	sut, err = StateFor(controlledJob, nil, time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.Equal(t, time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC), sut.NextEventTime().UTC())
+}
+
+func Test_StateFor_InvalidFriendlySchedules(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	for name, schedule := range map[string]batch.FriendlyScheduleSpec{
+		"no time of day":        {DaysOfWeek: "MON-FRI"},
+		"no days":               {TimeOfDay: "09:00"},
+		"invalid time of day":   {TimesOfDay: []string{"09:00", "24:00"}, DaysOfWeek: "MON-FRI"},
+		"invalid day of month":  {TimeOfDay: "09:00", DaysOfMonth: []int32{32}},
+		"invalid week of month": {TimeOfDay: "09:00", WeekdaysOfMonth: []batch.WeekdayOfMonthSpec{{Day: "FRI", Week: 0}}},
+		"invalid weekday":       {TimeOfDay: "09:00", WeekdaysOfMonth: []batch.WeekdayOfMonthSpec{{Day: "FOO", Week: 1}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   []batch.EventSpec{{Action: batch.EventTypeStart, Schedule: &schedule}},
+				},
+			}
+			_, err := StateFor(controlledJob, nil, now)
+			assert.NotNil(t, err, "should return an error")
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package schedule
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// everyDescriptor starts a cron schedule which happens at a constant interval, rather than at wall clock times
+const everyDescriptor = "@every "
+
+func isIntervalSchedule(cronSpec string) bool {
+	return strings.HasPrefix(strings.TrimSpace(cronSpec), everyDescriptor)
+}
+
+// intervalEventScheduleFor builds the schedule of an event with an '@every' cron schedule. The cron library's own
+// ConstantDelaySchedule counts from whenever it's asked, so can't tell us when the event last happened. Instead the
+// interval is anchored to the event's epoch
+func intervalEventScheduleFor(event batch.EventSpec, location locationWithOffset) (eventSchedule, error) {
+	schedule, err := cron.ParseStandard(strings.TrimSpace(event.CronSchedule))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse cron schedule")
+	}
+	constantDelay, ok := schedule.(cron.ConstantDelaySchedule)
+	if !ok {
+		return nil, errors.Errorf("Expected instance of ConstantDelaySchedule for cron schedule %s", event.CronSchedule)
+	}
+
+	epoch, hasOffset, err := event.AsEpoch()
+	if err != nil {
+		return nil, err
+	}
+	if !hasOffset {
+		epoch = location.fromWallTime(epoch)
+	}
+	// Occurrences are in the epoch's location, so normalise it to UTC to match the other event schedules
+	return intervalEventSchedule{epoch: epoch.UTC(), interval: constantDelay.Delay}, nil
+}
+
+// intervalEventSchedule happens at the epoch, and at every whole multiple of the interval before and after it. The
+// interval is elapsed time, so clock changes have no effect on it
+type intervalEventSchedule struct {
+	epoch    time.Time
+	interval time.Duration
+}
+
+func (i intervalEventSchedule) next(t time.Time) time.Time {
+	return i.epoch.Add((i.intervalsBefore(t) + 1) * i.interval)
+}
+
+func (i intervalEventSchedule) prev(t time.Time) time.Time {
+	return i.epoch.Add(i.intervalsBefore(t) * i.interval)
+}
+
+// intervalsBefore returns how many whole intervals there are from the epoch to t, rounding down. This is negative if
+// t is before the epoch
+func (i intervalEventSchedule) intervalsBefore(t time.Time) time.Duration {
+	sinceEpoch := t.Sub(i.epoch)
+	intervals := sinceEpoch / i.interval
+	if sinceEpoch%i.interval < 0 {
+		intervals--
+	}
+	return intervals
+}
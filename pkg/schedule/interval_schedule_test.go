@@ -0,0 +1,118 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_intervalEventSchedule(t *testing.T) {
+	epoch := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	every90Minutes := intervalEventSchedule{epoch: epoch, interval: 90 * time.Minute}
+
+	testCases := map[string]struct {
+		now       time.Time
+		direction eventDirection
+		expected  time.Time
+	}{
+		"[Next] after the epoch": {
+			now:       epoch.Add(2 * time.Hour),
+			direction: directionNext,
+			expected:  epoch.Add(3 * time.Hour),
+		},
+		"[Next] is strictly after the given time": {
+			now:       epoch.Add(90 * time.Minute),
+			direction: directionNext,
+			expected:  epoch.Add(3 * time.Hour),
+		},
+		"[Next] before the epoch": {
+			now:       epoch.Add(-100 * time.Minute),
+			direction: directionNext,
+			expected:  epoch.Add(-90 * time.Minute),
+		},
+		"[Previous] after the epoch": {
+			now:       epoch.Add(2 * time.Hour),
+			direction: directionPrevious,
+			expected:  epoch.Add(90 * time.Minute),
+		},
+		"[Previous] includes the given time": {
+			now:       epoch,
+			direction: directionPrevious,
+			expected:  epoch,
+		},
+		"[Previous] before the epoch": {
+			now:       epoch.Add(-100 * time.Minute),
+			direction: directionPrevious,
+			expected:  epoch.Add(-3 * time.Hour),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := adjacentTime(every90Minutes, tc.now, tc.direction)
+			assert.True(t, tc.expected.Equal(actual), "%s (expected) != %s (actual)", tc.expected, actual)
+		})
+	}
+}
+
+func Test_StateFor_IntervalEvents(t *testing.T) {
+	// Start every 90 minutes from 09:00 London time on 2024-03-30 and run for 30 minutes. The clocks go forward
+	// overnight, but the interval is elapsed time so isn't affected
+	controlledJob := &batch.ControlledJob{
+		Spec: batch.ControlledJobSpec{
+			Timezone: batch.TimezoneSpec{Name: "Europe/London"},
+			Events: []batch.EventSpec{
+				{
+					Action:       batch.EventTypeStart,
+					CronSchedule: "@every 90m",
+					Epoch:        "2024-03-30T09:00",
+					RunFor:       &metav1.Duration{Duration: 30 * time.Minute},
+				},
+			},
+		},
+	}
+
+	// 16 intervals after 09:00 UTC on 2024-03-30 is 09:00 UTC (10:00 London time) on 2024-03-31
+	sut, err := StateFor(controlledJob, nil, time.Date(2024, 3, 31, 9, 10, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.True(t, sut.ShouldBeRunning())
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), sut.StartOfCurrentRunPeriod().UTC())
+	assert.Equal(t, time.Date(2024, 3, 31, 8, 0, 0, 0, time.UTC), sut.LastStopTime().UTC())
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC), sut.NextEventTime().UTC())
+	assert.Equal(t, time.UTC, sut.StartOfCurrentRunPeriod().Location(), "times should be in UTC, whatever the timezone of the epoch")
+	assert.Equal(t, time.UTC, sut.NextEventTime().Location(), "times should be in UTC, whatever the timezone of the epoch")
+
+	controlledJob.Spec.Events[0].Epoch = "2024-03-30T10:00:00+01:00"
+	controlledJob.Spec.Timezone = batch.TimezoneSpec{Name: "America/New_York"}
+	sut, err = StateFor(controlledJob, nil, time.Date(2024, 3, 31, 9, 10, 0, 0, time.UTC))
+
+	assert.Nil(t, err, "Should not return an error")
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), sut.StartOfCurrentRunPeriod().UTC(), "an epoch with an explicit offset doesn't depend on the timezone")
+	assert.Equal(t, time.UTC, sut.StartOfCurrentRunPeriod().Location(), "times should be in UTC, whatever the offset of the epoch")
+}
+
+func Test_StateFor_InvalidIntervalEvents(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	for name, event := range map[string]batch.EventSpec{
+		"invalid interval":           {Action: batch.EventTypeStart, CronSchedule: "@every 90 minutes"},
+		"invalid epoch":              {Action: batch.EventTypeStart, CronSchedule: "@every 90m", Epoch: "30/03/2024"},
+		"epoch without an interval":  {Action: batch.EventTypeStart, CronSchedule: "0 9 * * *", Epoch: "2024-03-30T09:00"},
+		"unsupported cron schedules": {Action: batch.EventTypeStart, CronSchedule: "@reboot"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   []batch.EventSpec{event},
+				},
+			}
+			_, err := StateFor(controlledJob, nil, now)
+			assert.NotNil(t, err, "should return an error")
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// checkEventReferences checks the names of the given events, and the relativeTo references between them. It returns
+// an error for each event whose name or reference is invalid, or nil for each event which is fine: names must be
+// unique, every event referred to must exist, and the references must not form a cycle
+func checkEventReferences(events []batch.EventSpec) []error {
+	result := make([]error, len(events))
+	byName := map[string]int{}
+	for i, event := range events {
+		if event.Name == "" {
+			continue
+		}
+		if j, ok := byName[event.Name]; ok {
+			result[i] = errors.Errorf("name %q is already used by events[%d]", event.Name, j)
+			continue
+		}
+		byName[event.Name] = i
+	}
+
+	for i, event := range events {
+		if result[i] != nil || event.RelativeTo == nil {
+			continue
+		}
+		// Follow the references from this event until they reach an event with a schedule of its own
+		chain := []string{describeEventName(i, event)}
+		visited := map[int]bool{i: true}
+		for current := i; events[current].RelativeTo != nil; {
+			j, ok := byName[events[current].RelativeTo.Event]
+			if !ok {
+				if current == i {
+					result[i] = errors.Errorf("relativeTo refers to an event named %q, but there isn't one", events[current].RelativeTo.Event)
+				}
+				// Otherwise the missing event is reported on the event which refers to it
+				break
+			}
+			chain = append(chain, describeEventName(j, events[j]))
+			if visited[j] {
+				result[i] = errors.Errorf("relativeTo references form a cycle: %s", strings.Join(chain, " -> "))
+				break
+			}
+			visited[j] = true
+			current = j
+		}
+	}
+	return result
+}
+
+// namedEventsFor returns the named events among the given events, or an error if any of their names or references
+// are invalid
+func namedEventsFor(events []batch.EventSpec) (map[string]batch.EventSpec, error) {
+	for i, err := range checkEventReferences(events) {
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid events[%d]", i)
+		}
+	}
+	result := map[string]batch.EventSpec{}
+	for _, event := range events {
+		if event.Name != "" {
+			result[event.Name] = event
+		}
+	}
+	return result, nil
+}
+
+func describeEventName(i int, event batch.EventSpec) string {
+	if event.Name == "" {
+		return fmt.Sprintf("events[%d]", i)
+	}
+	return fmt.Sprintf("events[%d] (%s)", i, event.Name)
+}
+
+// relativeEventScheduleFor builds the schedule of an event which happens at a fixed offset on the clock in the given
+// location from each time another event is scheduled
+func (c calendar) relativeEventScheduleFor(relativeTo batch.RelativeEventSpec, location locationWithOffset) (eventSchedule, error) {
+	anchor, ok := c.namedEvents[relativeTo.Event]
+	if !ok {
+		return nil, errors.Errorf("relativeTo refers to an event named %q, but there isn't one", relativeTo.Event)
+	}
+	anchorSchedule, err := c.unexcludedEventScheduleFor(anchor)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid event %q", relativeTo.Event)
+	}
+	return wallClockEventSchedule{
+		wallClock: delayedEventSchedule{wallClockTimesOf{anchorSchedule, location}, relativeTo.Offset.Duration},
+		location:  location,
+	}, nil
+}
+
+// wallClockTimesOf is the schedule of wall clock times, as times in UTC, which the clock in a location shows each time
+// the wrapped schedule happens. It's the opposite of wallClockEventSchedule
+type wallClockTimesOf struct {
+	eventSchedule
+	location locationWithOffset
+}
+
+func (w wallClockTimesOf) next(wallTime time.Time) time.Time {
+	// The clock only shows times around wallTime within a couple of clock changes of this instant
+	approx := w.location.fromWallTime(wallTime)
+	var result, resultInstant time.Time
+	for t := w.eventSchedule.next(approx.Add(-2 * maxClockChange)); !t.IsZero(); t = w.eventSchedule.next(t) {
+		if !result.IsZero() && t.After(resultInstant.Add(maxClockChange)) {
+			break
+		}
+		if shown := w.location.wallClock(t); shown.After(wallTime) && (result.IsZero() || shown.Before(result)) {
+			result, resultInstant = shown, t
+		}
+	}
+	return result
+}
+
+func (w wallClockTimesOf) prev(wallTime time.Time) time.Time {
+	approx := w.location.fromWallTime(wallTime)
+	var result, resultInstant time.Time
+	for t := w.eventSchedule.prev(approx.Add(2 * maxClockChange)); !t.IsZero(); t = w.eventSchedule.prev(t.Add(-time.Nanosecond)) {
+		if !result.IsZero() && t.Before(resultInstant.Add(-maxClockChange)) {
+			break
+		}
+		if shown := w.location.wallClock(t); !shown.After(wallTime) && shown.After(result) {
+			result, resultInstant = shown, t
+		}
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+func relativeTo(event string, offset time.Duration) *batch.RelativeEventSpec {
+	return &batch.RelativeEventSpec{Event: event, Offset: metav1.Duration{Duration: offset}}
+}
+
+func Test_RelativeEvents_SessionClose(t *testing.T) {
+	// NYSE trades from 09:30 to 16:00 in New York, which is 13:30 to 20:00 UTC in June
+	sut := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeStart, Session: &batch.SessionEventSpec{Exchange: "NYSE", Anchor: batch.SessionAnchorOpen}},
+			{Name: "close", Action: batch.EventTypeRestart, Session: &batch.SessionEventSpec{Exchange: "NYSE", Anchor: batch.SessionAnchorClose}},
+			{Action: batch.EventTypeStop, RelativeTo: relativeTo("close", -10*time.Minute)},
+		},
+	}, nil)
+
+	window, err := sut.WindowAt(june(3, 15, 0))
+	assert.Nil(t, err)
+	if assert.NotNil(t, window) && assert.NotNil(t, window.Stop) {
+		assert.Equal(t, june(3, 13, 30), window.Start.Time.UTC())
+		assert.Equal(t, june(3, 19, 50), window.Stop.Time.UTC(), "should stop 10 minutes before the close")
+		assert.Equal(t, 2, window.Stop.EventIndex)
+	}
+
+	window, err = sut.WindowAt(june(8, 15, 0))
+	assert.Nil(t, err)
+	assert.Nil(t, window, "should not run at the weekend, when the exchange is closed")
+}
+
+func Test_RelativeEvents_Chain(t *testing.T) {
+	sut := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeRestart, RelativeTo: relativeTo("refresh", 30*time.Minute)},
+			{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+			{Name: "refresh", Action: batch.EventTypeRestart, RelativeTo: relativeTo("open", time.Hour)},
+			{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+		},
+	}, nil)
+
+	restartAfterRefresh := sut.compiled[0].schedule
+	assert.Equal(t, june(3, 10, 30), restartAfterRefresh.next(june(3, 9, 0)))
+	assert.Equal(t, june(4, 10, 30), restartAfterRefresh.next(june(3, 10, 30)))
+	assert.Equal(t, june(10, 10, 30), restartAfterRefresh.next(june(7, 12, 0)), "should follow the weekdays of the event it is relative to")
+	assert.Equal(t, june(3, 10, 30), restartAfterRefresh.prev(june(3, 10, 30)))
+	assert.Equal(t, june(7, 10, 30), restartAfterRefresh.prev(june(10, 10, 29)))
+
+	refresh := sut.compiled[2].schedule
+	assert.Equal(t, june(3, 10, 0), refresh.next(june(3, 9, 0)))
+}
+
+func Test_RelativeEvents_OffsetOnTheClock(t *testing.T) {
+	// The clocks go forward in London at 1am on 2024-03-31, so 10am the next day is only 11 hours after 10pm
+	// on the 30th
+	spec := batch.ControlledJobSpec{
+		Timezone: batch.TimezoneSpec{Name: "Europe/London"},
+		Events: []batch.EventSpec{
+			{Name: "evening", Action: batch.EventTypeStop, CronSchedule: "0 22 * * *"},
+			{Action: batch.EventTypeStart, RelativeTo: relativeTo("evening", 12*time.Hour)},
+		},
+	}
+	sut, err := Compile(&batch.ControlledJob{Spec: spec}, nil)
+	assert.Nil(t, err)
+
+	start := sut.compiled[1].schedule
+	assert.Equal(t, time.Date(2024, 3, 30, 10, 0, 0, 0, time.UTC), start.next(time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), start.next(time.Date(2024, 3, 30, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 30, 10, 0, 0, 0, time.UTC), start.prev(time.Date(2024, 3, 31, 8, 59, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), start.prev(time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)))
+}
+
+func Test_RelativeEvents_Exclusions(t *testing.T) {
+	sut := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+			{Action: batch.EventTypeStart, RelativeTo: relativeTo("open", 2*time.Hour)},
+			{Action: batch.EventTypeStop, RelativeTo: relativeTo("open", 8*time.Hour)},
+		},
+		Exclusions: []batch.ExclusionSpec{{Date: "2024-06-04"}},
+	}, nil)
+
+	assert.Equal(t, june(5, 11, 0), sut.compiled[1].schedule.next(june(3, 11, 0)), "relative start events should be skipped on excluded dates")
+	assert.Equal(t, june(4, 17, 0), sut.compiled[2].schedule.next(june(3, 17, 0)), "relative stop events should still happen on excluded dates")
+}
+
+func Test_RelativeEvents_InvalidReferences(t *testing.T) {
+	testCases := map[string]struct {
+		events        []batch.EventSpec
+		expectedError string
+	}{
+		"unknown event": {
+			events: []batch.EventSpec{
+				{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"},
+				{Action: batch.EventTypeStop, RelativeTo: relativeTo("close", -10*time.Minute)},
+			},
+			expectedError: `invalid events[1]: relativeTo refers to an event named "close", but there isn't one`,
+		},
+		"duplicate name": {
+			events: []batch.EventSpec{
+				{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"},
+				{Name: "open", Action: batch.EventTypeStop, CronSchedule: "0 17 * * *"},
+			},
+			expectedError: `invalid events[1]: name "open" is already used by events[0]`,
+		},
+		"cycle": {
+			events: []batch.EventSpec{
+				{Name: "open", Action: batch.EventTypeStart, RelativeTo: relativeTo("close", -8*time.Hour)},
+				{Name: "close", Action: batch.EventTypeStop, RelativeTo: relativeTo("open", 8*time.Hour)},
+			},
+			expectedError: "invalid events[0]: relativeTo references form a cycle: events[0] (open) -> events[1] (close) -> events[0] (open)",
+		},
+		"relative event with its own schedule": {
+			events: []batch.EventSpec{
+				{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"},
+				{Action: batch.EventTypeStop, CronSchedule: "0 17 * * *", RelativeTo: relativeTo("open", 8*time.Hour)},
+			},
+			expectedError: "an event relative to another event can't also have its own cronSchedule, schedule, session or at",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(&batch.ControlledJob{Spec: batch.ControlledJobSpec{Timezone: batch.TimezoneSpec{Name: "UTC"}, Events: tc.events}}, nil)
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package schedule
+
+import (
+	"time"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// EventSource says what produced the start or stop of a RunWindow
+type EventSource string
+
+const (
+	// EventSourceEvent is one of the ControlledJob's events
+	EventSourceEvent EventSource = "Event"
+	// EventSourceRunFor is the stop implied by a start event's runFor
+	EventSourceRunFor EventSource = "RunFor"
+	// EventSourceEarlyClose is an early close in the ControlledJob's calendar
+	EventSourceEarlyClose EventSource = "EarlyClose"
+)
+
+// Boundary is the start or stop of a RunWindow
+type Boundary struct {
+	Time   time.Time
+	Source EventSource
+	// EventIndex is the index in the ControlledJob's spec.events of the event which produced the boundary. For a
+	// stop implied by a runFor, it's the index of the start event with the runFor. It's -1 for an early close
+	EventIndex int
+}
+
+// RunWindow is a period during which a ControlledJob should be running: a run period, as far as the first stop
+// event in it.
+//
+// A window starts at the first start event after a stop. Any further start events before the next stop (see
+// State.StartOfCurrentRunPeriod) are duplicates, and don't start a new window. Restart events don't affect windows.
+// When a start and a stop event happen at the same instant, the ControlledJob's eventPrecedence says whether one
+// window ends and the next starts at that instant, or the start event is ignored. If it isn't set, the start event is
+// ignored, as it is by StateAt
+type RunWindow struct {
+	Start Boundary
+	// Stop is nil if the window doesn't end. This is always the case in a start-only schedule, where Jobs run to
+	// completion rather than being stopped, and otherwise means there is no later stop event
+	Stop *Boundary
+}
+
+// Contains returns true if t is inside the window. The window includes its start, but not its stop
+func (w RunWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start.Time) && (w.Stop == nil || t.Before(w.Stop.Time))
+}
+
+// WindowAt returns the window containing t, or nil if the ControlledJob shouldn't be running at t.
+// It returns the same error as StateAt if the schedule has start events before t but no stop events to say
+// where their window started
+func (c *CompiledSchedule) WindowAt(t time.Time) (*RunWindow, error) {
+	window, err := c.WindowsBefore(t).Next()
+	if err != nil || window == nil || !window.Contains(t) {
+		return nil, err
+	}
+	return window, nil
+}
+
+// IsRunningAt returns true if t is inside a window
+func (c *CompiledSchedule) IsRunningAt(t time.Time) (bool, error) {
+	window, err := c.WindowAt(t)
+	return window != nil, err
+}
+
+// WindowsFrom iterates forward through the windows which end after t, starting with the window containing t if
+// there is one
+func (c *CompiledSchedule) WindowsFrom(t time.Time) *WindowIterator {
+	return &WindowIterator{schedule: c, direction: directionNext, cursor: t}
+}
+
+// WindowsBefore iterates backward through the windows which start at or before t, starting with the window
+// containing t if there is one
+func (c *CompiledSchedule) WindowsBefore(t time.Time) *WindowIterator {
+	return &WindowIterator{schedule: c, direction: directionPrevious, cursor: t}
+}
+
+// WindowIterator iterates through the windows of a CompiledSchedule, in one direction
+type WindowIterator struct {
+	schedule  *CompiledSchedule
+	direction eventDirection
+	started   bool
+	done      bool
+	// cursor is the time to carry on searching from. Going forward, this is the stop of the last window. Going
+	// backward, the previous window starts strictly before it
+	cursor time.Time
+}
+
+// Next returns the next window in the iterator's direction, or nil once there are no more
+func (it *WindowIterator) Next() (*RunWindow, error) {
+	if it.done {
+		return nil, nil
+	}
+	var start *Boundary
+	var err error
+	switch {
+	case it.schedule.isStartOnly:
+		start = it.nextStartOnly()
+	case it.direction == directionNext:
+		start, err = it.nextStartForward()
+	default:
+		start, err = it.nextStartBackward()
+	}
+	it.started = true
+	if err != nil || start == nil {
+		it.done = true
+		return nil, err
+	}
+
+	window := &RunWindow{Start: *start}
+	if !it.schedule.isStartOnly {
+		window.Stop = it.schedule.nearest(start.Time, directionNext, batch.EventTypeStop)
+	}
+	switch {
+	case it.direction == directionPrevious, it.schedule.isStartOnly:
+		it.cursor = start.Time
+	case window.Stop != nil:
+		it.cursor = window.Stop.Time
+	default:
+		// A window which never stops is the last one
+		it.done = true
+	}
+	return window, nil
+}
+
+// nextStartOnly finds the start of the next window in a start-only schedule, where every start event starts a
+// new window
+func (it *WindowIterator) nextStartOnly() *Boundary {
+	if it.direction == directionNext {
+		if !it.started {
+			// The window containing the cursor, if there is one
+			if start := it.schedule.nearest(it.cursor, directionPrevious, batch.EventTypeStart); start != nil {
+				return start
+			}
+		}
+		return it.schedule.nearest(it.cursor, directionNext, batch.EventTypeStart)
+	}
+	if !it.started {
+		return it.schedule.nearest(it.cursor, directionPrevious, batch.EventTypeStart)
+	}
+	return it.schedule.nearestBefore(it.cursor, batch.EventTypeStart)
+}
+
+// maxSkippedStarts is how many start events which coincide with stop events are skipped before giving up.
+// Without it, a schedule in which every start event coincides with a stop event would be searched forever
+const maxSkippedStarts = 1000
+
+func (it *WindowIterator) nextStartForward() (*Boundary, error) {
+	if !it.started {
+		// The window containing the cursor, if there is one
+		start, err := it.schedule.windowStartAt(it.cursor)
+		if err != nil || start != nil {
+			return start, err
+		}
+	}
+	for skipped := 0; skipped < maxSkippedStarts; skipped++ {
+		nextStart := it.schedule.firstStartFrom(it.cursor)
+		if nextStart == nil {
+			return nil, nil
+		}
+		start, err := it.schedule.windowStartAt(nextStart.Time)
+		if err != nil || start != nil {
+			return start, err
+		}
+		// The start event coincides with a stop event which takes precedence over it, so keep looking from there
+		it.cursor = nextStart.Time
+	}
+	return nil, nil
+}
+
+func (it *WindowIterator) nextStartBackward() (*Boundary, error) {
+	for skipped := 0; skipped < maxSkippedStarts; skipped++ {
+		// The most recent start event before the cursor is either the start of the previous window, or a
+		// duplicate start inside it
+		var latestStart *Boundary
+		if it.started {
+			latestStart = it.schedule.nearestBefore(it.cursor, batch.EventTypeStart)
+		} else {
+			latestStart = it.schedule.nearest(it.cursor, directionPrevious, batch.EventTypeStart)
+		}
+		if latestStart == nil {
+			return nil, nil
+		}
+		start, err := it.schedule.windowStartAt(latestStart.Time)
+		if err != nil || start != nil {
+			return start, err
+		}
+		// The start event coincides with a stop event which takes precedence over it, so keep looking from there
+		it.started = true
+		it.cursor = latestStart.Time
+	}
+	return nil, nil
+}
+
+// windowStartAt returns the start of the window containing t, or nil if t isn't inside a window. The window
+// starts at the first start event after the most recent stop event
+func (c *CompiledSchedule) windowStartAt(t time.Time) (*Boundary, error) {
+	lastStop := c.nearest(t, directionPrevious, batch.EventTypeStop)
+	if lastStop == nil {
+		if c.nearest(t, directionPrevious, batch.EventTypeStart) == nil {
+			return nil, nil
+		}
+		// As in StateAt, there's no way to tell where the window started
+		_, err := c.startOfRunPeriodAfter(nil)
+		return nil, err
+	}
+	start := c.firstStartFrom(lastStop.Time)
+	if start == nil || start.Time.After(t) {
+		return nil, nil
+	}
+	return start, nil
+}
+
+// firstStartFrom finds the first start event which can start a window after a stop event at t: the first start event
+// after t, or at t itself if start events take precedence
+func (c *CompiledSchedule) firstStartFrom(t time.Time) *Boundary {
+	if c.precedence == batch.StartEventPrecedence {
+		if start := c.nearest(t, directionPrevious, batch.EventTypeStart); start != nil && start.Time.Equal(t) {
+			return start
+		}
+	}
+	return c.nearest(t, directionNext, batch.EventTypeStart)
+}
+
+// nearest finds the nearest event with the given action in the given direction. As with the eventSchedule it
+// searches, the previous event may be at t itself but the next event is strictly after t
+func (c *CompiledSchedule) nearest(t time.Time, direction eventDirection, action batch.EventType) *Boundary {
+	var result *Boundary
+	for _, event := range c.compiled {
+		if event.action != action {
+			continue
+		}
+		adjacent := adjacentTime(event.schedule, t, direction)
+		if adjacent.IsZero() {
+			continue
+		}
+		if result == nil || eventIsNearer(adjacent, result.Time, direction) {
+			result = &Boundary{Time: adjacent, Source: event.source, EventIndex: event.eventIndex}
+		}
+	}
+	return result
+}
+
+// nearestBefore finds the latest event with the given action strictly before t
+func (c *CompiledSchedule) nearestBefore(t time.Time, action batch.EventType) *Boundary {
+	return c.nearest(t.Add(-time.Nanosecond), directionPrevious, action)
+}
@@ -0,0 +1,217 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// 2024-06-03 is a Monday
+func june(day, hour, minute int) time.Time {
+	return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+}
+
+func compileForTest(t *testing.T, spec batch.ControlledJobSpec, calendar *batch.CalendarSpec) *CompiledSchedule {
+	spec.Timezone = batch.TimezoneSpec{Name: "UTC"}
+	compiled, err := Compile(&batch.ControlledJob{Spec: spec}, calendar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compiled
+}
+
+// weekdaySchedule starts at 9am, with a duplicate start at 10am and a restart at midday, and stops at 5pm
+var weekdaySchedule = batch.ControlledJobSpec{
+	Events: []batch.EventSpec{
+		{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+		{Action: batch.EventTypeStart, CronSchedule: "0 10 * * MON-FRI"},
+		{Action: batch.EventTypeRestart, CronSchedule: "0 12 * * MON-FRI"},
+		{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+	},
+}
+
+func weekdayWindow(day int) RunWindow {
+	return RunWindow{
+		Start: Boundary{Time: june(day, 9, 0), Source: EventSourceEvent, EventIndex: 0},
+		Stop:  &Boundary{Time: june(day, 17, 0), Source: EventSourceEvent, EventIndex: 3},
+	}
+}
+
+func collectWindows(t *testing.T, it *WindowIterator, count int) []RunWindow {
+	var result []RunWindow
+	for i := 0; i < count; i++ {
+		window, err := it.Next()
+		assert.Nil(t, err, "should not return an error")
+		if window == nil {
+			break
+		}
+		result = append(result, *window)
+	}
+	return result
+}
+
+func Test_CompiledSchedule_WindowAt(t *testing.T) {
+	sut := compileForTest(t, weekdaySchedule, nil)
+
+	window, err := sut.WindowAt(june(3, 11, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, weekdayWindow(3), *window, "the duplicate start at 10am should not start a new window")
+
+	window, err = sut.WindowAt(june(3, 9, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, weekdayWindow(3), *window, "the window should include its start")
+
+	for _, notRunning := range []time.Time{june(3, 8, 59), june(3, 17, 0), june(8, 12, 0)} {
+		window, err = sut.WindowAt(notRunning)
+		assert.Nil(t, err)
+		assert.Nil(t, window, "should not be running at %s", notRunning)
+		isRunning, _ := sut.IsRunningAt(notRunning)
+		assert.False(t, isRunning)
+	}
+}
+
+func Test_CompiledSchedule_WindowsFrom(t *testing.T) {
+	sut := compileForTest(t, weekdaySchedule, nil)
+
+	assert.Equal(t, []RunWindow{weekdayWindow(3), weekdayWindow(4), weekdayWindow(5)}, collectWindows(t, sut.WindowsFrom(june(3, 11, 0)), 3),
+		"should start with the window containing the given time")
+	assert.Equal(t, []RunWindow{weekdayWindow(10), weekdayWindow(11)}, collectWindows(t, sut.WindowsFrom(june(8, 12, 0)), 2),
+		"should skip the weekend")
+}
+
+func Test_CompiledSchedule_WindowsBefore(t *testing.T) {
+	sut := compileForTest(t, weekdaySchedule, nil)
+
+	assert.Equal(t, []RunWindow{weekdayWindow(4), weekdayWindow(3)}, collectWindows(t, sut.WindowsBefore(june(4, 10, 30)), 2),
+		"should start with the window containing the given time")
+	assert.Equal(t, []RunWindow{weekdayWindow(7), weekdayWindow(6)}, collectWindows(t, sut.WindowsBefore(june(10, 8, 0)), 2),
+		"should skip the weekend")
+}
+
+func Test_CompiledSchedule_WindowSources(t *testing.T) {
+	runFor := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *", RunFor: &metav1.Duration{Duration: 2 * time.Hour}},
+		},
+	}, nil)
+
+	window, err := runFor.WindowAt(june(3, 10, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, &Boundary{Time: june(3, 11, 0), Source: EventSourceRunFor, EventIndex: 0}, window.Stop)
+
+	earlyClose := compileForTest(t, weekdaySchedule, &batch.CalendarSpec{EarlyCloses: []batch.EarlyCloseSpec{{Date: "2024-06-04", TimeOfDay: "12:30"}}})
+
+	window, err = earlyClose.WindowAt(june(4, 10, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, &Boundary{Time: june(4, 12, 30), Source: EventSourceEarlyClose, EventIndex: -1}, window.Stop)
+}
+
+func Test_CompiledSchedule_EventPrecedenceWindows(t *testing.T) {
+	// Each implied stop happens at the same instant as the next start
+	spec := batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *", RunFor: &metav1.Duration{Duration: 24 * time.Hour}},
+		},
+	}
+	window := func(day int) RunWindow {
+		return RunWindow{
+			Start: Boundary{Time: june(day, 9, 0), Source: EventSourceEvent, EventIndex: 0},
+			Stop:  &Boundary{Time: june(day+1, 9, 0), Source: EventSourceRunFor, EventIndex: 0},
+		}
+	}
+
+	spec.EventPrecedence = batch.StartEventPrecedence
+	startWins := compileForTest(t, spec, nil)
+	assert.Equal(t, []RunWindow{window(3), window(4), window(5)}, collectWindows(t, startWins.WindowsFrom(june(3, 9, 0)), 3),
+		"each window should start as the last one stops")
+	assert.Equal(t, []RunWindow{window(3), window(2)}, collectWindows(t, startWins.WindowsBefore(june(4, 8, 0)), 2))
+
+	spec.EventPrecedence = batch.StopEventPrecedence
+	stopWins := compileForTest(t, spec, nil)
+	assert.Empty(t, collectWindows(t, stopWins.WindowsFrom(june(3, 9, 0)), 3), "every start event should be cancelled out by a stop")
+	assert.Empty(t, collectWindows(t, stopWins.WindowsBefore(june(3, 9, 0)), 3), "every start event should be cancelled out by a stop")
+}
+
+func Test_CompiledSchedule_StartOnlyWindows(t *testing.T) {
+	sut := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{
+			{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"},
+			{Action: batch.EventTypeStart, CronSchedule: "0 15 * * *"},
+		},
+		ConcurrencyPolicy: batch.AllowConcurrent,
+	}, nil)
+	start := func(day, hour, eventIndex int) RunWindow {
+		return RunWindow{Start: Boundary{Time: june(day, hour, 0), Source: EventSourceEvent, EventIndex: eventIndex}}
+	}
+
+	assert.Equal(t, []RunWindow{start(3, 9, 0), start(3, 15, 1), start(4, 9, 0)}, collectWindows(t, sut.WindowsFrom(june(3, 12, 0)), 3),
+		"every start event should start a new window, which never stops")
+	assert.Equal(t, []RunWindow{start(3, 9, 0), start(2, 15, 1)}, collectWindows(t, sut.WindowsBefore(june(3, 12, 0)), 2))
+
+	notStartOnly := compileForTest(t, batch.ControlledJobSpec{
+		Events: []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"}},
+	}, nil)
+	_, err := notStartOnly.WindowAt(june(3, 12, 0))
+	assert.NotNil(t, err, "a schedule with only start events and no concurrencyPolicy should return an error")
+}
+
+func Test_CompiledSchedule_WindowsAgreeWithStateAt(t *testing.T) {
+	calendar := &batch.CalendarSpec{
+		Holidays:    []batch.ExclusionSpec{{Date: "2024-06-05"}},
+		EarlyCloses: []batch.EarlyCloseSpec{{Date: "2024-06-04", TimeOfDay: "12:30"}},
+	}
+	sut := compileForTest(t, weekdaySchedule, calendar)
+
+	for now := june(3, 0, 0); now.Before(june(10, 0, 0)); now = now.Add(15 * time.Minute) {
+		state, err := sut.StateAt(now)
+		assert.Nil(t, err)
+		window, err := sut.WindowAt(now)
+		assert.Nil(t, err)
+
+		assert.Equal(t, state.ShouldBeRunning(), window != nil, "at %s", now)
+		if window != nil {
+			assert.Equal(t, *state.StartOfCurrentRunPeriod(), window.Start.Time, "at %s", now)
+			assert.Equal(t, *state.NextEventTime(), minTime(*state.NextEventTime(), window.Stop.Time), "the window should not stop before the next event at %s", now)
+		}
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func Test_CompiledSchedule_TiedEventsWithoutPrecedence(t *testing.T) {
+	// On Wednesday the ControlledJob stops at the same instant as it starts, and there's no eventPrecedence
+	start := batch.EventSpec{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"}
+	stop := batch.EventSpec{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"}
+	tiedStop := batch.EventSpec{Action: batch.EventTypeStop, CronSchedule: "0 9 * * WED"}
+
+	for name, events := range map[string][]batch.EventSpec{
+		"start listed first": {start, stop, tiedStop},
+		"stop listed first":  {tiedStop, stop, start},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sut := compileForTest(t, batch.ControlledJobSpec{Events: events}, nil)
+
+			for now := june(3, 0, 0); now.Before(june(10, 0, 0)); now = now.Add(15 * time.Minute) {
+				state, err := sut.StateAt(now)
+				assert.Nil(t, err)
+				isRunning, err := sut.IsRunningAt(now)
+				assert.Nil(t, err)
+
+				assert.Equal(t, state.ShouldBeRunning(), isRunning, "at %s", now)
+			}
+
+			state, err := sut.StateAt(june(5, 9, 0))
+			assert.Nil(t, err)
+			assert.False(t, state.ShouldBeRunning(), "the stop event should win the tie")
+		})
+	}
+}
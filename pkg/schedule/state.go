@@ -46,13 +46,28 @@ type State interface {
 	//
 	// This could return nil if there are no previous start events (for example, there are only stop events defined)
 	StartOfCurrentRunPeriod() *RunPeriodStartTime
+
+	// LastRestartTime is the most recent restart event inside the current run period. Any Job that was started
+	// before this time should be replaced by a fresh Job.
+	//
+	// Restart events only have an effect while we're inside a run period, so this will return nil if
+	// ShouldBeRunning() is false, or if there has been no restart event since StartOfCurrentRunPeriod()
+	LastRestartTime() *time.Time
+
+	// IsStartOnly returns true if the schedule has no stop events, and has a ConcurrencyPolicy. In that case each
+	// start event begins a new run period, and StartOfCurrentRunPeriod() is simply the most recent start event.
+	// There is never a LastStopTime(): instead, Jobs from earlier run periods are dealt with according to the
+	// ConcurrencyPolicy
+	IsStartOnly() bool
 }
 
 type state struct {
 	lastStopTime            *time.Time
 	startOfCurrentRunPeriod *RunPeriodStartTime
+	lastRestartTime         *time.Time
 	previousEvent           *ScheduledEvent
 	nextEvent               *ScheduledEvent
+	isStartOnly             bool
 }
 
 // ScheduledEvent represents an instance of an event
@@ -65,43 +80,34 @@ type locationWithOffset struct {
 	*time.Location
 	// Additional offset from the specified timezone
 	OffsetSeconds int32
+	// DSTPolicy says when wall clock times which are skipped or repeated by DST changes happen
+	DSTPolicy batch.DSTPolicySpec
 }
 
-// StateFor works out the closest previous and next events to the given time in the given ControlledJob's schedule
-func StateFor(controlledJob *batch.ControlledJob, now time.Time) (State, error) {
-	location, err := time.LoadLocation(controlledJob.Spec.Timezone.Name)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to resolve timezone named %s", controlledJob.Spec.Timezone.Name)
-	}
-	locationWithOffset := locationWithOffset{
-		location,
-		controlledJob.Spec.Timezone.OffsetSeconds,
-	}
-	previousEvent, err := findNearestEvent(controlledJob.Spec.Events, now, locationWithOffset, directionPrevious, func(es batch.EventSpec) bool { return true })
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to find previous event in the schedule")
-	}
-	nextEvent, err := findNearestEvent(controlledJob.Spec.Events, now, locationWithOffset, directionNext, func(es batch.EventSpec) bool { return true })
+// locationFor resolves a TimezoneSpec
+func locationFor(timezone batch.TimezoneSpec) (locationWithOffset, error) {
+	location, err := time.LoadLocation(timezone.Name)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to find next event in the schedule")
+		return locationWithOffset{}, errors.Wrapf(err, "failed to resolve timezone named %s", timezone.Name)
 	}
-
-	lastStopTime, err := findMostRecentStopTime(controlledJob.Spec.Events, locationWithOffset, now)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to find most recent stop time")
+	result := locationWithOffset{Location: location, OffsetSeconds: timezone.OffsetSeconds}
+	if timezone.DSTPolicy != nil {
+		result.DSTPolicy = *timezone.DSTPolicy
 	}
+	return result, nil
+}
 
-	startOfCurrentRunPeriod, err := findStartOfCurrentRunPeriod(controlledJob.Spec.Events, locationWithOffset, now)
+// StateFor works out the closest previous and next events to the given time in the given ControlledJob's schedule
+// calendarSpec is the spec of the Calendar or ClusterCalendar referenced by the ControlledJob, or nil if it doesn't reference one
+//
+// This builds the ControlledJob's schedule from scratch. To work out the State of the same ControlledJob repeatedly,
+// use a Cache
+func StateFor(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, now time.Time) (State, error) {
+	compiled, err := Compile(controlledJob, calendarSpec)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to find start of current run period")
+		return nil, err
 	}
-
-	return &state{
-		lastStopTime:            lastStopTime,
-		startOfCurrentRunPeriod: startOfCurrentRunPeriod,
-		previousEvent:           previousEvent,
-		nextEvent:               nextEvent,
-	}, nil
+	return compiled.StateAt(now)
 }
 
 func (s *state) NextEventTime() *time.Time {
@@ -123,64 +129,20 @@ func (s *state) StartOfCurrentRunPeriod() *RunPeriodStartTime {
 	return s.startOfCurrentRunPeriod
 }
 
-func findMostRecentStopTime(events []batch.EventSpec, locationWithOffset locationWithOffset, now time.Time) (*RunPeriodStartTime, error) {
-	lastStopEvent, err := findNearestEvent(events, now, locationWithOffset, directionPrevious,
-		func(es batch.EventSpec) bool { return es.Action == batch.EventTypeStop },
-	)
-	if err != nil {
-		return nil, err
-	}
-	if lastStopEvent == nil {
-		return nil, nil
-	}
-	return &lastStopEvent.ScheduledTimeUTC, nil
+func (s *state) LastRestartTime() *time.Time {
+	return s.lastRestartTime
 }
 
-func findStartOfCurrentRunPeriod(events []batch.EventSpec, locationWithOffset locationWithOffset, now time.Time) (*RunPeriodStartTime, error) {
-	// To find the start of the current run period we go back until we find a stop event (which
-	// defines the end of the previous period) and then go forward from there until we find a start event
-
-	lastStopTime, err := findMostRecentStopTime(events, locationWithOffset, now)
-	if err != nil {
-		return nil, err
-	}
-	if lastStopTime == nil {
-		// No recent stop event.
-		hasAnyStartEvent := false
-		for _, event := range events {
-			if event.Action == batch.EventTypeStart {
-				hasAnyStartEvent = true
-				break
-			}
-		}
+func (s *state) IsStartOnly() bool {
+	return s.isStartOnly
+}
 
-		if !hasAnyStartEvent {
-			// No start events, so just return nil
-			return nil, nil
+// hasNoStopEvents returns true if there are neither stop events nor start events which imply a stop
+func hasNoStopEvents(events []batch.EventSpec) bool {
+	for _, event := range events {
+		if event.Action == batch.EventTypeStop || (event.Action == batch.EventTypeStart && event.RunFor != nil) {
+			return false
 		}
-
-		// If there _are_ some start events though, return an error because we can't be sure what to do here for the best.
-		// Why?
-		// Because a schedule of just start events could be the user trying to:
-		// - start a new job at each start event, even if previous runs are still going (like CronJob semantics)
-		// - make sure the job is still running
-		// - explicitly restart the job each start event
-		// - a mistake - they forgot to add stop events
-		// Because we can't tell the difference, for now let's fail-fast and fail-safe by not running anything
-		// until we get a real user requirement for start-only schedules
-		return nil, errors.New("No previous stop events found, only start events. We don't currently support start-only schedules (as it's not clear what the semantics should be)")
-	}
-
-	nextStartEvent, err := findNearestEvent(events, *lastStopTime, locationWithOffset, directionNext,
-		func(es batch.EventSpec) bool { return es.Action == batch.EventTypeStart },
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if nextStartEvent == nil {
-		return nil, nil
 	}
-
-	return &nextStartEvent.ScheduledTimeUTC, nil
+	return true
 }
@@ -6,6 +6,7 @@ import (
 
 	batch "github.com/G-Research/controlled-job/api/v1"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -46,7 +47,7 @@ var (
 	}
 )
 
-func Test_findStartOfCurrentRunPeriod(t *testing.T) {
+func Test_StateAt_StartOfCurrentRunPeriod(t *testing.T) {
 
 	// TODO: Test things like:
 	// No stop events
@@ -194,7 +195,12 @@ func Test_findStartOfCurrentRunPeriod(t *testing.T) {
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			location, _ := time.LoadLocation(tc.timezone.Name)
-			actual, _ := findStartOfCurrentRunPeriod(tc.events, locationWithOffset{location, tc.timezone.OffsetSeconds}, tc.now)
+			compiled, err := compileSchedule(tc.events, calendar{location: locationWithOffset{Location: location, OffsetSeconds: tc.timezone.OffsetSeconds}}, false)
+			assert.Nil(t, err, "should not return an error")
+			var actual *RunPeriodStartTime
+			if state, err := compiled.StateAt(tc.now); err == nil {
+				actual = state.StartOfCurrentRunPeriod()
+			}
 
 			if actual == nil {
 				assert.Nil(t, tc.expected)
@@ -227,7 +233,7 @@ func Test_StateFor(t *testing.T) {
 	// 9am UTC = 4am New_York
 	now := hours[9]
 
-	sut, err := StateFor(controlledJob, now)
+	sut, err := StateFor(controlledJob, nil, now)
 
 	assert.Nil(t, err, "Should not return an error")
 
@@ -238,3 +244,451 @@ func Test_StateFor(t *testing.T) {
 	assert.True(t, sut.ShouldBeRunning(), "Expect ShouldBeRunning to be true")
 	assert.Equal(t, hours[8], *actualStartOfRunPeriod, "%v (expected) != %v (actual)", hours[8], sut.StartOfCurrentRunPeriod())
 }
+
+func Test_StateFor_LastRestartTime(t *testing.T) {
+	// start at 1am, restart at 3am and 11am, stop at 5am
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 1 * * * ",
+		},
+		{
+			Action:       batch.EventTypeRestart,
+			CronSchedule: "0 3 * * * ",
+		},
+		{
+			Action:       batch.EventTypeRestart,
+			CronSchedule: "0 11 * * * ",
+		},
+		{
+			Action:       batch.EventTypeStop,
+			CronSchedule: "0 5 * * * ",
+		},
+	}
+
+	testCases := map[string]struct {
+		now                     time.Time
+		expectedShouldBeRunning bool
+		expectedLastRestartTime *time.Time
+		expectedNextEventTime   time.Time
+	}{
+		"inside run period, before restart": {
+			now:                     hours[2],
+			expectedShouldBeRunning: true,
+			expectedLastRestartTime: nil,
+			expectedNextEventTime:   hours[3],
+		},
+		"inside run period, exactly at restart": {
+			now:                     hours[3],
+			expectedShouldBeRunning: true,
+			expectedLastRestartTime: &hours[3],
+			expectedNextEventTime:   hours[5],
+		},
+		"inside run period, after restart": {
+			now:                     hours[4],
+			expectedShouldBeRunning: true,
+			expectedLastRestartTime: &hours[3],
+			expectedNextEventTime:   hours[5],
+		},
+		"outside run period, after a restart event": {
+			// The 11am restart must not make us think we should be running
+			now:                     hours[12],
+			expectedShouldBeRunning: false,
+			expectedLastRestartTime: nil,
+			expectedNextEventTime:   hours[1].Add(24 * time.Hour),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   events,
+				},
+			}
+
+			sut, err := StateFor(controlledJob, nil, tc.now)
+
+			assert.Nil(t, err, "Should not return an error")
+			assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning())
+			assert.Equal(t, tc.expectedLastRestartTime, sut.LastRestartTime())
+			assert.Equal(t, tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_StartOnly(t *testing.T) {
+	// start every 3 hours, with no stop events
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 */3 * * * ",
+		},
+	}
+
+	testCases := map[string]struct {
+		concurrencyPolicy               batch.ConcurrencyPolicy
+		now                             time.Time
+		expectedError                   bool
+		expectedIsStartOnly             bool
+		expectedStartOfCurrentRunPeriod *time.Time
+		expectedNextEventTime           time.Time
+	}{
+		"no concurrency policy": {
+			now:           hours[4],
+			expectedError: true,
+		},
+		"exactly at a start event": {
+			concurrencyPolicy:               batch.AllowConcurrent,
+			now:                             hours[3],
+			expectedIsStartOnly:             true,
+			expectedStartOfCurrentRunPeriod: &hours[3],
+			expectedNextEventTime:           hours[6],
+		},
+		"between start events": {
+			concurrencyPolicy:               batch.ForbidConcurrent,
+			now:                             hours[5],
+			expectedIsStartOnly:             true,
+			expectedStartOfCurrentRunPeriod: &hours[3],
+			expectedNextEventTime:           hours[6],
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone:          batch.TimezoneSpec{Name: "UTC"},
+					Events:            events,
+					ConcurrencyPolicy: tc.concurrencyPolicy,
+				},
+			}
+
+			sut, err := StateFor(controlledJob, nil, tc.now)
+
+			if tc.expectedError {
+				assert.NotNil(t, err, "Should return an error")
+				return
+			}
+			assert.Nil(t, err, "Should not return an error")
+			assert.Equal(t, tc.expectedIsStartOnly, sut.IsStartOnly())
+			assert.True(t, sut.ShouldBeRunning())
+			assert.Nil(t, sut.LastStopTime())
+			assert.Equal(t, tc.expectedStartOfCurrentRunPeriod, sut.StartOfCurrentRunPeriod())
+			assert.Equal(t, tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_Exclusions(t *testing.T) {
+	// start at 1am, stop at 5am every day, except on the 4th February (the test date)
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 1 * * * ",
+		},
+		{
+			Action:       batch.EventTypeStop,
+			CronSchedule: "0 5 * * * ",
+		},
+	}
+	yesterday := func(t time.Time) time.Time { return t.AddDate(0, 0, -1) }
+	tomorrow := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+
+	testCases := map[string]struct {
+		exclusions                      []batch.ExclusionSpec
+		now                             time.Time
+		expectedShouldBeRunning         bool
+		expectedStartOfCurrentRunPeriod time.Time
+		expectedLastStopTime            time.Time
+		expectedNextEventTime           time.Time
+	}{
+		"no exclusions": {
+			now:                             hours[2],
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: hours[1],
+			expectedLastStopTime:            yesterday(hours[5]),
+			expectedNextEventTime:           hours[5],
+		},
+		"start event on an excluded date is skipped": {
+			exclusions:                      []batch.ExclusionSpec{{Date: "2022-02-04"}},
+			now:                             hours[2],
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: tomorrow(hours[1]),
+			expectedLastStopTime:            yesterday(hours[5]),
+			expectedNextEventTime:           hours[5],
+		},
+		"stop event on an excluded date still happens": {
+			exclusions:                      []batch.ExclusionSpec{{Date: "2022-02-04"}},
+			now:                             hours[6],
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: tomorrow(hours[1]),
+			expectedLastStopTime:            hours[5],
+			expectedNextEventTime:           tomorrow(hours[1]),
+		},
+		"next run period skips a range of excluded dates": {
+			exclusions:                      []batch.ExclusionSpec{{Date: "2022-02-05", EndDate: "2022-02-06"}},
+			now:                             hours[6],
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: tomorrow(tomorrow(tomorrow(hours[1]))),
+			expectedLastStopTime:            hours[5],
+			expectedNextEventTime:           tomorrow(hours[5]),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone:   batch.TimezoneSpec{Name: "UTC"},
+					Events:     events,
+					Exclusions: tc.exclusions,
+				},
+			}
+
+			sut, err := StateFor(controlledJob, nil, tc.now)
+
+			assert.Nil(t, err, "Should not return an error")
+			assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning())
+			assert.Equal(t, tc.expectedStartOfCurrentRunPeriod, *sut.StartOfCurrentRunPeriod())
+			assert.Equal(t, tc.expectedLastStopTime, *sut.LastStopTime())
+			assert.Equal(t, tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_RunFor(t *testing.T) {
+	// start at 22:00 every day in New York, and run for 10 hours. The clocks go forward at 02:00 on 2022-03-13
+	events := []batch.EventSpec{
+		{
+			Action:       batch.EventTypeStart,
+			CronSchedule: "0 22 * * *",
+			RunFor:       &metav1.Duration{Duration: 10 * time.Hour},
+		},
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, time.March, day, hour, 0, 0, 0, nyLoc)
+	}
+
+	testCases := map[string]struct {
+		exclusions                      []batch.ExclusionSpec
+		now                             time.Time
+		expectedShouldBeRunning         bool
+		expectedStartOfCurrentRunPeriod time.Time
+		expectedLastStopTime            time.Time
+		expectedNextEventTime           time.Time
+	}{
+		"overnight, before midnight": {
+			now:                             at(10, 23),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(10, 22),
+			expectedLastStopTime:            at(10, 8),
+			expectedNextEventTime:           at(11, 8),
+		},
+		"overnight, after midnight": {
+			now:                             at(11, 3),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(10, 22),
+			expectedLastStopTime:            at(10, 8),
+			expectedNextEventTime:           at(11, 8),
+		},
+		"after the implied stop": {
+			now:                             at(11, 12),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(11, 22),
+			expectedLastStopTime:            at(11, 8),
+			expectedNextEventTime:           at(11, 22),
+		},
+		"the implied stop is an elapsed time, so moves on the clock when the clocks change": {
+			now:                             at(13, 3),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(12, 22),
+			expectedLastStopTime:            at(12, 8),
+			expectedNextEventTime:           at(13, 9),
+		},
+		"the implied stop still happens after an excluded start": {
+			exclusions:                      []batch.ExclusionSpec{{Date: "2022-03-10"}},
+			now:                             at(11, 3),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(11, 22),
+			expectedLastStopTime:            at(10, 8),
+			expectedNextEventTime:           at(11, 8),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone:          batch.TimezoneSpec{Name: "America/New_York"},
+					Events:            events,
+					Exclusions:        tc.exclusions,
+					ConcurrencyPolicy: batch.ForbidConcurrent,
+				},
+			}
+
+			sut, err := StateFor(controlledJob, nil, tc.now)
+
+			assert.Nil(t, err, "Should not return an error")
+			assert.False(t, sut.IsStartOnly(), "the implied stops mean this isn't a start-only schedule")
+			assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning())
+			assert.True(t, tc.expectedStartOfCurrentRunPeriod.Equal(*sut.StartOfCurrentRunPeriod()), "%s (expected) != %s (actual)", tc.expectedStartOfCurrentRunPeriod, *sut.StartOfCurrentRunPeriod())
+			assert.True(t, tc.expectedLastStopTime.Equal(*sut.LastStopTime()), "%s (expected) != %s (actual)", tc.expectedLastStopTime, *sut.LastStopTime())
+			assert.True(t, tc.expectedNextEventTime.Equal(*sut.NextEventTime()), "%s (expected) != %s (actual)", tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_InvalidRunFor(t *testing.T) {
+	now := time.Date(2022, time.March, 10, 12, 0, 0, 0, time.UTC)
+	for name, event := range map[string]batch.EventSpec{
+		"on a stop event":   {Action: batch.EventTypeStop, CronSchedule: "0 8 * * *", RunFor: &metav1.Duration{Duration: time.Hour}},
+		"negative duration": {Action: batch.EventTypeStart, CronSchedule: "0 8 * * *", RunFor: &metav1.Duration{Duration: -time.Hour}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *"}, event},
+				},
+			}
+
+			_, err := StateFor(controlledJob, nil, now)
+			assert.NotNil(t, err, "should return an error")
+		})
+	}
+}
+
+func Test_StateFor_OneOffEvents(t *testing.T) {
+	// start at 09:00 and stop at 17:00 on weekdays in London, plus an extra session on Saturday 2022-12-24, which is
+	// also excluded
+	events := []batch.EventSpec{
+		{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+		{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+		{Action: batch.EventTypeStart, At: "2022-12-24T06:00"},
+		{Action: batch.EventTypeStop, At: "2022-12-24T10:30:00Z"},
+	}
+	londonLoc, _ := time.LoadLocation("Europe/London")
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, time.December, day, hour, minute, 0, 0, londonLoc)
+	}
+
+	testCases := map[string]struct {
+		now                             time.Time
+		expectedShouldBeRunning         bool
+		expectedStartOfCurrentRunPeriod time.Time
+		expectedLastStopTime            time.Time
+		expectedNextEventTime           time.Time
+	}{
+		"before the one-off events": {
+			now:                             at(23, 18, 0),
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(24, 6, 0),
+			expectedLastStopTime:            at(23, 17, 0),
+			expectedNextEventTime:           at(24, 6, 0),
+		},
+		"during the one-off session, on an excluded date": {
+			now:                             at(24, 8, 0),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(24, 6, 0),
+			expectedLastStopTime:            at(23, 17, 0),
+			expectedNextEventTime:           at(24, 10, 30),
+		},
+		"after the one-off events have expired": {
+			now:                             at(27, 10, 0),
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(27, 9, 0),
+			expectedLastStopTime:            at(26, 17, 0),
+			expectedNextEventTime:           at(27, 17, 0),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone:   batch.TimezoneSpec{Name: "Europe/London"},
+					Events:     events,
+					Exclusions: []batch.ExclusionSpec{{Date: "2022-12-24"}},
+				},
+			}
+
+			sut, err := StateFor(controlledJob, nil, tc.now)
+
+			assert.Nil(t, err, "Should not return an error")
+			assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning())
+			assert.True(t, tc.expectedStartOfCurrentRunPeriod.Equal(*sut.StartOfCurrentRunPeriod()), "%s (expected) != %s (actual)", tc.expectedStartOfCurrentRunPeriod, *sut.StartOfCurrentRunPeriod())
+			assert.True(t, tc.expectedLastStopTime.Equal(*sut.LastStopTime()), "%s (expected) != %s (actual)", tc.expectedLastStopTime, *sut.LastStopTime())
+			assert.True(t, tc.expectedNextEventTime.Equal(*sut.NextEventTime()), "%s (expected) != %s (actual)", tc.expectedNextEventTime, *sut.NextEventTime())
+		})
+	}
+}
+
+func Test_StateFor_InvalidOneOffEvents(t *testing.T) {
+	now := time.Date(2022, time.March, 10, 12, 0, 0, 0, time.UTC)
+	for name, event := range map[string]batch.EventSpec{
+		"invalid time":         {Action: batch.EventTypeStart, At: "24/12/2022 06:00"},
+		"also a session event": {Action: batch.EventTypeStart, At: "2022-12-24T06:00", Session: &batch.SessionEventSpec{Exchange: "XLON", Anchor: batch.SessionAnchorOpen}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events:   []batch.EventSpec{{Action: batch.EventTypeStop, CronSchedule: "0 17 * * *"}, event},
+				},
+			}
+
+			_, err := StateFor(controlledJob, nil, now)
+			assert.NotNil(t, err, "should return an error")
+		})
+	}
+}
+
+func Test_StateFor_EventPrecedence(t *testing.T) {
+	// Start at 09:00 every day and run for 24 hours, so each implied stop happens at the same instant as the next start
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, time.December, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	testCases := map[string]struct {
+		precedence                      batch.EventPrecedence
+		expectedShouldBeRunning         bool
+		expectedStartOfCurrentRunPeriod time.Time
+	}{
+		"start events take precedence": {
+			precedence:                      batch.StartEventPrecedence,
+			expectedShouldBeRunning:         true,
+			expectedStartOfCurrentRunPeriod: at(13, 9),
+		},
+		"stop events take precedence": {
+			precedence:                      batch.StopEventPrecedence,
+			expectedShouldBeRunning:         false,
+			expectedStartOfCurrentRunPeriod: at(14, 9),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{
+				Spec: batch.ControlledJobSpec{
+					Timezone: batch.TimezoneSpec{Name: "UTC"},
+					Events: []batch.EventSpec{
+						{Action: batch.EventTypeStart, CronSchedule: "0 9 * * *", RunFor: &metav1.Duration{Duration: 24 * time.Hour}},
+					},
+					EventPrecedence: tc.precedence,
+				},
+			}
+
+			for _, now := range []time.Time{at(13, 9), at(13, 12)} {
+				sut, err := StateFor(controlledJob, nil, now)
+
+				assert.Nil(t, err, "Should not return an error")
+				assert.Equal(t, tc.expectedShouldBeRunning, sut.ShouldBeRunning(), "at %s", now)
+				assert.Equal(t, tc.expectedStartOfCurrentRunPeriod, *sut.StartOfCurrentRunPeriod(), "at %s", now)
+				assert.Equal(t, at(13, 9), *sut.LastStopTime(), "at %s", now)
+				assert.Equal(t, at(14, 9), *sut.NextEventTime(), "at %s", now)
+			}
+		})
+	}
+}
@@ -21,6 +21,11 @@ func mapEventToSpecSchedule(event batch.EventSpec, location *time.Location) (*cr
 	if err != nil {
 		return nil, err
 	}
+	return parseCronSpec(cronSpec, location)
+}
+
+// parseCronSpec parses a cron spec string into a cron.SpecSchedule in the given location
+func parseCronSpec(cronSpec string, location *time.Location) (*cron.SpecSchedule, error) {
 	//  2. Get the cron library to parse it
 	schedule, err := cron.ParseStandard(cronSpec)
 	if err != nil {
@@ -31,7 +36,7 @@ func mapEventToSpecSchedule(event batch.EventSpec, location *time.Location) (*cr
 	// in cronPrev()
 	specSchedule, ok := schedule.(*cron.SpecSchedule)
 	if !ok {
-		return nil, errors.Wrap(err, "Expected instance of SpecSchedule")
+		return nil, errors.Errorf("Expected instance of SpecSchedule for cron schedule %s", cronSpec)
 	}
 	// Ignore any timezone the user has specified in the cron schedule itself (using TZ=foo syntax)
 	// and override it using the location specified in the wider ControllerJob spec
@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"github.com/pkg/errors"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+// ValidateTimezone checks that the given timezone exists
+func ValidateTimezone(timezone batch.TimezoneSpec) error {
+	_, err := locationFor(timezone)
+	return err
+}
+
+// ValidateEvents checks that the schedule of each event can be calculated, returning an error for each event whose
+// schedule can't be, or nil for each event which is fine. timezone is the ControlledJob's timezone, which the events use
+// unless they have their own. The events are checked together so that events relative to other events can be checked
+func ValidateEvents(events []batch.EventSpec, timezone batch.TimezoneSpec) ([]error, error) {
+	location, err := locationFor(timezone)
+	if err != nil {
+		return nil, err
+	}
+	result := checkEventReferences(events)
+	c := calendar{location: location, namedEvents: map[string]batch.EventSpec{}}
+	for i, event := range events {
+		if event.Name != "" && result[i] == nil {
+			c.namedEvents[event.Name] = event
+		}
+	}
+	for i, event := range events {
+		if result[i] != nil {
+			continue
+		}
+		result[i] = validateEvent(event, c)
+	}
+	return result, nil
+}
+
+func validateEvent(event batch.EventSpec, c calendar) error {
+	switch event.Action {
+	case batch.EventTypeStart, batch.EventTypeStop, batch.EventTypeRestart:
+	default:
+		return errors.Errorf("action must be one of %s, %s or %s, not %q", batch.EventTypeStart, batch.EventTypeStop, batch.EventTypeRestart, event.Action)
+	}
+	_, err := c.eventScheduleFor(event)
+	return err
+}
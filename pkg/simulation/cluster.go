@@ -0,0 +1,203 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	kbatch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/clientadapter"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+)
+
+// cluster is an in-memory ControlledJobClient holding a single ControlledJob and its Jobs. It stands in for both the
+// API server and the Job controller: Pods start as soon as a Job is unsuspended, and terminate as soon as it's
+// suspended or deleted. Every change the reconciler makes is recorded as an Entry
+type cluster struct {
+	now           time.Time
+	controlledJob *batch.ControlledJob
+	calendar      *batch.CalendarSpec
+	jobs          map[string]*kbatch.Job
+	changes       []Entry
+}
+
+var _ clientadapter.ControlledJobClient = &cluster{}
+
+func newCluster(controlledJob *batch.ControlledJob, calendar *batch.CalendarSpec) *cluster {
+	return &cluster{
+		controlledJob: controlledJob.DeepCopy(),
+		calendar:      calendar,
+		jobs:          map[string]*kbatch.Job{},
+	}
+}
+
+func (c *cluster) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: c.controlledJob.Namespace, Name: c.controlledJob.Name}
+}
+
+func (c *cluster) record(entryType EntryType, jobName, format string, args ...interface{}) {
+	c.changes = append(c.changes, Entry{Time: c.now, Type: entryType, JobName: jobName, Message: fmt.Sprintf(format, args...)})
+}
+
+// takeChanges returns the changes recorded since it was last called
+func (c *cluster) takeChanges() []Entry {
+	changes := c.changes
+	c.changes = nil
+	return changes
+}
+
+func (c *cluster) GetControlledJob(ctx context.Context, namespacedName types.NamespacedName) (*batch.ControlledJob, bool, error) {
+	if namespacedName != c.key() {
+		return nil, false, nil
+	}
+	return c.controlledJob.DeepCopy(), true, nil
+}
+
+func (c *cluster) UpdateControlledJob(ctx context.Context, controlledJob *batch.ControlledJob) error {
+	c.controlledJob.Spec = *controlledJob.Spec.DeepCopy()
+	c.controlledJob.Generation++
+	return nil
+}
+
+func (c *cluster) UpdateStatus(ctx context.Context, controlledJob *batch.ControlledJob) error {
+	c.controlledJob.Status = *controlledJob.Status.DeepCopy()
+	return nil
+}
+
+func (c *cluster) GetCalendar(ctx context.Context, namespace string, ref batch.CalendarReference) (*batch.CalendarSpec, error) {
+	if c.calendar == nil {
+		return nil, errors.Errorf("the ControlledJob refers to %s %s, but no calendar was given", ref.Kind, ref.Name)
+	}
+	return c.calendar, nil
+}
+
+func (c *cluster) ListJobsForControlledJob(ctx context.Context, namespacedName types.NamespacedName) (kbatch.JobList, error) {
+	var result kbatch.JobList
+	if namespacedName != c.key() {
+		return result, nil
+	}
+	for _, job := range c.jobs {
+		result.Items = append(result.Items, *job.DeepCopy())
+	}
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Name < result.Items[j].Name
+	})
+	return result, nil
+}
+
+func (c *cluster) CreateJob(ctx context.Context, job *kbatch.Job) error {
+	if _, exists := c.jobs[job.Name]; exists {
+		return errors.Errorf("job %s already exists", job.Name)
+	}
+	created := job.DeepCopy()
+	created.CreationTimestamp = metav1.NewTime(c.now)
+	c.jobs[job.Name] = created
+	if metadata.IsJobSuspended(created) {
+		c.record(EntryJobCreated, job.Name, "Created suspended job")
+	} else {
+		c.record(EntryJobCreated, job.Name, "Created job")
+	}
+	return nil
+}
+
+func (c *cluster) SuspendJob(ctx context.Context, job *kbatch.Job) error {
+	suspend := true
+	job.Spec.Suspend = &suspend
+	if err := c.update(job); err != nil {
+		return err
+	}
+	c.record(EntryJobSuspended, job.Name, "Suspended job")
+	return nil
+}
+
+func (c *cluster) UnsuspendJob(ctx context.Context, job *kbatch.Job) error {
+	suspend := false
+	job.Spec.Suspend = &suspend
+	if err := c.update(job); err != nil {
+		return err
+	}
+	c.record(EntryJobUnsuspended, job.Name, "Unsuspended job")
+	return nil
+}
+
+func (c *cluster) DeleteJob(ctx context.Context, job *kbatch.Job, propagation metav1.DeletionPropagation) error {
+	if _, exists := c.jobs[job.Name]; !exists {
+		return nil
+	}
+	delete(c.jobs, job.Name)
+	c.record(EntryJobDeleted, job.Name, "Deleted job")
+	return nil
+}
+
+// update stores the given Job, keeping the status from the Job controller
+func (c *cluster) update(job *kbatch.Job) error {
+	existing, exists := c.jobs[job.Name]
+	if !exists {
+		return errors.Errorf("job %s not found", job.Name)
+	}
+	updated := job.DeepCopy()
+	updated.Status = existing.Status
+	c.jobs[job.Name] = updated
+	return nil
+}
+
+// runPods brings the Jobs' Pods up to date, as the Job controller would: a Job which isn't suspended or finished has
+// one ready Pod, and any other Job has none
+func (c *cluster) runPods() {
+	for _, job := range c.jobs {
+		var active int32
+		if !metadata.IsJobSuspended(job) && !metadata.IsJobCompleted(job) {
+			active = 1
+			if job.Status.StartTime == nil {
+				startTime := metav1.NewTime(c.now)
+				job.Status.StartTime = &startTime
+			}
+		}
+		job.Status.Active = active
+		job.Status.Ready = &active
+	}
+}
+
+// failJob marks the Job as failed, as the Job controller would once its Pods have failed too many times
+func (c *cluster) failJob(job *kbatch.Job) {
+	failedAt := metav1.NewTime(c.now)
+	stored := c.jobs[job.Name]
+	stored.Status.Conditions = append(stored.Status.Conditions, kbatch.JobCondition{
+		Type:               kbatch.JobFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             "BackoffLimitExceeded",
+		Message:            "Injected failure",
+		LastProbeTime:      failedAt,
+		LastTransitionTime: failedAt,
+	})
+	stored.Status.Failed++
+	c.runPods()
+}
+
+// eventRecorder records the warning events the reconciler raises. Normal events only repeat the changes the cluster
+// records itself
+type eventRecorder struct {
+	cluster *cluster
+}
+
+func (r eventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if eventtype == corev1.EventTypeWarning {
+		r.cluster.record(EntryWarning, "", "%s: %s", reason, message)
+	}
+}
+
+func (r eventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r eventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
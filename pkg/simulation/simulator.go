@@ -0,0 +1,246 @@
+// Package simulation runs the reconciler over a period of time against an in-memory cluster, to show what it would do
+// with a ControlledJob without deploying it
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	kbatch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/actions"
+	"github.com/G-Research/controlled-job/pkg/events"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/reconciliation"
+)
+
+const (
+	// reactionDelay is how long after a change to one of its Jobs the ControlledJob is reconciled again. The operator
+	// watches the Jobs it owns, so in a real cluster this is usually well under a second
+	reactionDelay = time.Second
+	// errorRetryDelay is how long after a reconcile fails with a retryable error it is tried again. The operator
+	// backs off exponentially instead, starting from a few milliseconds
+	errorRetryDelay = time.Minute
+	// maxReconciles stops a reconciler which never settles from running the simulation forever
+	maxReconciles = 100000
+)
+
+// EntryType is the kind of thing that happened in the simulation
+type EntryType string
+
+const (
+	EntryJobCreated     EntryType = "JobCreated"
+	EntryJobSuspended   EntryType = "JobSuspended"
+	EntryJobUnsuspended EntryType = "JobUnsuspended"
+	EntryJobDeleted     EntryType = "JobDeleted"
+	// EntryConditionChanged is a change in the status of one of the ControlledJob's conditions
+	EntryConditionChanged EntryType = "ConditionChanged"
+	// EntryWarning is a warning event raised by the reconciler, for example because it failed
+	EntryWarning EntryType = "Warning"
+	// EntryInjected is an injected failure
+	EntryInjected EntryType = "Injected"
+)
+
+// Entry is something that happened in the simulation
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Type    EntryType `json:"type"`
+	JobName string    `json:"jobName,omitempty"`
+	Message string    `json:"message"`
+}
+
+// FailureType is a failure which can be injected into the simulation
+type FailureType string
+
+const (
+	// JobFails marks the running Job as failed
+	JobFails FailureType = "JobFails"
+	// JobDeletedByUser deletes the running Job, as if a user had deleted it
+	JobDeletedByUser FailureType = "JobDeletedByUser"
+)
+
+// Failure is a failure to inject at a particular time. It affects the newest running Job at that time, if any
+type Failure struct {
+	At   time.Time
+	Type FailureType
+}
+
+// Options configure a simulation
+type Options struct {
+	// From and To are the period to simulate. The ControlledJob is first reconciled at From, with no Jobs
+	From time.Time
+	To   time.Time
+	// Calendar is the spec of the Calendar or ClusterCalendar the ControlledJob refers to, if any
+	Calendar *batch.CalendarSpec
+	// Failures to inject
+	Failures []Failure
+}
+
+// Result is the outcome of a simulation
+type Result struct {
+	// Timeline is everything that happened, in order
+	Timeline []Entry `json:"timeline"`
+	// Reconciles is how many times the ControlledJob was reconciled
+	Reconciles int `json:"reconciles"`
+	// Jobs are the Jobs left at the end of the simulation
+	Jobs []kbatch.Job `json:"jobs"`
+	// Status is the status of the ControlledJob at the end of the simulation
+	Status batch.ControlledJobStatus `json:"status"`
+}
+
+// Run simulates the operator reconciling the ControlledJob from options.From until options.To. It's reconciled
+// whenever the real operator would reconcile it: when the reconciler asks to be requeued, when one of its Jobs changes
+// and when a failure is injected.
+//
+// Run sets events.NowFunc for the duration of the simulation, so it mustn't be called concurrently with anything
+// else which records events
+func Run(ctx context.Context, controlledJob *batch.ControlledJob, options Options) (*Result, error) {
+	if !options.To.After(options.From) {
+		return nil, errors.Errorf("the end of the simulation, %s, must be after its start, %s", options.To, options.From)
+	}
+	c := newCluster(controlledJob, options.Calendar)
+	eventHandler := events.NewHandler(eventRecorder{cluster: c})
+
+	previousNowFunc := events.NowFunc
+	events.NowFunc = func() *time.Time {
+		now := c.now
+		return &now
+	}
+	defer func() { events.NowFunc = previousNowFunc }()
+
+	var failures []Failure
+	for _, failure := range options.Failures {
+		if !failure.At.Before(options.From) && !failure.At.After(options.To) {
+			failures = append(failures, failure)
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].At.Before(failures[j].At)
+	})
+
+	result := &Result{}
+	nextReconcile := &options.From
+	for {
+		if len(failures) > 0 && (nextReconcile == nil || !failures[0].At.After(*nextReconcile)) {
+			c.now = failures[0].At
+			inject(c, failures[0])
+			failures = failures[1:]
+			result.Timeline = append(result.Timeline, c.takeChanges()...)
+			// The change to the Job triggers a reconcile
+			next := c.now.Add(reactionDelay)
+			nextReconcile = &next
+			continue
+		}
+		if nextReconcile == nil || nextReconcile.After(options.To) {
+			break
+		}
+		if result.Reconciles >= maxReconciles {
+			return nil, errors.Errorf("gave up after %d reconciles, at %s", result.Reconciles, c.now)
+		}
+
+		c.now = *nextReconcile
+		previousConditions := c.controlledJob.Status.Conditions
+		reconcileResult := reconciliation.Reconcile(ctx, c.key(), c.now, c, eventHandler)
+		result.Reconciles++
+		changes := c.takeChanges()
+		result.Timeline = append(result.Timeline, changes...)
+		result.Timeline = append(result.Timeline, conditionChanges(c.now, previousConditions, c.controlledJob.Status.Conditions)...)
+		c.runPods()
+
+		nextReconcile = nil
+		switch {
+		case reconcileResult.Error != nil && reconcileResult.Error.IsRetryable:
+			next := c.now.Add(errorRetryDelay)
+			nextReconcile = &next
+		case reconcileResult.Error != nil:
+			// The operator waits for the ControlledJob or its Jobs to change
+		case len(changes) > 0 && (reconcileResult.RequeueAfter <= 0 || reconcileResult.RequeueAfter > reactionDelay):
+			next := c.now.Add(reactionDelay)
+			nextReconcile = &next
+		case reconcileResult.RequeueAfter > 0:
+			next := c.now.Add(reconcileResult.RequeueAfter)
+			nextReconcile = &next
+		}
+	}
+
+	for _, job := range c.jobs {
+		result.Jobs = append(result.Jobs, *job.DeepCopy())
+	}
+	sort.Slice(result.Jobs, func(i, j int) bool {
+		return result.Jobs[i].Name < result.Jobs[j].Name
+	})
+	result.Status = *c.controlledJob.Status.DeepCopy()
+	return result, nil
+}
+
+// inject applies the failure to the newest running Job
+func inject(c *cluster, failure Failure) {
+	var running []*kbatch.Job
+	for _, job := range c.jobs {
+		running = append(running, job)
+	}
+	running = actions.RunningJobs(running)
+	for len(running) > 0 && !metadata.IsJobRunning(running[0]) {
+		running = running[1:]
+	}
+	if len(running) == 0 {
+		c.record(EntryInjected, "", "%s: no job is running, so there's nothing to do", failure.Type)
+		return
+	}
+
+	job := running[0]
+	switch failure.Type {
+	case JobFails:
+		c.failJob(job)
+		c.record(EntryInjected, job.Name, "Job failed")
+	case JobDeletedByUser:
+		delete(c.jobs, job.Name)
+		c.record(EntryInjected, job.Name, "Job deleted by the user")
+	default:
+		c.record(EntryInjected, job.Name, "%s: unknown failure type", failure.Type)
+	}
+}
+
+// jobConditions mirror the state of the current Job, so are left out of the timeline, which already shows the changes
+// to the Jobs
+var jobConditions = map[string]bool{
+	string(batch.ConditionTypeJobManuallyScheduled): true,
+	string(batch.ConditionTypeJobExists):            true,
+	string(batch.ConditionTypeJobRunning):           true,
+	string(batch.ConditionTypeJobComplete):          true,
+	string(batch.ConditionTypeJobBeingDeleted):      true,
+	string(batch.ConditionTypeJobSuspended):         true,
+	string(batch.ConditionTypeJobStoppedByUser):     true,
+}
+
+// conditionChanges records conditions which have become True, or have gone from True to False. To keep the timeline
+// readable, changes to and from Unknown are left out, as are the conditions which mirror the current Job
+func conditionChanges(now time.Time, previous, current []metav1.Condition) []Entry {
+	var result []Entry
+	for _, condition := range current {
+		if jobConditions[condition.Type] {
+			continue
+		}
+		from := metav1.ConditionUnknown
+		if before := meta.FindStatusCondition(previous, condition.Type); before != nil {
+			from = before.Status
+		}
+		becameTrue := condition.Status == metav1.ConditionTrue && from != metav1.ConditionTrue
+		becameFalse := condition.Status == metav1.ConditionFalse && from == metav1.ConditionTrue
+		if !becameTrue && !becameFalse {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s -> %s (%s)", condition.Type, from, condition.Status, condition.Reason)
+		if condition.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, condition.Message)
+		}
+		result = append(result, Entry{Time: now, Type: EntryConditionChanged, Message: message})
+	}
+	return result
+}
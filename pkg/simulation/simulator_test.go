@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/testhelpers"
+)
+
+var (
+	// 2024-06-03 is a Monday
+	monday    = time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	mondayAt9 = time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+)
+
+func newControlledJob(opts ...testhelpers.ControlledJobOption) *batch.ControlledJob {
+	opts = append([]testhelpers.ControlledJobOption{
+		testhelpers.WithUID("my-uid"),
+		testhelpers.WithTimezone("UTC", 0),
+		testhelpers.WithCronEvent(batch.EventTypeStart, "0 9 * * MON-FRI"),
+		testhelpers.WithCronEvent(batch.EventTypeStop, "0 17 * * MON-FRI"),
+		testhelpers.WithDefaultJobTemplate(),
+	}, opts...)
+	return testhelpers.NewControlledJob("my-controlled-job", opts...)
+}
+
+// entriesOfType returns the job name and time of each entry of the given type
+func entriesOfType(result *Result, entryType EntryType) []Entry {
+	var matching []Entry
+	for _, entry := range result.Timeline {
+		if entry.Type == entryType {
+			matching = append(matching, Entry{Time: entry.Time, Type: entry.Type, JobName: entry.JobName})
+		}
+	}
+	return matching
+}
+
+func Test_Run(t *testing.T) {
+	result, err := Run(context.Background(), newControlledJob(), Options{From: monday, To: monday.Add(48 * time.Hour)})
+	require.Nil(t, err)
+
+	mondaysJob := metadata.JobName("my-controlled-job", mondayAt9, 0)
+	tuesdaysJob := metadata.JobName("my-controlled-job", mondayAt9.Add(24*time.Hour), 0)
+	assert.Equal(t, []Entry{
+		{Time: mondayAt9, Type: EntryJobCreated, JobName: mondaysJob},
+		{Time: mondayAt9.Add(24 * time.Hour), Type: EntryJobCreated, JobName: tuesdaysJob},
+	}, entriesOfType(result, EntryJobCreated))
+	assert.Equal(t, []Entry{
+		{Time: mondayAt9.Add(8 * time.Hour), Type: EntryJobDeleted, JobName: mondaysJob},
+		{Time: mondayAt9.Add(32 * time.Hour), Type: EntryJobDeleted, JobName: tuesdaysJob},
+	}, entriesOfType(result, EntryJobDeleted))
+	assert.Empty(t, entriesOfType(result, EntryWarning))
+	assert.Empty(t, result.Jobs)
+
+	var shouldBeRunning []Entry
+	for _, entry := range result.Timeline {
+		if entry.Type == EntryConditionChanged && strings.HasPrefix(entry.Message, "ShouldBeRunning:") {
+			shouldBeRunning = append(shouldBeRunning, entry)
+		}
+	}
+	assert.Len(t, shouldBeRunning, 4, "should record ShouldBeRunning changing at the start and end of each run period")
+	assert.Less(t, result.Reconciles, 100, "should only reconcile when something changes or the reconciler asks to be requeued")
+}
+
+func Test_Run_JobFailsAndIsRestarted(t *testing.T) {
+	controlledJob := newControlledJob(
+		testhelpers.WithFailurePolicy(batch.AlwaysRestartFailurePolicy),
+		testhelpers.WithFailureBackoff(60, 600),
+	)
+	failAt := mondayAt9.Add(time.Hour)
+
+	result, err := Run(context.Background(), controlledJob, Options{
+		From:     monday,
+		To:       monday.Add(12 * time.Hour),
+		Failures: []Failure{{At: failAt, Type: JobFails}},
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, []Entry{
+		{Time: failAt, Type: EntryInjected, JobName: metadata.JobName("my-controlled-job", mondayAt9, 0)},
+	}, entriesOfType(result, EntryInjected))
+	created := entriesOfType(result, EntryJobCreated)
+	if assert.Len(t, created, 2) {
+		assert.Equal(t, metadata.JobName("my-controlled-job", mondayAt9, 1), created[1].JobName)
+		assert.False(t, created[1].Time.Before(failAt.Add(time.Minute)), "should wait for the backoff before restarting")
+	}
+}
+
+func Test_Run_JobFailsAndIsNotRestarted(t *testing.T) {
+	failAt := mondayAt9.Add(time.Hour)
+
+	result, err := Run(context.Background(), newControlledJob(), Options{
+		From:     monday,
+		To:       monday.Add(12 * time.Hour),
+		Failures: []Failure{{At: failAt, Type: JobFails}},
+	})
+	require.Nil(t, err)
+
+	assert.Len(t, entriesOfType(result, EntryJobCreated), 1, "should leave the failed job in place until the end of the run period")
+}
+
+func Test_Run_JobDeletedByUser(t *testing.T) {
+	deleteAt := mondayAt9.Add(time.Hour)
+
+	result, err := Run(context.Background(), newControlledJob(), Options{
+		From:     monday,
+		To:       monday.Add(12 * time.Hour),
+		Failures: []Failure{{At: deleteAt, Type: JobDeletedByUser}},
+	})
+	require.Nil(t, err)
+
+	created := entriesOfType(result, EntryJobCreated)
+	if assert.Len(t, created, 2) {
+		assert.Equal(t, Entry{Time: deleteAt.Add(reactionDelay), Type: EntryJobCreated, JobName: metadata.JobName("my-controlled-job", mondayAt9, 0)}, created[1],
+			"should replace the deleted job as soon as the operator notices")
+	}
+}
+
+func Test_Run_NothingToInject(t *testing.T) {
+	result, err := Run(context.Background(), newControlledJob(), Options{
+		From:     monday,
+		To:       monday.Add(12 * time.Hour),
+		Failures: []Failure{{At: monday.Add(time.Hour), Type: JobFails}},
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, []Entry{{Time: monday.Add(time.Hour), Type: EntryInjected}}, entriesOfType(result, EntryInjected))
+	assert.Len(t, entriesOfType(result, EntryJobCreated), 1)
+}
+
+func Test_Run_InvalidRange(t *testing.T) {
+	_, err := Run(context.Background(), newControlledJob(), Options{From: monday, To: monday})
+	assert.NotNil(t, err)
+}
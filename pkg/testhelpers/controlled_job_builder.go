@@ -70,6 +70,15 @@ func WithTimezone(name string, offsetSeconds int32) ControlledJobOption {
 	}
 }
 
+func WithDSTPolicy(skippedTimes batch.DSTSkippedTimePolicy, repeatedTimes batch.DSTRepeatedTimePolicy) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Timezone.DSTPolicy = &batch.DSTPolicySpec{
+			SkippedTimes:  skippedTimes,
+			RepeatedTimes: repeatedTimes,
+		}
+	}
+}
+
 func WithCronEvent(eventType batch.EventType, cronSpec string) ControlledJobOption {
 	return func(controlledJob *batch.ControlledJob) {
 		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
@@ -79,6 +88,54 @@ func WithCronEvent(eventType batch.EventType, cronSpec string) ControlledJobOpti
 	}
 }
 
+func WithCronEventInTimezone(eventType batch.EventType, cronSpec, timezone string) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
+			Action:       eventType,
+			CronSchedule: cronSpec,
+			Timezone:     &batch.TimezoneSpec{Name: timezone},
+		})
+	}
+}
+
+func WithIntervalEvent(eventType batch.EventType, interval, epoch string) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
+			Action:       eventType,
+			CronSchedule: "@every " + interval,
+			Epoch:        epoch,
+		})
+	}
+}
+
+func WithCronStartEventRunningFor(cronSpec string, runFor time.Duration) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
+			Action:       batch.EventTypeStart,
+			CronSchedule: cronSpec,
+			RunFor:       &metav1.Duration{Duration: runFor},
+		})
+	}
+}
+
+func WithOneOffEvent(eventType batch.EventType, at string) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
+			Action: eventType,
+			At:     at,
+		})
+	}
+}
+
+func WithFriendlyScheduledEvent(eventType batch.EventType, schedule batch.FriendlyScheduleSpec) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
+			Action:   eventType,
+			Schedule: &schedule,
+		})
+	}
+}
+
 func WithScheduledEvent(eventType batch.EventType, daysOfWeek, timeOfDay string) ControlledJobOption {
 	return func(controlledJob *batch.ControlledJob) {
 		controlledJob.Spec.Events = append(controlledJob.Spec.Events, batch.EventSpec{
@@ -112,6 +169,24 @@ func WithEventInTheFuture(eventType batch.EventType) ControlledJobOption {
 	return WithScheduledEventAtTime(eventType, time.Now().Add(time.Hour))
 }
 
+func WithExclusion(date, endDate string) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.Exclusions = append(controlledJob.Spec.Exclusions, batch.ExclusionSpec{
+			Date:    date,
+			EndDate: endDate,
+		})
+	}
+}
+
+func WithCalendarRef(kind, name string) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.CalendarRef = &batch.CalendarReference{
+			Kind: kind,
+			Name: name,
+		}
+	}
+}
+
 func WithJobTemplate(jobTemplate batchv1beta1.JobTemplateSpec) ControlledJobOption {
 	return func(controlledJob *batch.ControlledJob) {
 		controlledJob.Spec.JobTemplate = jobTemplate
@@ -143,6 +218,63 @@ func WithSpecChangePolicy(policyType batch.SpecChangePolicy) ControlledJobOption
 	}
 }
 
+func WithFailurePolicy(policy batch.FailurePolicy) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.RestartStrategy.FailurePolicy = policy
+	}
+}
+
+func WithFailureBackoff(backoffSeconds, maxBackoffSeconds int32) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.RestartStrategy.BackoffSeconds = &backoffSeconds
+		controlledJob.Spec.RestartStrategy.MaxBackoffSeconds = &maxBackoffSeconds
+	}
+}
+
+func WithMaxRestartsPerRunPeriod(maxRestarts int32) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.RestartStrategy.MaxRestartsPerRunPeriod = &maxRestarts
+	}
+}
+
+func WithConcurrencyPolicy(policy batch.ConcurrencyPolicy) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.ConcurrencyPolicy = policy
+	}
+}
+
+func WithSuspendAfterFailedRunPeriods(count int32) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Spec.SuspendAfterFailedRunPeriods = &count
+	}
+}
+
+func WithFailedRunPeriods(runPeriodStartTime time.Time, runPeriodFailed bool, consecutiveFailures int32) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Status.FailedRunPeriods = &batch.FailedRunPeriodsStatus{
+			RunPeriodStartTime:  metav1.NewTime(runPeriodStartTime),
+			RunPeriodFailed:     runPeriodFailed,
+			ConsecutiveFailures: consecutiveFailures,
+		}
+	}
+}
+
+func WithSkippedRunPeriod(runPeriodStartTime time.Time) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		skippedRunPeriodStartTime := metav1.NewTime(runPeriodStartTime)
+		controlledJob.Status.SkippedRunPeriodStartTime = &skippedRunPeriodStartTime
+	}
+}
+
+func WithAutoSuspended(reason string, suspendedAt time.Time) ControlledJobOption {
+	return func(controlledJob *batch.ControlledJob) {
+		controlledJob.Status.AutoSuspended = &batch.AutoSuspendedStatus{
+			Reason:      reason,
+			SuspendedAt: metav1.NewTime(suspendedAt),
+		}
+	}
+}
+
 func WithAnnotation(key, value string) ControlledJobOption {
 	return func(controlledJob *batch.ControlledJob) {
 		if controlledJob.Annotations == nil {
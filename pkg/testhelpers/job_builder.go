@@ -82,6 +82,14 @@ func HasSucceeded() JobOption {
 	})
 }
 
+func HasFailedAt(failedAt time.Time) JobOption {
+	return WithCondition(kbatch.JobCondition{
+		Type:               kbatch.JobFailed,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(failedAt),
+	})
+}
+
 func IsBeingDeleted() JobOption {
 	return func(job *kbatch.Job) {
 		now := metav1.NewTime(time.Now())
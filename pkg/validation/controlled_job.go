@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+	"github.com/G-Research/controlled-job/pkg/metadata"
+	"github.com/G-Research/controlled-job/pkg/schedule"
+)
+
+// ValidateControlledJob finds the problems with a ControlledJob which would otherwise only be found when it is
+// reconciled: schedules which can't be calculated, and names which are too long for the Jobs created for it.
+// calendarSpec is the spec of the Calendar or ClusterCalendar it references, or nil if it doesn't reference one
+// (or it couldn't be loaded)
+func ValidateControlledJob(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, now time.Time) field.ErrorList {
+	var allErrs field.ErrorList
+	if err := metadata.ValidateControlledJobName(controlledJob.Name); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), controlledJob.Name, err.Error()))
+	}
+	return append(allErrs, ValidateControlledJobSpec(controlledJob, calendarSpec, now)...)
+}
+
+// ValidateControlledJobSpec finds the problems with a ControlledJob's schedule which would otherwise only be found
+// when it is reconciled. Unlike ValidateControlledJob, it doesn't check the name, which can't be changed once the
+// ControlledJob has been created
+func ValidateControlledJobSpec(controlledJob *batch.ControlledJob, calendarSpec *batch.CalendarSpec, now time.Time) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	if err := schedule.ValidateTimezone(controlledJob.Spec.Timezone); err != nil {
+		// Every event depends on the timezone, so there's no point checking them individually
+		return append(allErrs, field.Invalid(specPath.Child("timezone"), controlledJob.Spec.Timezone.Name, err.Error()))
+	}
+	eventsPath := specPath.Child("events")
+	eventErrs, err := schedule.ValidateEvents(controlledJob.Spec.Events, controlledJob.Spec.Timezone)
+	if err != nil {
+		return append(allErrs, field.Invalid(specPath.Child("timezone"), controlledJob.Spec.Timezone.Name, err.Error()))
+	}
+	for i, err := range eventErrs {
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(eventsPath.Index(i), field.OmitValueType{}, err.Error()))
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	// The events are all fine individually, so check they work together with the exclusions and calendar, e.g. that a
+	// start-only schedule has a concurrencyPolicy
+	if _, err := schedule.StateFor(controlledJob, calendarSpec, now); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath, field.OmitValueType{}, err.Error()))
+	}
+	return allErrs
+}
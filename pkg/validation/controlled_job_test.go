@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	batch "github.com/G-Research/controlled-job/api/v1"
+)
+
+func Test_ValidateControlledJob(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	weekdays := []batch.EventSpec{
+		{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+		{Action: batch.EventTypeStop, CronSchedule: "0 17 * * MON-FRI"},
+	}
+
+	testCases := map[string]struct {
+		name           string
+		spec           batch.ControlledJobSpec
+		calendar       *batch.CalendarSpec
+		expectedFields []string
+	}{
+		"valid": {
+			spec: batch.ControlledJobSpec{Timezone: batch.TimezoneSpec{Name: "Europe/London"}, Events: weekdays},
+		},
+		"valid start-only schedule": {
+			spec: batch.ControlledJobSpec{
+				Timezone:          batch.TimezoneSpec{Name: "UTC"},
+				Events:            []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 * * * *"}},
+				ConcurrencyPolicy: batch.ForbidConcurrent,
+			},
+		},
+		"name too long for its Jobs": {
+			name:           strings.Repeat("a", 50),
+			spec:           batch.ControlledJobSpec{Timezone: batch.TimezoneSpec{Name: "UTC"}, Events: weekdays},
+			expectedFields: []string{"metadata.name"},
+		},
+		"unknown timezone": {
+			spec:           batch.ControlledJobSpec{Timezone: batch.TimezoneSpec{Name: "Europe/Nowhere"}, Events: weekdays},
+			expectedFields: []string{"spec.timezone"},
+		},
+		"invalid events": {
+			spec: batch.ControlledJobSpec{
+				Timezone: batch.TimezoneSpec{Name: "UTC"},
+				Events: []batch.EventSpec{
+					{Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+					{Action: batch.EventTypeStop, CronSchedule: "0 17 * *"},
+					{Action: "pause", CronSchedule: "0 12 * * *"},
+					{Action: batch.EventTypeStop, CronSchedule: "0 9 * * *", RunFor: &metav1.Duration{Duration: time.Hour}},
+					{Action: batch.EventTypeStop, CronSchedule: "0 18 * * *", Timezone: &batch.TimezoneSpec{Name: "Europe/Nowhere"}},
+				},
+			},
+			expectedFields: []string{"spec.events[1]", "spec.events[2]", "spec.events[3]", "spec.events[4]"},
+		},
+		"events relative to other events": {
+			spec: batch.ControlledJobSpec{
+				Timezone: batch.TimezoneSpec{Name: "UTC"},
+				Events: []batch.EventSpec{
+					{Name: "open", Action: batch.EventTypeStart, CronSchedule: "0 9 * * MON-FRI"},
+					{Action: batch.EventTypeStop, RelativeTo: &batch.RelativeEventSpec{Event: "open", Offset: metav1.Duration{Duration: 8 * time.Hour}}},
+					{Action: batch.EventTypeRestart, RelativeTo: &batch.RelativeEventSpec{Event: "close", Offset: metav1.Duration{Duration: -time.Hour}}},
+					{Name: "open", Action: batch.EventTypeRestart, CronSchedule: "0 12 * * MON-FRI"},
+				},
+			},
+			expectedFields: []string{"spec.events[2]", "spec.events[3]"},
+		},
+		"start-only schedule without a concurrencyPolicy": {
+			spec: batch.ControlledJobSpec{
+				Timezone: batch.TimezoneSpec{Name: "UTC"},
+				Events:   []batch.EventSpec{{Action: batch.EventTypeStart, CronSchedule: "0 * * * *"}},
+			},
+			expectedFields: []string{"spec"},
+		},
+		"invalid calendar": {
+			spec:           batch.ControlledJobSpec{Timezone: batch.TimezoneSpec{Name: "UTC"}, Events: weekdays},
+			calendar:       &batch.CalendarSpec{Holidays: []batch.ExclusionSpec{{Date: "26/12/2022"}}},
+			expectedFields: []string{"spec"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			controlledJob := &batch.ControlledJob{ObjectMeta: metav1.ObjectMeta{Name: "my-job"}, Spec: tc.spec}
+			if tc.name != "" {
+				controlledJob.Name = tc.name
+			}
+
+			errs := ValidateControlledJob(controlledJob, tc.calendar, now)
+
+			var actualFields []string
+			for _, err := range errs {
+				actualFields = append(actualFields, err.Field)
+			}
+			assert.Equal(t, tc.expectedFields, actualFields, "%v", errs)
+		})
+	}
+}